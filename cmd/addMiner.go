@@ -30,6 +30,9 @@ func init() {
 
 	addMinerCmd.Flags().StringVar(&config.MinerGoSmConfig, "miner-go-sm-config", config.MinerGoSmConfig, "config file location for the new miner (example \"./config.json\")")
 	addMinerCmd.Flags().StringVar(&config.MinerNumber, "miner-number", config.MinerNumber, "miner to add")
+	addMinerCmd.Flags().StringVar(&config.MinerGroup, "miner-group", config.MinerGroup, "group of the new miner defined in miner-groups of the config file (default: group of the miner number)")
+	addMinerCmd.Flags().StringVar(&config.AdversarialImage, "adversarial-image", config.AdversarialImage, "docker image for adversarial miners (default: go-sm-image)")
+	addMinerCmd.Flags().StringSliceVar(&config.AdversarialFlags, "adversarial-flags", config.AdversarialFlags, "extra go-spacemesh flags for adversarial miners")
 	addMinerCmd.Flags().StringVar(&config.MinerMemory, "miner-ram", config.MinerMemory, "RAM for each miner")
 	addMinerCmd.Flags().StringVar(&config.MinerCPU, "miner-cpu", config.MinerCPU, "vCPUs for each miner")
 	addMinerCmd.Flags().StringVar(&config.GoSmImage, "go-sm-image", config.GoSmImage, "docker image for go-spacemesh build")
@@ -38,6 +41,9 @@ func init() {
 	addMinerCmd.Flags().StringVar(&config.SlackToken, "slack-token", config.SlackToken, "slack API token to post alerts")
 	addMinerCmd.Flags().StringVar(&config.SlackChannelId, "slack-channel-id", config.SlackChannelId, "slack channel ID to post alerts")
 	addMinerCmd.Flags().BoolVar(&config.EnableSlackAlerts, "enable-slack-alerts", config.EnableSlackAlerts, "deploy spacemesh-watch")
+	addMinerCmd.Flags().StringVar(&config.CoinbaseMnemonic, "coinbase-mnemonic", config.CoinbaseMnemonic, "mnemonic to derive miner coinbase keys from")
+	addMinerCmd.Flags().StringVar(&config.CoinbaseKeystore, "coinbase-keystore", config.CoinbaseKeystore, "keys file exported using exportKeys to import miner coinbase keys from")
+	addMinerCmd.Flags().StringVar(&config.KeystorePassword, "keystore-password", config.KeystorePassword, "password of the coinbase keystore")
 
 	err := viper.BindPFlags(addMinerCmd.Flags())
 	if err != nil {
@@ -0,0 +1,32 @@
+package cmd
+
+import (
+	"github.com/spacemeshos/go-spacecraft/log"
+	"github.com/spacemeshos/go-spacecraft/network"
+	"github.com/spf13/cobra"
+)
+
+var certsCmd = &cobra.Command{
+	Use:   "certs",
+	Short: "Manage TLS certificates",
+	Long: `Show the TLS certificates of a network. Certificates are read from --tls-crt and --tls-key
+or issued by cert-manager if deployWS or createNetwork is run with --tls-issuer acme or selfsigned.`,
+}
+
+var certsStatusCmd = &cobra.Command{
+	Use:   "status",
+	Short: "Show the expiry dates of the TLS certificates",
+	Long:  "Certificates which expire in less than 14 days are printed as errors",
+	Run: func(cmd *cobra.Command, args []string) {
+		err := network.CertsStatus()
+		if err != nil {
+			log.Error.Println(err)
+			return
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(certsCmd)
+	certsCmd.AddCommand(certsStatusCmd)
+}
@@ -0,0 +1,126 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/spacemeshos/go-spacecraft/log"
+	"github.com/spacemeshos/go-spacecraft/network"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+var chaosCmd = &cobra.Command{
+	Use:   "chaos",
+	Short: "Manage chaos experiments",
+	Long: `Create, list, pause, resume and delete chaos mesh experiments of a network. Chaos mesh needs
+to be deployed using --chaos-mesh or deployCM first.`,
+}
+
+var chaosCreateCmd = &cobra.Command{
+	Use:   "create",
+	Short: "Create chaos experiments from a scenario file",
+	Long: `For example: spacecraft chaos create -f ./scenario.yaml
+
+experiments:
+  - name: kill-bootnodes
+    action: pod-kill
+    target: bootnodes
+    start:
+      epoch: 2
+  - name: slow-miners
+    action: network-delay
+    target: miners
+    percent: 30
+    latency: 500ms
+    duration: 10m
+    start:
+      layer: 20
+      after: 30s
+
+Actions: pod-kill, network-delay, network-loss, network-partition, clock-skew, io-stress
+Targets: bootstrap, bootnodes, miners, poets, miner-<N>, poet-<N>, group:<name>, cohort:<name>
+
+Experiments with a start in the future are created as workflows which wait in the cluster until the start.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		err := network.CreateChaos()
+		if err != nil {
+			log.Error.Println(err)
+			return
+		}
+
+		log.Success.Println("chaos experiments created successfully")
+	},
+}
+
+var chaosListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List chaos experiments",
+	Run: func(cmd *cobra.Command, args []string) {
+		err := network.ListChaos()
+		if err != nil {
+			log.Error.Println(err)
+			return
+		}
+	},
+}
+
+var chaosPauseCmd = &cobra.Command{
+	Use:   "pause [name]",
+	Short: "Pause a chaos experiment",
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		err := network.PauseChaos(args[0], true)
+		if err != nil {
+			log.Error.Println(err)
+			return
+		}
+
+		log.Success.Println("chaos experiment paused successfully")
+	},
+}
+
+var chaosResumeCmd = &cobra.Command{
+	Use:   "resume [name]",
+	Short: "Resume a paused chaos experiment",
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		err := network.PauseChaos(args[0], false)
+		if err != nil {
+			log.Error.Println(err)
+			return
+		}
+
+		log.Success.Println("chaos experiment resumed successfully")
+	},
+}
+
+var chaosDeleteCmd = &cobra.Command{
+	Use:   "delete [name]",
+	Short: "Delete a chaos experiment",
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		err := network.DeleteChaos(args[0])
+		if err != nil {
+			log.Error.Println(err)
+			return
+		}
+
+		log.Success.Println("chaos experiment deleted successfully")
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(chaosCmd)
+	chaosCmd.AddCommand(chaosCreateCmd)
+	chaosCmd.AddCommand(chaosListCmd)
+	chaosCmd.AddCommand(chaosPauseCmd)
+	chaosCmd.AddCommand(chaosResumeCmd)
+	chaosCmd.AddCommand(chaosDeleteCmd)
+
+	chaosCreateCmd.Flags().StringVarP(&config.ChaosFile, "chaos-file", "f", config.ChaosFile, "chaos scenario file")
+
+	err := viper.BindPFlags(chaosCreateCmd.Flags())
+	if err != nil {
+		fmt.Println("an error has occurred while binding flags:", err)
+	}
+}
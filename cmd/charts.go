@@ -0,0 +1,25 @@
+package cmd
+
+import (
+	"github.com/spacemeshos/go-spacecraft/log"
+	"github.com/spacemeshos/go-spacecraft/network"
+	"github.com/spf13/cobra"
+)
+
+var chartsCmd = &cobra.Command{
+	Use:   "charts",
+	Short: "Lists the helm charts installed in a network",
+	Long: `Lists the helm releases of all namespaces with their chart version and the version in the chart
+catalog. For example: spacecraft charts -n devnet-201`,
+	Run: func(cmd *cobra.Command, args []string) {
+		err := network.ListCharts()
+		if err != nil {
+			log.Error.Println(err)
+			return
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(chartsCmd)
+}
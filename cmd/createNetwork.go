@@ -63,12 +63,33 @@ func init() {
 	createNetworkCmd.Flags().StringVar(&config.ESMasterNodes, "es-master-nodes", config.ESMasterNodes, "number of master nodes out of total nodes")
 	createNetworkCmd.Flags().StringVar(&config.KibanaCPU, "kibana-cpu", config.KibanaCPU, "vCPUs to allocate to kibana")
 	createNetworkCmd.Flags().StringVar(&config.KibanaMemory, "kibana-memory", config.KibanaMemory, "RAM to allocate to kibana")
+	createNetworkCmd.Flags().StringVar(&config.ESURL, "es-url", config.ESURL, "URL of an external elasticsearch to send logs to instead of deploying one")
+	createNetworkCmd.Flags().StringVar(&config.ESUsername, "es-username", config.ESUsername, "username of the external elasticsearch")
+	createNetworkCmd.Flags().StringVar(&config.ESPassword, "es-password", config.ESPassword, "password of the external elasticsearch")
+	createNetworkCmd.Flags().StringVar(&config.ESCA, "es-ca", config.ESCA, "path to the CA certificate of the external elasticsearch")
+	createNetworkCmd.Flags().StringVar(&config.KibanaURL, "kibana-url", config.KibanaURL, "URL of the kibana of the external elasticsearch")
+	createNetworkCmd.Flags().StringVar(&config.LogBackend, "log-backend", config.LogBackend, "where logs are stored: elk, loki or none")
+	createNetworkCmd.Flags().StringVar(&config.LokiVersion, "loki-version", config.LokiVersion, "version of the loki-stack chart, overrides the chart catalog")
+	createNetworkCmd.Flags().StringVar(&config.LokiDiskSize, "loki-disk-size", config.LokiDiskSize, "disk size to allocate to loki")
 	createNetworkCmd.Flags().StringVar(&config.LogsExpiry, "logs-expiry", config.LogsExpiry, "number of days after which logs are deleted automatically")
+	createNetworkCmd.Flags().BoolVar(&config.ArchiveLogs, "archive-logs", config.ArchiveLogs, "snapshot logs to GCS before they are deleted")
+	createNetworkCmd.Flags().StringVar(&config.ArchiveCredentials, "archive-credentials", config.ArchiveCredentials, "GCP service account key used by elasticsearch, defaults to GOOGLE_APPLICATION_CREDENTIALS")
+	createNetworkCmd.Flags().StringVar(&config.LogsBucket, "logs-bucket", config.LogsBucket, "GCS bucket of the logs archive")
 	createNetworkCmd.Flags().StringVar(&config.PyroscopeImage, "pyroscope-image", config.PyroscopeImage, "docker image url of pyroscope")
 	createNetworkCmd.Flags().StringVar(&config.PyroscopeCPU, "pyroscope-cpu", config.PyroscopeCPU, "vCPUs to allocate to pyroscope")
 	createNetworkCmd.Flags().StringVar(&config.PyroscopeMemory, "pyroscope-memory", config.PyroscopeMemory, "memory to allocate to pyroscope")
 	createNetworkCmd.Flags().BoolVar(&config.DeployPyroscope, "deploy-pyroscope", config.DeployPyroscope, "deploy pyroscope profiler")
-	createNetworkCmd.Flags().BoolVar(&config.Metrics, "metrics", config.Metrics, "enable go-sm metrics collection")
+	createNetworkCmd.Flags().BoolVar(&config.Metrics, "metrics", config.Metrics, "deploy prometheus and grafana and enable go-sm metrics collection")
+	createNetworkCmd.Flags().StringVar(&config.PushGatewayURL, "push-gateway-url", config.PushGatewayURL, "push gateway the miners also push metrics to (empty to disable)")
+	createNetworkCmd.Flags().StringVar(&config.PrometheusVersion, "prometheus-version", config.PrometheusVersion, "version of the kube-prometheus-stack chart, overrides the chart catalog")
+	createNetworkCmd.Flags().StringVar(&config.PrometheusDiskSize, "prometheus-disk-size", config.PrometheusDiskSize, "prometheus disk size in GB")
+	createNetworkCmd.Flags().StringVar(&config.PrometheusPassword, "prometheus-password", config.PrometheusPassword, "password of the admin user of prometheus, a random password is generated if empty")
+	createNetworkCmd.Flags().StringVar(&config.GrafanaPassword, "grafana-password", config.GrafanaPassword, "password of the admin user of grafana, a random password is generated if empty")
+	createNetworkCmd.Flags().StringVar(&config.MetricsRetention, "metrics-retention", config.MetricsRetention, "how long prometheus keeps metrics")
+	createNetworkCmd.Flags().StringVar(&config.GrafanaDashboards, "grafana-dashboards", config.GrafanaDashboards, "directory of grafana dashboards to provision")
+	createNetworkCmd.Flags().StringVar(&config.AlertRules, "alert-rules", config.AlertRules, "directory of prometheus alert rules")
+	createNetworkCmd.Flags().StringVar(&config.AlertWebhookURL, "alert-webhook-url", config.AlertWebhookURL, "webhook alertmanager sends alerts to")
+	createNetworkCmd.Flags().StringVar(&config.PagerDutyRoutingKey, "pagerduty-routing-key", config.PagerDutyRoutingKey, "pagerduty routing key alertmanager sends alerts to")
 	createNetworkCmd.Flags().BoolVar(&config.EnableJsonAPI, "enable-json-api", config.EnableJsonAPI, "enables JSON api in all nodes")
 	createNetworkCmd.Flags().IntVar(&config.MaxConcurrentDeployments, "max-concurrent-deployments", config.MaxConcurrentDeployments, "number of miners that can be deployed concurrently")
 	createNetworkCmd.Flags().BoolVar(&config.EnableGoDebug, "enable-go-debug", config.EnableGoDebug, "start miners with GODEBUG=\"gctrace=1,scavtrace=1,gcpacertrace=1\" env")
@@ -78,11 +99,31 @@ func init() {
 	createNetworkCmd.Flags().StringVar(&config.SlackToken, "slack-token", config.SlackToken, "slack API token to post alerts")
 	createNetworkCmd.Flags().StringVar(&config.SlackChannelId, "slack-channel-id", config.SlackChannelId, "slack channel ID to post alerts")
 	createNetworkCmd.Flags().BoolVar(&config.EnableSlackAlerts, "enable-slack-alerts", config.EnableSlackAlerts, "deploy spacemesh-watch")
+	createNetworkCmd.Flags().StringVar(&config.SpacemeshWatchImage, "sw-image", config.SpacemeshWatchImage, "docker image for spacemesh-watch")
+	createNetworkCmd.Flags().StringSliceVar(&config.WatchFlags, "watch-flags", config.WatchFlags, "extra flags of spacemesh-watch, e.g., alert thresholds")
 	createNetworkCmd.Flags().StringVar(&config.CloudflareAPIToken, "cloudflare-api-token", config.CloudflareAPIToken, "cloudflare API token")
+	createNetworkCmd.Flags().StringVar(&config.DNSProvider, "dns-provider", config.DNSProvider, "DNS provider for the network records: cloudflare, route53, clouddns, rfc2136, coredns or none")
+	createNetworkCmd.Flags().StringVar(&config.RootDomain, "root-domain", config.RootDomain, "root domain of the network records")
+	createNetworkCmd.Flags().StringVar(&config.DNSRecordTemplate, "dns-record-template", config.DNSRecordTemplate, "name of the records under the root domain, {service} and {network} are replaced")
+	createNetworkCmd.Flags().StringVar(&config.DNSServer, "dns-server", config.DNSServer, "address of the DNS server for rfc2136, e.g., 127.0.0.1:53")
+	createNetworkCmd.Flags().StringVar(&config.DNSHostsFile, "dns-hosts-file", config.DNSHostsFile, "hosts file served by the CoreDNS hosts plugin for coredns")
+	createNetworkCmd.Flags().StringVar(&config.DNSTSIGKey, "dns-tsig-key", config.DNSTSIGKey, "TSIG key name for rfc2136")
+	createNetworkCmd.Flags().StringVar(&config.DNSTSIGSecret, "dns-tsig-secret", config.DNSTSIGSecret, "base64 TSIG secret for rfc2136")
+	createNetworkCmd.Flags().StringVar(&config.DNSTSIGAlgorithm, "dns-tsig-algorithm", config.DNSTSIGAlgorithm, "TSIG algorithm for rfc2136")
+	createNetworkCmd.Flags().StringVar(&config.TLSIssuer, "tls-issuer", config.TLSIssuer, "files to use --tls-crt and --tls-key, acme to issue certificates with DNS-01 challenges through the DNS provider or selfsigned to issue them with a self-signed CA")
+	createNetworkCmd.Flags().StringVar(&config.ACMEEmail, "acme-email", config.ACMEEmail, "email of the ACME account for expiry notices")
+	createNetworkCmd.Flags().StringVar(&config.ACMEServer, "acme-server", config.ACMEServer, "ACME directory URL, e.g., the letsencrypt staging directory")
+	createNetworkCmd.Flags().StringVar(&config.CertManagerVersion, "cert-manager-version", config.CertManagerVersion, "cert-manager chart version, overrides the chart catalog")
 	createNetworkCmd.Flags().BoolVar(&config.ChaosMesh, "chaos-mesh", config.ChaosMesh, "deploy chaos mesh")
-	createNetworkCmd.Flags().StringVar(&config.ChaosMeshVersion, "chaos-mesh-version", config.ChaosMeshVersion, "chaosmesh version")
+	createNetworkCmd.Flags().StringVar(&config.ChaosMeshVersion, "chaos-mesh-version", config.ChaosMeshVersion, "chaosmesh version, overrides the chart catalog")
 	createNetworkCmd.Flags().StringVar(&config.VPC, "vpc", config.VPC, "name of existing VPC to use. if you don't have a VPC then create a VPC with firewall rules for ingress: #1 10255 port blocked and #2 all other ports open")
 	createNetworkCmd.Flags().BoolVar(&config.UseVPC, "use-vpc", config.UseVPC, "create cluster in an VPC")
+	createNetworkCmd.Flags().StringVar(&config.CoinbaseMnemonic, "coinbase-mnemonic", config.CoinbaseMnemonic, "mnemonic to derive miner coinbase keys from")
+	createNetworkCmd.Flags().StringVar(&config.CoinbaseKeystore, "coinbase-keystore", config.CoinbaseKeystore, "keys file exported using exportKeys to import miner coinbase keys from")
+	createNetworkCmd.Flags().StringVar(&config.KeystorePassword, "keystore-password", config.KeystorePassword, "password of the coinbase keystore")
+	createNetworkCmd.Flags().Float64Var(&config.AdversarialFraction, "adversarial-fraction", config.AdversarialFraction, "fraction of miners to deploy as adversarial cohort")
+	createNetworkCmd.Flags().StringVar(&config.AdversarialImage, "adversarial-image", config.AdversarialImage, "docker image for adversarial miners (default: go-sm-image)")
+	createNetworkCmd.Flags().StringSliceVar(&config.AdversarialFlags, "adversarial-flags", config.AdversarialFlags, "extra go-spacemesh flags for adversarial miners")
 
 	err := viper.BindPFlags(createNetworkCmd.Flags())
 	if err != nil {
@@ -27,6 +27,14 @@ func init() {
 	rootCmd.AddCommand(deleteNetworkCmd)
 
 	deleteNetworkCmd.Flags().StringVar(&config.CloudflareAPIToken, "cloudflare-api-token", config.CloudflareAPIToken, "cloudflare API token")
+	deleteNetworkCmd.Flags().StringVar(&config.DNSProvider, "dns-provider", config.DNSProvider, "DNS provider for the network records: cloudflare, route53, clouddns, rfc2136, coredns or none (only used if the network has no saved DNS settings)")
+	deleteNetworkCmd.Flags().StringVar(&config.RootDomain, "root-domain", config.RootDomain, "root domain of the network records (only used if the network has no saved DNS settings)")
+	deleteNetworkCmd.Flags().StringVar(&config.DNSRecordTemplate, "dns-record-template", config.DNSRecordTemplate, "name of the records under the root domain, {service} and {network} are replaced")
+	deleteNetworkCmd.Flags().StringVar(&config.DNSServer, "dns-server", config.DNSServer, "address of the DNS server for rfc2136, e.g., 127.0.0.1:53")
+	deleteNetworkCmd.Flags().StringVar(&config.DNSHostsFile, "dns-hosts-file", config.DNSHostsFile, "hosts file served by the CoreDNS hosts plugin for coredns")
+	deleteNetworkCmd.Flags().StringVar(&config.DNSTSIGKey, "dns-tsig-key", config.DNSTSIGKey, "TSIG key name for rfc2136")
+	deleteNetworkCmd.Flags().StringVar(&config.DNSTSIGSecret, "dns-tsig-secret", config.DNSTSIGSecret, "base64 TSIG secret for rfc2136")
+	deleteNetworkCmd.Flags().StringVar(&config.DNSTSIGAlgorithm, "dns-tsig-algorithm", config.DNSTSIGAlgorithm, "TSIG algorithm for rfc2136")
 	deleteNetworkCmd.Flags().BoolVar(&config.KeepLogsMetrics, "keep-logs-metrics", config.KeepLogsMetrics, "Delete everything except logs and metrics")
 
 	err := viper.BindPFlags(deleteNetworkCmd.Flags())
@@ -0,0 +1,44 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/spacemeshos/go-spacecraft/log"
+	"github.com/spacemeshos/go-spacecraft/network"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+var deleteWSCmd = &cobra.Command{
+	Use:   "deleteWS",
+	Short: "Deletes web services",
+	Long:  `Deletes the web services, their DNS records and the network from the discovery service. The miners keep running. For example: spacecraft deleteWS -n devnet-201`,
+	Run: func(cmd *cobra.Command, args []string) {
+		err := network.DeleteWS()
+		if err != nil {
+			log.Error.Println(err)
+			return
+		}
+
+		log.Success.Println("web services deleted successfully")
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(deleteWSCmd)
+	deleteWSCmd.Flags().BoolVar(&config.Private, "private", config.Private, "is network private")
+	deleteWSCmd.Flags().StringVar(&config.CloudflareAPIToken, "cloudflare-api-token", config.CloudflareAPIToken, "cloudflare API token")
+	deleteWSCmd.Flags().StringVar(&config.DNSProvider, "dns-provider", config.DNSProvider, "DNS provider for the network records: cloudflare, route53, clouddns, rfc2136, coredns or none (only used if the network has no saved DNS settings)")
+	deleteWSCmd.Flags().StringVar(&config.RootDomain, "root-domain", config.RootDomain, "root domain of the network records (only used if the network has no saved DNS settings)")
+	deleteWSCmd.Flags().StringVar(&config.DNSRecordTemplate, "dns-record-template", config.DNSRecordTemplate, "name of the records under the root domain, {service} and {network} are replaced")
+	deleteWSCmd.Flags().StringVar(&config.DNSServer, "dns-server", config.DNSServer, "address of the DNS server for rfc2136, e.g., 127.0.0.1:53")
+	deleteWSCmd.Flags().StringVar(&config.DNSHostsFile, "dns-hosts-file", config.DNSHostsFile, "hosts file served by the CoreDNS hosts plugin for coredns")
+	deleteWSCmd.Flags().StringVar(&config.DNSTSIGKey, "dns-tsig-key", config.DNSTSIGKey, "TSIG key name for rfc2136")
+	deleteWSCmd.Flags().StringVar(&config.DNSTSIGSecret, "dns-tsig-secret", config.DNSTSIGSecret, "base64 TSIG secret for rfc2136")
+	deleteWSCmd.Flags().StringVar(&config.DNSTSIGAlgorithm, "dns-tsig-algorithm", config.DNSTSIGAlgorithm, "TSIG algorithm for rfc2136")
+
+	err := viper.BindPFlags(deleteWSCmd.Flags())
+	if err != nil {
+		fmt.Println("an error has occurred while binding flags:", err)
+	}
+}
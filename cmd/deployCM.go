@@ -26,7 +26,7 @@ var deployCMCmd = &cobra.Command{
 
 func init() {
 	rootCmd.AddCommand(deployCMCmd)
-	deployCMCmd.Flags().StringVar(&config.ChaosMeshVersion, "chaos-mesh-version", config.ChaosMeshVersion, "chaosmesh version")
+	deployCMCmd.Flags().StringVar(&config.ChaosMeshVersion, "chaos-mesh-version", config.ChaosMeshVersion, "chaosmesh version, overrides the chart catalog")
 
 	err := viper.BindPFlags(deployCMCmd.Flags())
 	if err != nil {
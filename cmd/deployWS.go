@@ -35,7 +35,25 @@ func init() {
 	deployWSCmd.Flags().StringVar(&config.DashboardVersion, "dash-version", config.DashboardVersion, "docker image tag for spacemeshos/dash-backend")
 	deployWSCmd.Flags().StringVar(&config.ExplorerVersion, "explorer-version", config.ExplorerVersion, "docker image tag for spacemeshos/explorer-apiserver and spacemeshos/explorer-collector")
 	deployWSCmd.Flags().StringVar(&config.CloudflareAPIToken, "cloudflare-api-token", config.CloudflareAPIToken, "cloudflare API token")
+	deployWSCmd.Flags().StringVar(&config.DNSProvider, "dns-provider", config.DNSProvider, "DNS provider for the network records: cloudflare, route53, clouddns, rfc2136, coredns or none (only used if the network has no saved DNS settings)")
+	deployWSCmd.Flags().StringVar(&config.RootDomain, "root-domain", config.RootDomain, "root domain of the network records (only used if the network has no saved DNS settings)")
+	deployWSCmd.Flags().StringVar(&config.DNSRecordTemplate, "dns-record-template", config.DNSRecordTemplate, "name of the records under the root domain, {service} and {network} are replaced")
+	deployWSCmd.Flags().StringVar(&config.DNSServer, "dns-server", config.DNSServer, "address of the DNS server for rfc2136, e.g., 127.0.0.1:53")
+	deployWSCmd.Flags().StringVar(&config.DNSHostsFile, "dns-hosts-file", config.DNSHostsFile, "hosts file served by the CoreDNS hosts plugin for coredns")
+	deployWSCmd.Flags().StringVar(&config.DNSTSIGKey, "dns-tsig-key", config.DNSTSIGKey, "TSIG key name for rfc2136")
+	deployWSCmd.Flags().StringVar(&config.DNSTSIGSecret, "dns-tsig-secret", config.DNSTSIGSecret, "base64 TSIG secret for rfc2136")
+	deployWSCmd.Flags().StringVar(&config.DNSTSIGAlgorithm, "dns-tsig-algorithm", config.DNSTSIGAlgorithm, "TSIG algorithm for rfc2136")
+	deployWSCmd.Flags().StringVar(&config.PagerDutyToken, "pagerduty-token", config.PagerDutyToken, "pagerduty token of the spacemesh-api chart")
+	deployWSCmd.Flags().StringVar(&config.TLSIssuer, "tls-issuer", config.TLSIssuer, "files to use --tls-crt and --tls-key, acme to issue certificates with DNS-01 challenges through the DNS provider or selfsigned to issue them with a self-signed CA")
+	deployWSCmd.Flags().StringVar(&config.ACMEEmail, "acme-email", config.ACMEEmail, "email of the ACME account for expiry notices")
+	deployWSCmd.Flags().StringVar(&config.ACMEServer, "acme-server", config.ACMEServer, "ACME directory URL, e.g., the letsencrypt staging directory")
+	deployWSCmd.Flags().StringVar(&config.CertManagerVersion, "cert-manager-version", config.CertManagerVersion, "cert-manager chart version, overrides the chart catalog")
 	deployWSCmd.Flags().BoolVar(&config.Private, "private", config.Private, "is network private")
+	deployWSCmd.Flags().BoolVar(&config.DeployFaucet, "faucet", config.DeployFaucet, "deploy a faucet")
+	deployWSCmd.Flags().StringVar(&config.FaucetMiner, "faucet-miner", config.FaucetMiner, "number of the miner whose coinbase account funds the faucet")
+	deployWSCmd.Flags().StringVar(&config.FaucetImage, "faucet-image", config.FaucetImage, "docker image of spacecraft used to run the faucet")
+	deployWSCmd.Flags().Uint64Var(&config.FaucetAmount, "faucet-amount", config.FaucetAmount, "amount sent per faucet request")
+	deployWSCmd.Flags().StringVar(&config.FaucetInterval, "faucet-interval", config.FaucetInterval, "minimum time between faucet requests of an address or IP")
 
 	err := viper.BindPFlags(deployWSCmd.Flags())
 	if err != nil {
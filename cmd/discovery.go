@@ -0,0 +1,128 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/spacemeshos/go-spacecraft/log"
+	"github.com/spacemeshos/go-spacecraft/network"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+	"github.com/spf13/viper"
+)
+
+var discoveryCmd = &cobra.Command{
+	Use:   "discovery",
+	Short: "Manage the networks of the discovery service",
+	Long: `List, show, add, update and remove the networks of the discovery service. Every change is validated
+and only written if networks.json wasn't changed meanwhile, otherwise it's retried.`,
+}
+
+var discoveryListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List the networks of the discovery service",
+	Run: func(cmd *cobra.Command, args []string) {
+		err := network.ListDiscovery()
+		if err != nil {
+			log.Error.Println(err)
+			return
+		}
+	},
+}
+
+var discoveryShowCmd = &cobra.Command{
+	Use:   "show [name]",
+	Short: "Print a network of the discovery service as JSON",
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		err := network.ShowDiscovery(args[0])
+		if err != nil {
+			log.Error.Println(err)
+			return
+		}
+	},
+}
+
+var discoveryAddCmd = &cobra.Command{
+	Use:   "add",
+	Short: "Add a network to the discovery service",
+	Long:  `For example: spacecraft discovery add -f ./network.json`,
+	Run: func(cmd *cobra.Command, args []string) {
+		err := network.AddDiscovery()
+		if err != nil {
+			log.Error.Println(err)
+			return
+		}
+
+		log.Success.Println("network added to discovery service successfully")
+	},
+}
+
+var discoveryUpdateCmd = &cobra.Command{
+	Use:   "update [name]",
+	Short: "Update a network of the discovery service",
+	Long: `Updates the given fields of a network, or replaces it with the network of --discovery-file. For example:
+
+spacecraft discovery update devnet-201 --min-node-version v0.2.1 --max-node-version v0.2.2
+spacecraft discovery update devnet-201 -f ./network.json`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		for key, value := range map[string]*string{
+			"explorer-version": &config.ExplorerVersion,
+			"dash-version":     &config.DashboardVersion,
+		} {
+			if !isSet(cmd, key) {
+				*value = ""
+			}
+		}
+
+		err := network.UpdateDiscovery(args[0])
+		if err != nil {
+			log.Error.Println(err)
+			return
+		}
+
+		log.Success.Println("network updated in discovery service successfully")
+	},
+}
+
+var discoveryRemoveCmd = &cobra.Command{
+	Use:   "remove [name]",
+	Short: "Remove a network from the discovery service",
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		err := network.RemoveDiscovery(args[0])
+		if err != nil {
+			log.Error.Println(err)
+			return
+		}
+
+		log.Success.Println("network removed from discovery service successfully")
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(discoveryCmd)
+	discoveryCmd.AddCommand(discoveryListCmd)
+	discoveryCmd.AddCommand(discoveryShowCmd)
+	discoveryCmd.AddCommand(discoveryAddCmd)
+	discoveryCmd.AddCommand(discoveryUpdateCmd)
+	discoveryCmd.AddCommand(discoveryRemoveCmd)
+
+	discoveryCmd.PersistentFlags().BoolVar(&config.Private, "private", config.Private, "use the discovery service of private networks")
+
+	discoveryAddCmd.Flags().StringVarP(&config.DiscoveryFile, "discovery-file", "f", config.DiscoveryFile, "JSON file of the network")
+
+	discoveryUpdateCmd.Flags().StringVarP(&config.DiscoveryFile, "discovery-file", "f", config.DiscoveryFile, "JSON file replacing the network")
+	discoveryUpdateCmd.Flags().StringVar(&config.MinNodeVersion, "min-node-version", config.MinNodeVersion, "minimum go-spacemesh version of the network")
+	discoveryUpdateCmd.Flags().StringVar(&config.MaxNodeVersion, "max-node-version", config.MaxNodeVersion, "maximum go-spacemesh version of the network")
+	discoveryUpdateCmd.Flags().StringVar(&config.ExplorerVersion, "explorer-version", config.ExplorerVersion, "explorer version of the network")
+	discoveryUpdateCmd.Flags().StringVar(&config.DashboardVersion, "dash-version", config.DashboardVersion, "dash version of the network")
+	discoveryUpdateCmd.Flags().StringVar(&config.DiscoveryFaucet, "faucet-url", config.DiscoveryFaucet, "faucet URL of the network")
+
+	for _, flags := range []*pflag.FlagSet{discoveryCmd.PersistentFlags(), discoveryAddCmd.Flags(), discoveryUpdateCmd.Flags()} {
+		err := viper.BindPFlags(flags)
+		if err != nil {
+			fmt.Println("an error has occurred while binding flags:", err)
+		}
+	}
+}
@@ -0,0 +1,40 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/spacemeshos/go-spacecraft/log"
+	"github.com/spacemeshos/go-spacecraft/network"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+var exportKeysCmd = &cobra.Command{
+	Use:   "exportKeys",
+	Short: "Export coinbase keys of managed miners",
+	Long: `Writes the coinbase keys of all managed miners to a password encrypted file. The file can be
+imported when creating a network or adding a miner using --coinbase-keystore. For example:
+
+spacecraft exportKeys --keys-file=./devnet-keys.json --keystore-password=secret`,
+	Run: func(cmd *cobra.Command, args []string) {
+		err := network.ExportKeys()
+		if err != nil {
+			log.Error.Println(err)
+			return
+		}
+
+		log.Success.Println("keys exported successfully")
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(exportKeysCmd)
+
+	exportKeysCmd.Flags().StringVar(&config.KeysFile, "keys-file", config.KeysFile, "path of the encrypted keys file to write")
+	exportKeysCmd.Flags().StringVar(&config.KeystorePassword, "keystore-password", config.KeystorePassword, "password to encrypt the keys file")
+
+	err := viper.BindPFlags(exportKeysCmd.Flags())
+	if err != nil {
+		fmt.Println("an error has occurred while binding flags:", err)
+	}
+}
@@ -0,0 +1,44 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/spacemeshos/go-spacecraft/log"
+	"github.com/spacemeshos/go-spacecraft/network"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+var faucetCmd = &cobra.Command{
+	Use:   "faucet",
+	Short: "Run a faucet",
+	Long: `Runs the faucet HTTP API. It is deployed in the cluster using deployWS --faucet.
+For example: SPACECRAFT_FAUCET_KEY=<private key> spacecraft faucet --host=<miner grpc host>
+
+GET  /api/v1/info  address and balance of the faucet
+POST /api/v1/drip  {"address": "0x..."} sends --faucet-amount to the address`,
+	Run: func(cmd *cobra.Command, args []string) {
+		err := network.ServeFaucet()
+		if err != nil {
+			log.Error.Println(err)
+			return
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(faucetCmd)
+
+	faucetCmd.Flags().StringVar(&config.Host, "host", config.Host, "host to connect to")
+	faucetCmd.Flags().StringVar(&config.FaucetKey, "faucet-key", config.FaucetKey, "private key of the faucet account")
+	faucetCmd.Flags().Uint64Var(&config.FaucetAmount, "faucet-amount", config.FaucetAmount, "amount sent per request")
+	faucetCmd.Flags().StringVar(&config.FaucetInterval, "faucet-interval", config.FaucetInterval, "minimum time between requests of an address or IP")
+	faucetCmd.Flags().IntVar(&config.FaucetPort, "faucet-port", config.FaucetPort, "port of the HTTP API")
+	faucetCmd.Flags().StringSliceVar(&config.FaucetProxies, "faucet-trusted-proxies", config.FaucetProxies, "CIDRs, IPs or hostnames of the proxies whose X-Real-IP header is trusted, hostnames are resolved for every request")
+	faucetCmd.Flags().StringVar(&config.FaucetState, "faucet-state", config.FaucetState, "file the rate limits are saved to, they're kept in memory only if empty")
+
+	err := viper.BindPFlags(faucetCmd.Flags())
+	if err != nil {
+		fmt.Println("an error has occurred while binding flags:", err)
+	}
+}
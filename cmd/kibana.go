@@ -0,0 +1,67 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/spacemeshos/go-spacecraft/log"
+	"github.com/spacemeshos/go-spacecraft/network"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+var kibanaCmd = &cobra.Command{
+	Use:   "kibana",
+	Short: "Manage kibana saved objects",
+	Long: `Export dashboards, visualizations, searches and index patterns from a network and import them to
+existing networks. For example:
+
+spacecraft kibana export -n devnet-201
+spacecraft kibana import --all-networks`,
+}
+
+var kibanaExportCmd = &cobra.Command{
+	Use:   "export",
+	Short: "Export kibana saved objects of a network",
+	Run: func(cmd *cobra.Command, args []string) {
+		err := network.ExportKibana()
+		if err != nil {
+			log.Error.Println(err)
+			return
+		}
+
+		log.Success.Println("saved objects exported successfully")
+	},
+}
+
+var kibanaImportCmd = &cobra.Command{
+	Use:   "import",
+	Short: "Import kibana saved objects to networks",
+	Run: func(cmd *cobra.Command, args []string) {
+		err := network.ImportKibana()
+		if err != nil {
+			log.Error.Println(err)
+			return
+		}
+
+		log.Success.Println("saved objects imported successfully")
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(kibanaCmd)
+	kibanaCmd.AddCommand(kibanaExportCmd)
+	kibanaCmd.AddCommand(kibanaImportCmd)
+
+	kibanaCmd.PersistentFlags().StringVarP(&config.KibanaSavedObjects, "kibana-saved-objects", "f", config.KibanaSavedObjects, "path of the saved objects file")
+	kibanaImportCmd.Flags().BoolVar(&config.AllNetworks, "all-networks", config.AllNetworks, "import to every network")
+
+	err := viper.BindPFlags(kibanaCmd.PersistentFlags())
+	if err != nil {
+		fmt.Println("an error has occurred while binding flags:", err)
+	}
+
+	err = viper.BindPFlags(kibanaImportCmd.Flags())
+	if err != nil {
+		fmt.Println("an error has occurred while binding flags:", err)
+	}
+}
@@ -0,0 +1,42 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/spacemeshos/go-spacecraft/log"
+	"github.com/spacemeshos/go-spacecraft/network"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+var loadgenCmd = &cobra.Command{
+	Use:   "loadgen",
+	Short: "Generate transaction load",
+	Long: `Submits transfers between the coinbase accounts of the managed miners and reports submission, inclusion
+latency and failure statistics. For example: spacecraft loadgen --loadgen-rate=5 --loadgen-duration=10m --loadgen-pattern=ramp
+
+Patterns: constant, ramp (the rate grows from zero to --loadgen-rate), burst (the load of every 10 seconds is submitted at once)`,
+	Run: func(cmd *cobra.Command, args []string) {
+		err := network.Loadgen()
+		if err != nil {
+			log.Error.Println(err)
+			return
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(loadgenCmd)
+
+	loadgenCmd.Flags().StringVar(&config.Host, "host", config.Host, "host to connect to (defaults to a healthy managed miner)")
+	loadgenCmd.Flags().Float64Var(&config.LoadgenRate, "loadgen-rate", config.LoadgenRate, "transactions per second")
+	loadgenCmd.Flags().StringVar(&config.LoadgenDuration, "loadgen-duration", config.LoadgenDuration, "duration of the load")
+	loadgenCmd.Flags().StringVar(&config.LoadgenPattern, "loadgen-pattern", config.LoadgenPattern, "load pattern: constant, ramp or burst")
+	loadgenCmd.Flags().Uint64Var(&config.LoadgenAmount, "loadgen-amount", config.LoadgenAmount, "amount of every transfer")
+	loadgenCmd.Flags().Uint64Var(&config.LoadgenFee, "loadgen-fee", config.LoadgenFee, "fee of every transfer")
+
+	err := viper.BindPFlags(loadgenCmd.Flags())
+	if err != nil {
+		fmt.Println("an error has occurred while binding flags:", err)
+	}
+}
@@ -0,0 +1,53 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/spacemeshos/go-spacecraft/log"
+	"github.com/spacemeshos/go-spacecraft/network"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+var logsCmd = &cobra.Command{
+	Use:   "logs",
+	Short: "Search the logs of a network",
+	Long: `Searches the logs stored in elasticsearch. For example:
+
+spacecraft logs --log-miner=miner-1,miner-2 --log-level=error --log-since=1h
+spacecraft logs --log-module=hare --from-layer=100 --to-layer=110 -f
+spacecraft logs --log-query="block not found" --log-export=logs.ndjson.gz`,
+	Run: func(cmd *cobra.Command, args []string) {
+		err := network.Logs()
+		if err != nil {
+			log.Error.Println(err)
+			return
+		}
+
+		if config.LogExport != "" {
+			log.Success.Println("logs exported successfully")
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(logsCmd)
+
+	logsCmd.Flags().StringVar(&config.LogIndex, "log-index", config.LogIndex, "elasticsearch index pattern, ws-* for the web services")
+	logsCmd.Flags().StringSliceVar(&config.LogMiners, "log-miner", config.LogMiners, "miner or poet names")
+	logsCmd.Flags().StringVar(&config.LogLevel, "log-level", config.LogLevel, "log level")
+	logsCmd.Flags().StringVar(&config.LogModule, "log-module", config.LogModule, "go-spacemesh module, e.g., hare")
+	logsCmd.Flags().StringVar(&config.LogQuery, "log-query", config.LogQuery, "free text query")
+	logsCmd.Flags().StringVar(&config.LogSince, "log-since", config.LogSince, "RFC3339 time or duration before now, e.g., 30m")
+	logsCmd.Flags().StringVar(&config.LogUntil, "log-until", config.LogUntil, "RFC3339 time or duration before now")
+	logsCmd.Flags().IntVar(&config.FromLayer, "from-layer", config.FromLayer, "first layer")
+	logsCmd.Flags().IntVar(&config.ToLayer, "to-layer", config.ToLayer, "last layer")
+	logsCmd.Flags().IntVar(&config.LogLimit, "log-limit", config.LogLimit, "number of latest logs to print")
+	logsCmd.Flags().BoolVarP(&config.LogFollow, "log-follow", "f", config.LogFollow, "follow new logs")
+	logsCmd.Flags().StringVar(&config.LogExport, "log-export", config.LogExport, "export all matching logs to a NDJSON file, gzip compressed if it ends with .gz")
+
+	err := viper.BindPFlags(logsCmd.Flags())
+	if err != nil {
+		fmt.Println("an error has occurred while binding flags:", err)
+	}
+}
@@ -0,0 +1,40 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/spacemeshos/go-spacecraft/log"
+	"github.com/spacemeshos/go-spacecraft/network"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+var restoreLogsCmd = &cobra.Command{
+	Use:   "restoreLogs",
+	Short: "Restore the archived logs of a network",
+	Long: `Restores the logs archived by a deleted network into the elasticsearch of another network.
+The indices are prefixed with the archived network name. For example:
+
+spacecraft restoreLogs --archive=devnet-201 -n devnet-203`,
+	Run: func(cmd *cobra.Command, args []string) {
+		err := network.RestoreLogs()
+		if err != nil {
+			log.Error.Println(err)
+			return
+		}
+
+		log.Success.Println("logs restored successfully")
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(restoreLogsCmd)
+
+	restoreLogsCmd.Flags().StringVar(&config.ArchivedNetwork, "archive", config.ArchivedNetwork, "name of the network whose logs are restored")
+	restoreLogsCmd.Flags().StringVar(&config.LogsBucket, "logs-bucket", config.LogsBucket, "GCS bucket of the logs archive")
+
+	err := viper.BindPFlags(restoreLogsCmd.Flags())
+	if err != nil {
+		fmt.Println("an error has occurred while binding flags:", err)
+	}
+}
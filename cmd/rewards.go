@@ -12,6 +12,8 @@ import (
 var rewardsCmd = &cobra.Command{
 	Use:   "rewards",
 	Short: "Rewards of the network",
+	Long: `Reports the rewards of every managed miner for a range of layers, compares them with the expected share
+of each miner and flags miners earning nothing. For example: spacecraft rewards --from-layer=10 --to-layer=50 -o rewards.csv`,
 	Run: func(cmd *cobra.Command, args []string) {
 		err := network.Rewards()
 		if err != nil {
@@ -24,7 +26,10 @@ var rewardsCmd = &cobra.Command{
 func init() {
 	rootCmd.AddCommand(rewardsCmd)
 
-	rewardsCmd.Flags().StringVar(&config.Host, "host", config.Host, "host to connect to")
+	rewardsCmd.Flags().StringVar(&config.Host, "host", config.Host, "host to connect to (defaults to a healthy managed miner)")
+	rewardsCmd.Flags().IntVar(&config.FromLayer, "from-layer", config.FromLayer, "first layer of the rewards")
+	rewardsCmd.Flags().IntVar(&config.ToLayer, "to-layer", config.ToLayer, "last layer of the rewards (defaults to the current layer)")
+	rewardsCmd.Flags().StringVarP(&config.RewardsFile, "rewards-file", "o", config.RewardsFile, "export the report to a .csv or .json file")
 
 	err := viper.BindPFlags(rewardsCmd.Flags())
 	if err != nil {
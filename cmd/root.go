@@ -2,9 +2,14 @@ package cmd
 
 import (
 	"fmt"
+	stdlog "log"
+	"os"
 	"strings"
 
+	"github.com/fatih/color"
 	cfg "github.com/spacemeshos/go-spacecraft/config"
+	"github.com/spacemeshos/go-spacecraft/log"
+	"github.com/spacemeshos/go-spacecraft/secrets"
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
 )
@@ -12,6 +17,10 @@ import (
 var cfgFile string
 var config = &cfg.Config
 
+// restoreOutput restores os.Stdout and os.Stderr after the redacted output
+// is written
+var restoreOutput = func() {}
+
 // rootCmd represents the base command when called without any subcommands
 var rootCmd = &cobra.Command{
 	Use:   "spacecraft",
@@ -35,7 +44,33 @@ func init() {
 }
 
 func Execute() {
-	cobra.CheckErr(rootCmd.Execute())
+	restore, err := secrets.RedactOutput()
+	cobra.CheckErr(err)
+
+	restoreOutput = restore
+
+	// the colored output of the log package is written to the files which
+	// were os.Stdout and os.Stderr when the color package was initialized
+	color.Output = os.Stdout
+	color.Error = os.Stderr
+
+	err = rootCmd.Execute()
+
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "Error:", err)
+	}
+
+	restoreOutput()
+
+	if err != nil {
+		os.Exit(1)
+	}
+}
+
+// exit exits after the redacted output is written
+func exit(code int) {
+	restoreOutput()
+	os.Exit(code)
 }
 
 func initConfig() {
@@ -50,4 +85,17 @@ func initConfig() {
 	}
 
 	viper.Unmarshal(&config)
+
+	if err := config.ResolveSecrets(); err != nil {
+		log.Error.Println("cannot resolve secrets:", err)
+		exit(1)
+	}
+
+	if err := config.ValidateMinerGroups(); err != nil {
+		log.Error.Println("invalid miner groups:", err)
+		exit(1)
+	}
+
+	// helm prints its debug output with the standard logger
+	stdlog.SetOutput(secrets.Writer(os.Stderr))
 }
@@ -0,0 +1,54 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/spacemeshos/go-spacecraft/log"
+	"github.com/spacemeshos/go-spacecraft/network"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+var runCmd = &cobra.Command{
+	Use:   "run",
+	Short: "Run a scenario",
+	Long: `Runs the steps of a scenario file in order and prints a pass/fail report. For example: spacecraft run -f ./scenario.yaml
+
+steps:
+  - create: true
+  - waitEpoch: 2
+  - name: kill bootnodes
+    chaos:
+      - name: kill-bootnodes
+        action: pod-kill
+        target: bootnodes
+  - addMiners: 2
+  - upgrade: spacemeshos/go-spacemesh-dev:latest
+  - waitLayer: 40
+  - assert:
+      minersReady: true
+      layerHashConsistent: 30
+
+Steps: create, waitLayer, waitEpoch, sleep, addMiners, deleteMiner, upgrade, chaos, assert
+Assertions: minersReady, layerHashConsistent`,
+	Run: func(cmd *cobra.Command, args []string) {
+		err := network.Run()
+		if err != nil {
+			log.Error.Println(err)
+			return
+		}
+
+		log.Success.Println("scenario passed successfully")
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(runCmd)
+
+	runCmd.Flags().StringVarP(&config.ScenarioFile, "scenario-file", "f", config.ScenarioFile, "scenario file")
+
+	err := viper.BindPFlags(runCmd.Flags())
+	if err != nil {
+		fmt.Println("an error has occurred while binding flags:", err)
+	}
+}
@@ -0,0 +1,24 @@
+package cmd
+
+import (
+	"github.com/spacemeshos/go-spacecraft/log"
+	"github.com/spacemeshos/go-spacecraft/network"
+	"github.com/spf13/cobra"
+)
+
+var statusCmd = &cobra.Command{
+	Use:   "status",
+	Short: "Status of the managed miners",
+	Long:  `Lists the managed miners with their group, cohort, image and readiness. For example: spacecraft status`,
+	Run: func(cmd *cobra.Command, args []string) {
+		err := network.Status()
+		if err != nil {
+			log.Error.Println(err)
+			return
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(statusCmd)
+}
@@ -0,0 +1,24 @@
+package cmd
+
+import (
+	"github.com/spacemeshos/go-spacecraft/log"
+	"github.com/spacemeshos/go-spacecraft/network"
+	"github.com/spf13/cobra"
+)
+
+var statusWSCmd = &cobra.Command{
+	Use:   "statusWS",
+	Short: "Shows the status of web services",
+	Long:  `Shows the helm releases, pod readiness and ingress IP of the web services. For example: spacecraft statusWS -n devnet-201`,
+	Run: func(cmd *cobra.Command, args []string) {
+		err := network.StatusWS()
+		if err != nil {
+			log.Error.Println(err)
+			return
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(statusWSCmd)
+}
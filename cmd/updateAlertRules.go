@@ -0,0 +1,36 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/spacemeshos/go-spacecraft/log"
+	"github.com/spacemeshos/go-spacecraft/network"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+var updateAlertRulesCmd = &cobra.Command{
+	Use:   "updateAlertRules",
+	Short: "Updates the prometheus alert rules",
+	Long:  `Replaces the alert rules of a network deployed with --metrics with the rules of this version of spacecraft. For example: spacecraft updateAlertRules`,
+	Run: func(cmd *cobra.Command, args []string) {
+		err := network.UpdateAlertRules()
+		if err != nil {
+			log.Error.Println(err)
+			return
+		}
+
+		log.Success.Println("alert rules updated successfully")
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(updateAlertRulesCmd)
+
+	updateAlertRulesCmd.Flags().StringVar(&config.AlertRules, "alert-rules", config.AlertRules, "directory of prometheus alert rules")
+
+	err := viper.BindPFlags(updateAlertRulesCmd.Flags())
+	if err != nil {
+		fmt.Println("an error has occurred while binding flags:", err)
+	}
+}
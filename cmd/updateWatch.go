@@ -0,0 +1,39 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/spacemeshos/go-spacecraft/log"
+	"github.com/spacemeshos/go-spacecraft/network"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+var updateWatchCmd = &cobra.Command{
+	Use:   "updateWatch",
+	Short: "Updates spacemesh-watch",
+	Long: `Updates the targets of spacemesh-watch, and the image and alert thresholds if they are given. For example:
+
+spacecraft updateWatch --sw-image=spacemeshos/spacemesh-watch:v0.0.2 --watch-flags="--layer-wait-time=10m"`,
+	Run: func(cmd *cobra.Command, args []string) {
+		err := network.UpdateWatch(isSet(cmd, "sw-image"), isSet(cmd, "watch-flags"))
+		if err != nil {
+			log.Error.Println(err)
+			return
+		}
+
+		log.Success.Println("spacemesh-watch updated successfully")
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(updateWatchCmd)
+
+	updateWatchCmd.Flags().StringVar(&config.SpacemeshWatchImage, "sw-image", config.SpacemeshWatchImage, "docker image for spacemesh-watch")
+	updateWatchCmd.Flags().StringSliceVar(&config.WatchFlags, "watch-flags", config.WatchFlags, "extra flags of spacemesh-watch, e.g., alert thresholds")
+
+	err := viper.BindPFlags(updateWatchCmd.Flags())
+	if err != nil {
+		fmt.Println("an error has occurred while binding flags:", err)
+	}
+}
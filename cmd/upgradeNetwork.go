@@ -12,7 +12,8 @@ import (
 var upgradeNetworkCmd = &cobra.Command{
 	Use:   "upgradeNetwork",
 	Short: "Upgrade a network",
-	Long: `Used to upgrade go-sm build for all miners in a network. For example:
+	Long: `Used to upgrade go-sm build for all miners in a network. If all miners are upgraded the node versions of
+the network in the discovery service are updated. For example:
 
 spacecraft upgradeNetwork --go-sm-image=spacemeshos/go-spacemesh:v0.1.26`,
 	Run: func(cmd *cobra.Command, args []string) {
@@ -31,6 +32,9 @@ func init() {
 
 	upgradeNetworkCmd.Flags().StringVar(&config.GoSmImage, "go-sm-image", config.GoSmImage, "docker image for go-spacemesh build")
 	upgradeNetworkCmd.Flags().IntVar(&config.RestartWaitTime, "restart-wait-time", config.RestartWaitTime, "sleep time between miner restarts in minutes")
+	upgradeNetworkCmd.Flags().StringVar(&config.MinerGroup, "miner-group", config.MinerGroup, "only upgrade miners of this group")
+	upgradeNetworkCmd.Flags().StringVar(&config.AdversarialImage, "adversarial-image", config.AdversarialImage, "docker image for adversarial miners (default: go-sm-image)")
+	upgradeNetworkCmd.Flags().BoolVar(&config.Private, "private", config.Private, "is network private")
 
 	err := viper.BindPFlags(upgradeNetworkCmd.Flags())
 	if err != nil {
@@ -0,0 +1,67 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/spacemeshos/go-spacecraft/log"
+	"github.com/spacemeshos/go-spacecraft/network"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+var upgradeWSCmd = &cobra.Command{
+	Use:   "upgradeWS",
+	Short: "Upgrades web services",
+	Long: `Upgrades the API, explorer and dash charts of a network. The options which aren't given keep
+the values of the installed charts.
+
+For example: spacecraft upgradeWS -n devnet-201 --explorer-version v1.2.0 --go-sm-image spacemeshos/go-spacemesh:v0.2.1`,
+	Run: func(cmd *cobra.Command, args []string) {
+		for key, value := range map[string]*string{
+			"go-sm-image":      &config.GoSmImage,
+			"explorer-version": &config.ExplorerVersion,
+			"dash-version":     &config.DashboardVersion,
+			"miner-ram":        &config.MinerMemory,
+			"miner-cpu":        &config.MinerCPU,
+			"pagerduty-token":  &config.PagerDutyToken,
+		} {
+			if !isSet(cmd, key) {
+				*value = ""
+			}
+		}
+
+		err := network.UpgradeWS()
+		if err != nil {
+			log.Error.Println(err)
+			return
+		}
+
+		log.Success.Println("web services upgraded successfully")
+	},
+}
+
+// isSet is true if the option is given as a flag of cmd, an ENV or in the
+// config file
+func isSet(cmd *cobra.Command, key string) bool {
+	_, env := os.LookupEnv("SPACECRAFT_" + strings.ToUpper(strings.ReplaceAll(key, "-", "_")))
+
+	return cmd.Flags().Changed(key) || env || viper.InConfig(key)
+}
+
+func init() {
+	rootCmd.AddCommand(upgradeWSCmd)
+	upgradeWSCmd.Flags().BoolVar(&config.Private, "private", config.Private, "is network private")
+	upgradeWSCmd.Flags().StringVar(&config.GoSmImage, "go-sm-image", config.GoSmImage, "docker image for go-spacemesh build")
+	upgradeWSCmd.Flags().StringVar(&config.DashboardVersion, "dash-version", config.DashboardVersion, "docker image tag for spacemeshos/dash-backend")
+	upgradeWSCmd.Flags().StringVar(&config.ExplorerVersion, "explorer-version", config.ExplorerVersion, "docker image tag for spacemeshos/explorer-apiserver and spacemeshos/explorer-collector")
+	upgradeWSCmd.Flags().StringVar(&config.MinerMemory, "miner-ram", config.MinerMemory, "RAM for each miner")
+	upgradeWSCmd.Flags().StringVar(&config.MinerCPU, "miner-cpu", config.MinerCPU, "vCPUs for each miner")
+	upgradeWSCmd.Flags().StringVar(&config.PagerDutyToken, "pagerduty-token", config.PagerDutyToken, "pagerduty token of the spacemesh-api chart")
+
+	err := viper.BindPFlags(upgradeWSCmd.Flags())
+	if err != nil {
+		fmt.Println("an error has occurred while binding flags:", err)
+	}
+}
@@ -0,0 +1,39 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/spacemeshos/go-spacecraft/log"
+	"github.com/spacemeshos/go-spacecraft/network"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+var verifyCmd = &cobra.Command{
+	Use:   "verify",
+	Short: "Verify consensus of the miners",
+	Long: `Compares the layer hashes, blocks and global state roots reported by all miners for a range of layers and
+reports every miner that diverges from the majority together with the first layer it disagreed on.
+For example: spacecraft verify --from-layer=10 --to-layer=50`,
+	Run: func(cmd *cobra.Command, args []string) {
+		err := network.Verify()
+		if err != nil {
+			log.Error.Println(err)
+			return
+		}
+
+		log.Success.Println("all miners agree")
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(verifyCmd)
+
+	verifyCmd.Flags().IntVar(&config.FromLayer, "from-layer", config.FromLayer, "first layer to compare")
+	verifyCmd.Flags().IntVar(&config.ToLayer, "to-layer", config.ToLayer, "last layer to compare (defaults to the last layer finished by all miners)")
+
+	err := viper.BindPFlags(verifyCmd.Flags())
+	if err != nil {
+		fmt.Println("an error has occurred while binding flags:", err)
+	}
+}
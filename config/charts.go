@@ -0,0 +1,80 @@
+package config
+
+// Chart is an entry of the chart catalog. Chart is the name of the chart in
+// Repo, or the path of a local chart directory or tarball if Repo is empty.
+// Values is YAML merged over the values generated by spacecraft.
+type Chart struct {
+	Repo    string `mapstructure:"repo"`
+	Chart   string `mapstructure:"chart"`
+	Version string `mapstructure:"version"`
+	Values  string `mapstructure:"values"`
+}
+
+// ChartCatalog maps the add-ons to their charts
+type ChartCatalog map[string]Chart
+
+// Local is true if the chart is read from a directory or tarball
+func (c Chart) Local() bool {
+	return c.Repo == ""
+}
+
+// DefaultCharts are the charts of every add-on deployed by spacecraft. The
+// web services charts aren't released with fixed versions so they're
+// unpinned until a version is set in the catalog.
+var DefaultCharts = map[string]Chart{
+	"ingress-nginx":         {Repo: "https://kubernetes.github.io/ingress-nginx", Chart: "ingress-nginx", Version: "3.34.0"},
+	"elasticsearch":         {Repo: "https://helm.elastic.co", Chart: "elasticsearch", Version: "7.15.0"},
+	"kibana":                {Repo: "https://helm.elastic.co", Chart: "kibana", Version: "7.15.0"},
+	"filebeat":              {Repo: "https://helm.elastic.co", Chart: "filebeat", Version: "7.15.0"},
+	"loki-stack":            {Repo: "https://grafana.github.io/helm-charts", Chart: "loki-stack", Version: "2.5.0"},
+	"kube-prometheus-stack": {Repo: "https://prometheus-community.github.io/helm-charts", Chart: "kube-prometheus-stack", Version: "18.0.0"},
+	"chaos-mesh":            {Repo: "https://charts.chaos-mesh.org", Chart: "chaos-mesh", Version: "2.1.1"},
+	"cert-manager":          {Repo: "https://charts.jetstack.io", Chart: "cert-manager", Version: "v1.5.4"},
+	"spacemesh-api":         {Repo: "https://spacemeshos.github.io/ws-helm-charts", Chart: "spacemesh-api"},
+	"spacemesh-explorer":    {Repo: "https://spacemeshos.github.io/ws-helm-charts", Chart: "spacemesh-explorer"},
+	"spacemesh-dash":        {Repo: "https://spacemeshos.github.io/ws-helm-charts", Chart: "spacemesh-dash"},
+}
+
+// versionOptions are the options which override the version of a chart
+func (c *Configuration) versionOptions() map[string]string {
+	return map[string]string{
+		"loki-stack":            c.LokiVersion,
+		"kube-prometheus-stack": c.PrometheusVersion,
+		"chaos-mesh":            c.ChaosMeshVersion,
+		"cert-manager":          c.CertManagerVersion,
+	}
+}
+
+// Chart returns the catalog entry of a chart. Empty fields of the configured
+// entry fall back to the default entry, a local chart doesn't use the
+// default repo.
+func (c *Configuration) Chart(name string) Chart {
+	chart := DefaultCharts[name]
+	configured := c.Charts[name]
+
+	if configured.Chart != "" {
+		chart.Chart = configured.Chart
+		chart.Repo = configured.Repo
+		chart.Version = configured.Version
+	}
+
+	if configured.Repo != "" {
+		chart.Repo = configured.Repo
+	}
+
+	if configured.Version != "" {
+		chart.Version = configured.Version
+	}
+
+	if version := c.versionOptions()[name]; version != "" {
+		chart.Version = version
+	}
+
+	if chart.Local() {
+		chart.Version = ""
+	}
+
+	chart.Values = configured.Values
+
+	return chart
+}
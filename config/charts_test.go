@@ -0,0 +1,59 @@
+package config
+
+import (
+	"testing"
+)
+
+func TestChart(t *testing.T) {
+	loki := DefaultCharts["loki-stack"]
+
+	tests := []struct {
+		name        string
+		charts      ChartCatalog
+		lokiVersion string
+		expected    Chart
+	}{
+		{
+			name:     "default entry",
+			expected: loki,
+		},
+		{
+			name:     "pinned version",
+			charts:   ChartCatalog{"loki-stack": {Version: "2.6.0"}},
+			expected: Chart{Repo: loki.Repo, Chart: loki.Chart, Version: "2.6.0"},
+		},
+		{
+			name:     "mirrored repo",
+			charts:   ChartCatalog{"loki-stack": {Repo: "https://charts.example.com"}},
+			expected: Chart{Repo: "https://charts.example.com", Chart: loki.Chart, Version: loki.Version},
+		},
+		{
+			name:     "other chart is unpinned",
+			charts:   ChartCatalog{"loki-stack": {Repo: "https://charts.example.com", Chart: "loki"}},
+			expected: Chart{Repo: "https://charts.example.com", Chart: "loki"},
+		},
+		{
+			name:     "local chart has no repo or version",
+			charts:   ChartCatalog{"loki-stack": {Chart: "./charts/loki-stack", Version: "1.0.0"}},
+			expected: Chart{Chart: "./charts/loki-stack"},
+		},
+		{
+			name:        "version option overrides the catalog",
+			charts:      ChartCatalog{"loki-stack": {Version: "2.6.0", Values: "loki:\n  enabled: true\n"}},
+			lokiVersion: "2.7.0",
+			expected:    Chart{Repo: loki.Repo, Chart: loki.Chart, Version: "2.7.0", Values: "loki:\n  enabled: true\n"},
+		},
+	}
+
+	for _, test := range tests {
+		c := Configuration{Charts: test.charts, LokiVersion: test.lokiVersion}
+
+		if chart := c.Chart("loki-stack"); chart != test.expected {
+			t.Errorf("%s: Chart() = %+v, expected %+v", test.name, chart, test.expected)
+		}
+	}
+
+	if chart := (&Configuration{}).Chart("unknown"); chart.Chart != "" {
+		t.Errorf("unknown chart is %+v", chart)
+	}
+}
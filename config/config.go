@@ -1,74 +1,153 @@
 package config
 
 type Configuration struct {
-	NetworkName              string `mapstructure:"network-name"`
-	NumberOfMiners           int    `mapstructure:"miners"`
-	NumberOfPoets            int    `mapstructure:"poets"`
-	MinerMemory              string `mapstructure:"miner-ram"`
-	MinerCPU                 string `mapstructure:"miner-cpu"`
-	PoetMemory               string `mapstructure:"poet-ram"`
-	PoetCPU                  string `mapstructure:"poet-cpu"`
-	MinerDiskSize            string `mapstructure:"miner-disk-size"`
-	PoetDiskSize             string `mapstructure:"poet-disk-size"`
-	GoSmImage                string `mapstructure:"go-sm-image"`
-	PoetImage                string `mapstructure:"poet-image"`
-	SpacemeshWatchImage      string `mapstructure:"sw-image"`
-	GCPProject               string `mapstructure:"gcp-project"`
-	GCPLocation              string `mapstructure:"gcp-location"`
-	GCPZone                  string `mapstructure:"gcp-zone"`
-	GCPMachineType           string `mapstructure:"gcp-machine-type"`
-	GoSmConfig               string `mapstructure:"go-sm-config"`
-	InitPhaseShift           int    `mapstructure:"init-phase-shift"`
-	PoetGatewayAmount        int    `mapstructure:"poet-gateway-amount"`
-	BootnodeAmount           int    `mapstructure:"bootnode-amount"`
-	GCPMachineCPU            int    `mapstructure:"gcp-machine-cpu"`
-	GCPMachineMemory         int    `mapstructure:"gcp-machine-memory"`
-	GenesisDelay             int    `mapstructure:"genesis-delay"`
-	MinerNumber              string `mapstructure:"miner-number"`
-	MinerGoSmConfig          string `mapstructure:"miner-go-sm-config"`
-	RestartWaitTime          int    `mapstructure:"restart-wait-time"`
-	Bootstrap                bool   `mapstructure:"bootstrap"`
-	KibanaSavedObjects       string `mapstructure:"kibana-saved-objects"`
-	ESCert                   string `mapstructure:"es-cert"`
-	ESDiskSize               string `mapstructure:"es-disk-size"`
-	ESMemory                 string `mapstructure:"es-memory"`
-	ESCPU                    string `mapstructure:"es-cpu"`
-	ESHeapMemory             string `mapstructure:"es-heap-memory"`
-	ESReplicas               string `mapstructure:"es-replicas"`
-	ESMasterNodes            string `mapstructure:"es-master-nodes"`
-	KibanaMemory             string `mapstructure:"kibana-memory"`
-	KibanaCPU                string `mapstructure:"kibana-cpu"`
-	LogsExpiry               string `mapstructure:"logs-expiry"`
-	Host                     string `mapstructure:"host"`
-	PyroscopeImage           string `mapstructure:"pyroscope-image"`
-	PyroscopeCPU             string `mapstructure:"pyroscope-cpu"`
-	PyroscopeMemory          string `mapstructure:"pyroscope-memory"`
-	DeployPyroscope          bool   `mapstructure:"deploy-pyroscope"`
-	Metrics                  bool   `mapstructure:"metrics"`
-	MaxConcurrentDeployments int    `mapstructure:"max-concurrent-deployments"`
-	EnableJsonAPI            bool   `mapstructure:"enable-json-api"`
-	EnableGoDebug            bool   `mapstructure:"enable-go-debug"`
-	AcceleratorCount         int64  `mapstructure:"accelerator-count"`
-	AcceletatorType          string `mapstructure:"accelerator-type"`
-	ImageType                string `mapstructure:"image-type"`
-	SlackChannelId           string `mapstructure:"slack-channel-id"`
-	SlackToken               string `mapstructure:"slack-token"`
-	EnableSlackAlerts        bool   `mapstructure:"enable-slack-alerts"`
-	CloudflareAPIToken       string `mapstructure:"cloudflare-api-token"`
-	DashboardVersion         string `mapstructure:"dash-version"`
-	ExplorerVersion          string `mapstructure:"explorer-version"`
-	SmappVersion             string `mapstructure:"smapp-version"`
-	TLSKey                   string `mapstructure:"tls-key"`
-	TLSCert                  string `mapstructure:"tls-cert"`
-	GoSmReleaseVersion       string `mapstructure:"go-sm-release-version"`
-	GithubToken              string `mapstructure:"github-token"`
-	KeepLogsMetrics          bool   `mapstructure:"keep-logs-metrics"`
-	ChaosMesh                bool   `mapstructure:"chaos-mesh"`
-	ChaosMeshVersion         string `mapstructure:"chaos-mesh-version"`
-	UseVPC                   bool   `mapstructure:"use-vpc"`
-	VPC                      string `mapstructure:"vpc"`
-	Private                  bool   `mapstructure:"private"`
-	PushGatewayURL           string `mapstructure:"push-gateway-url"`
+	NetworkName              string       `mapstructure:"network-name"`
+	NumberOfMiners           int          `mapstructure:"miners"`
+	NumberOfPoets            int          `mapstructure:"poets"`
+	MinerMemory              string       `mapstructure:"miner-ram"`
+	MinerCPU                 string       `mapstructure:"miner-cpu"`
+	PoetMemory               string       `mapstructure:"poet-ram"`
+	PoetCPU                  string       `mapstructure:"poet-cpu"`
+	MinerDiskSize            string       `mapstructure:"miner-disk-size"`
+	PoetDiskSize             string       `mapstructure:"poet-disk-size"`
+	GoSmImage                string       `mapstructure:"go-sm-image"`
+	PoetImage                string       `mapstructure:"poet-image"`
+	SpacemeshWatchImage      string       `mapstructure:"sw-image"`
+	GCPProject               string       `mapstructure:"gcp-project"`
+	GCPLocation              string       `mapstructure:"gcp-location"`
+	GCPZone                  string       `mapstructure:"gcp-zone"`
+	GCPMachineType           string       `mapstructure:"gcp-machine-type"`
+	GoSmConfig               string       `mapstructure:"go-sm-config"`
+	InitPhaseShift           int          `mapstructure:"init-phase-shift"`
+	PoetGatewayAmount        int          `mapstructure:"poet-gateway-amount"`
+	BootnodeAmount           int          `mapstructure:"bootnode-amount"`
+	GCPMachineCPU            int          `mapstructure:"gcp-machine-cpu"`
+	GCPMachineMemory         int          `mapstructure:"gcp-machine-memory"`
+	GenesisDelay             int          `mapstructure:"genesis-delay"`
+	MinerNumber              string       `mapstructure:"miner-number"`
+	MinerGoSmConfig          string       `mapstructure:"miner-go-sm-config"`
+	RestartWaitTime          int          `mapstructure:"restart-wait-time"`
+	Bootstrap                bool         `mapstructure:"bootstrap"`
+	KibanaSavedObjects       string       `mapstructure:"kibana-saved-objects"`
+	ESCert                   string       `mapstructure:"es-cert"`
+	ESDiskSize               string       `mapstructure:"es-disk-size"`
+	ESMemory                 string       `mapstructure:"es-memory"`
+	ESCPU                    string       `mapstructure:"es-cpu"`
+	ESHeapMemory             string       `mapstructure:"es-heap-memory"`
+	ESReplicas               string       `mapstructure:"es-replicas"`
+	ESMasterNodes            string       `mapstructure:"es-master-nodes"`
+	KibanaMemory             string       `mapstructure:"kibana-memory"`
+	KibanaCPU                string       `mapstructure:"kibana-cpu"`
+	LogsExpiry               string       `mapstructure:"logs-expiry"`
+	Host                     string       `mapstructure:"host"`
+	PyroscopeImage           string       `mapstructure:"pyroscope-image"`
+	PyroscopeCPU             string       `mapstructure:"pyroscope-cpu"`
+	PyroscopeMemory          string       `mapstructure:"pyroscope-memory"`
+	DeployPyroscope          bool         `mapstructure:"deploy-pyroscope"`
+	Metrics                  bool         `mapstructure:"metrics"`
+	MaxConcurrentDeployments int          `mapstructure:"max-concurrent-deployments"`
+	EnableJsonAPI            bool         `mapstructure:"enable-json-api"`
+	EnableGoDebug            bool         `mapstructure:"enable-go-debug"`
+	AcceleratorCount         int64        `mapstructure:"accelerator-count"`
+	AcceletatorType          string       `mapstructure:"accelerator-type"`
+	ImageType                string       `mapstructure:"image-type"`
+	SlackChannelId           string       `mapstructure:"slack-channel-id"`
+	SlackToken               string       `mapstructure:"slack-token"`
+	EnableSlackAlerts        bool         `mapstructure:"enable-slack-alerts"`
+	CloudflareAPIToken       string       `mapstructure:"cloudflare-api-token"`
+	DashboardVersion         string       `mapstructure:"dash-version"`
+	ExplorerVersion          string       `mapstructure:"explorer-version"`
+	SmappVersion             string       `mapstructure:"smapp-version"`
+	TLSKey                   string       `mapstructure:"tls-key"`
+	TLSCert                  string       `mapstructure:"tls-cert"`
+	GoSmReleaseVersion       string       `mapstructure:"go-sm-release-version"`
+	GithubToken              string       `mapstructure:"github-token"`
+	KeepLogsMetrics          bool         `mapstructure:"keep-logs-metrics"`
+	ChaosMesh                bool         `mapstructure:"chaos-mesh"`
+	ChaosMeshVersion         string       `mapstructure:"chaos-mesh-version"`
+	UseVPC                   bool         `mapstructure:"use-vpc"`
+	VPC                      string       `mapstructure:"vpc"`
+	Private                  bool         `mapstructure:"private"`
+	PushGatewayURL           string       `mapstructure:"push-gateway-url"`
+	CoinbaseMnemonic         string       `mapstructure:"coinbase-mnemonic"`
+	CoinbaseKeystore         string       `mapstructure:"coinbase-keystore"`
+	KeystorePassword         string       `mapstructure:"keystore-password"`
+	KeysFile                 string       `mapstructure:"keys-file"`
+	MinerGroups              []MinerGroup `mapstructure:"miner-groups"`
+	MinerGroup               string       `mapstructure:"miner-group"`
+	AdversarialFraction      float64      `mapstructure:"adversarial-fraction"`
+	AdversarialImage         string       `mapstructure:"adversarial-image"`
+	AdversarialFlags         []string     `mapstructure:"adversarial-flags"`
+	ChaosFile                string       `mapstructure:"chaos-file"`
+	ScenarioFile             string       `mapstructure:"scenario-file"`
+	FromLayer                int          `mapstructure:"from-layer"`
+	ToLayer                  int          `mapstructure:"to-layer"`
+	RewardsFile              string       `mapstructure:"rewards-file"`
+	LoadgenRate              float64      `mapstructure:"loadgen-rate"`
+	LoadgenDuration          string       `mapstructure:"loadgen-duration"`
+	LoadgenPattern           string       `mapstructure:"loadgen-pattern"`
+	LoadgenAmount            uint64       `mapstructure:"loadgen-amount"`
+	LoadgenFee               uint64       `mapstructure:"loadgen-fee"`
+	DeployFaucet             bool         `mapstructure:"faucet"`
+	FaucetMiner              string       `mapstructure:"faucet-miner"`
+	FaucetImage              string       `mapstructure:"faucet-image"`
+	FaucetKey                string       `mapstructure:"faucet-key"`
+	FaucetAmount             uint64       `mapstructure:"faucet-amount"`
+	FaucetInterval           string       `mapstructure:"faucet-interval"`
+	FaucetPort               int          `mapstructure:"faucet-port"`
+	FaucetProxies            []string     `mapstructure:"faucet-trusted-proxies"`
+	FaucetState              string       `mapstructure:"faucet-state"`
+	PrometheusVersion        string       `mapstructure:"prometheus-version"`
+	PrometheusDiskSize       string       `mapstructure:"prometheus-disk-size"`
+	PrometheusPassword       string       `mapstructure:"prometheus-password"`
+	MetricsRetention         string       `mapstructure:"metrics-retention"`
+	GrafanaDashboards        string       `mapstructure:"grafana-dashboards"`
+	GrafanaPassword          string       `mapstructure:"grafana-password"`
+	AlertRules               string       `mapstructure:"alert-rules"`
+	AlertWebhookURL          string       `mapstructure:"alert-webhook-url"`
+	PagerDutyRoutingKey      string       `mapstructure:"pagerduty-routing-key"`
+	WatchFlags               []string     `mapstructure:"watch-flags"`
+	LogIndex                 string       `mapstructure:"log-index"`
+	LogMiners                []string     `mapstructure:"log-miner"`
+	LogLevel                 string       `mapstructure:"log-level"`
+	LogModule                string       `mapstructure:"log-module"`
+	LogQuery                 string       `mapstructure:"log-query"`
+	LogSince                 string       `mapstructure:"log-since"`
+	LogUntil                 string       `mapstructure:"log-until"`
+	LogLimit                 int          `mapstructure:"log-limit"`
+	LogFollow                bool         `mapstructure:"log-follow"`
+	LogExport                string       `mapstructure:"log-export"`
+	ArchiveLogs              bool         `mapstructure:"archive-logs"`
+	ArchiveCredentials       string       `mapstructure:"archive-credentials"`
+	LogsBucket               string       `mapstructure:"logs-bucket"`
+	ArchivedNetwork          string       `mapstructure:"archive"`
+	LogBackend               string       `mapstructure:"log-backend"`
+	LokiVersion              string       `mapstructure:"loki-version"`
+	LokiDiskSize             string       `mapstructure:"loki-disk-size"`
+	ESURL                    string       `mapstructure:"es-url"`
+	ESUsername               string       `mapstructure:"es-username"`
+	ESPassword               string       `mapstructure:"es-password"`
+	ESCA                     string       `mapstructure:"es-ca"`
+	KibanaURL                string       `mapstructure:"kibana-url"`
+	AllNetworks              bool         `mapstructure:"all-networks"`
+	DNSProvider              string       `mapstructure:"dns-provider"`
+	RootDomain               string       `mapstructure:"root-domain"`
+	DNSRecordTemplate        string       `mapstructure:"dns-record-template"`
+	DNSServer                string       `mapstructure:"dns-server"`
+	DNSHostsFile             string       `mapstructure:"dns-hosts-file"`
+	DNSTSIGKey               string       `mapstructure:"dns-tsig-key"`
+	DNSTSIGSecret            string       `mapstructure:"dns-tsig-secret"`
+	DNSTSIGAlgorithm         string       `mapstructure:"dns-tsig-algorithm"`
+	TLSIssuer                string       `mapstructure:"tls-issuer"`
+	ACMEEmail                string       `mapstructure:"acme-email"`
+	ACMEServer               string       `mapstructure:"acme-server"`
+	CertManagerVersion       string       `mapstructure:"cert-manager-version"`
+	PagerDutyToken           string       `mapstructure:"pagerduty-token"`
+	Charts                   ChartCatalog `mapstructure:"charts"`
+	DiscoveryFile            string       `mapstructure:"discovery-file"`
+	MinNodeVersion           string       `mapstructure:"min-node-version"`
+	MaxNodeVersion           string       `mapstructure:"max-node-version"`
+	DiscoveryFaucet          string       `mapstructure:"faucet-url"`
 }
 
 var Config = Configuration{
@@ -135,9 +214,88 @@ var Config = Configuration{
 	GithubToken:              "",
 	KeepLogsMetrics:          false,
 	ChaosMesh:                false,
-	ChaosMeshVersion:         "2.1.1",
+	ChaosMeshVersion:         "",
 	UseVPC:                   false,
 	VPC:                      "spacecraft",
 	Private:                  false,
 	PushGatewayURL:           "https://public-metrics.spacemesh.dev/",
+	CoinbaseMnemonic:         "",
+	CoinbaseKeystore:         "",
+	KeystorePassword:         "",
+	KeysFile:                 "./keys.json",
+	MinerGroups:              []MinerGroup{},
+	MinerGroup:               "",
+	AdversarialFraction:      0,
+	AdversarialImage:         "",
+	AdversarialFlags:         []string{},
+	ChaosFile:                "",
+	ScenarioFile:             "",
+	FromLayer:                0,
+	ToLayer:                  0,
+	RewardsFile:              "",
+	LoadgenRate:              1,
+	LoadgenDuration:          "5m",
+	LoadgenPattern:           "constant",
+	LoadgenAmount:            1,
+	LoadgenFee:               1,
+	DeployFaucet:             false,
+	FaucetMiner:              "1",
+	FaucetImage:              "spacemeshos/spacecraft:latest",
+	FaucetKey:                "",
+	FaucetAmount:             100,
+	FaucetInterval:           "24h",
+	FaucetPort:               8080,
+	FaucetProxies:            []string{"ingress-nginx-controller-pods.kube-system.svc.cluster.local"},
+	FaucetState:              "",
+	PrometheusVersion:        "",
+	PrometheusDiskSize:       "10",
+	PrometheusPassword:       "",
+	MetricsRetention:         "15d",
+	GrafanaDashboards:        "./artifacts/metrics/dashboards",
+	GrafanaPassword:          "",
+	AlertRules:               "./artifacts/metrics/rules",
+	AlertWebhookURL:          "",
+	PagerDutyRoutingKey:      "",
+	WatchFlags:               []string{},
+	LogIndex:                 "sm-*",
+	LogMiners:                []string{},
+	LogLevel:                 "",
+	LogModule:                "",
+	LogQuery:                 "",
+	LogSince:                 "",
+	LogUntil:                 "",
+	LogLimit:                 100,
+	LogFollow:                false,
+	LogExport:                "",
+	ArchiveLogs:              true,
+	ArchiveCredentials:       "",
+	LogsBucket:               "spacecraft-data",
+	ArchivedNetwork:          "",
+	LogBackend:               "elk",
+	LokiVersion:              "",
+	LokiDiskSize:             "10",
+	ESURL:                    "",
+	ESUsername:               "elastic",
+	ESPassword:               "",
+	ESCA:                     "",
+	KibanaURL:                "",
+	AllNetworks:              false,
+	DNSProvider:              "cloudflare",
+	RootDomain:               "spacemesh.io",
+	DNSRecordTemplate:        "{service}-{network}",
+	DNSServer:                "",
+	DNSHostsFile:             "",
+	DNSTSIGKey:               "",
+	DNSTSIGSecret:            "",
+	DNSTSIGAlgorithm:         "hmac-sha256",
+	TLSIssuer:                "files",
+	ACMEEmail:                "",
+	ACMEServer:               "https://acme-v02.api.letsencrypt.org/directory",
+	CertManagerVersion:       "",
+	PagerDutyToken:           "",
+	Charts:                   ChartCatalog{},
+	DiscoveryFile:            "",
+	MinNodeVersion:           "",
+	MaxNodeVersion:           "",
+	DiscoveryFaucet:          "",
 }
@@ -0,0 +1,202 @@
+package config
+
+import (
+	"fmt"
+	"math"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/util/validation"
+)
+
+const DefaultMinerGroup = "default"
+const AdversarialMinerGroup = "adversarial"
+
+const HonestCohort = "honest"
+const AdversarialCohort = "adversarial"
+
+// MinerGroup overrides the miner settings for Count miners. Empty fields
+// fall back to the network wide settings.
+type MinerGroup struct {
+	Name        string   `mapstructure:"name"`
+	Count       int      `mapstructure:"count"`
+	Image       string   `mapstructure:"image"`
+	CPU         string   `mapstructure:"cpu"`
+	Memory      string   `mapstructure:"ram"`
+	DiskSize    string   `mapstructure:"disk-size"`
+	Config      string   `mapstructure:"config"`
+	Flags       []string `mapstructure:"flags"`
+	Profile     bool     `mapstructure:"profile"`
+	Adversarial bool     `mapstructure:"adversarial"`
+}
+
+func (g MinerGroup) Cohort() string {
+	if g.Adversarial {
+		return AdversarialCohort
+	}
+
+	return HonestCohort
+}
+
+func (c *Configuration) withDefaults(group MinerGroup) MinerGroup {
+	if group.Image == "" {
+		group.Image = c.GoSmImage
+	}
+
+	if group.CPU == "" {
+		group.CPU = c.MinerCPU
+	}
+
+	if group.Memory == "" {
+		group.Memory = c.MinerMemory
+	}
+
+	if group.DiskSize == "" {
+		group.DiskSize = c.MinerDiskSize
+	}
+
+	return group
+}
+
+// DefaultGroup is used for miners which are not part of any configured group
+func (c *Configuration) DefaultGroup() MinerGroup {
+	return c.withDefaults(MinerGroup{Name: DefaultMinerGroup})
+}
+
+// ValidateMinerGroups checks that every group has a unique name which can be
+// used as a label value and at least one miner. The names of the default and
+// adversarial groups are reserved.
+func (c *Configuration) ValidateMinerGroups() error {
+	names := map[string]bool{}
+
+	for i, group := range c.MinerGroups {
+		if group.Name == "" {
+			return fmt.Errorf("miner group %d has no name", i+1)
+		}
+
+		if errs := validation.IsValidLabelValue(group.Name); len(errs) > 0 {
+			return fmt.Errorf("miner group %s: invalid name: %s", group.Name, strings.Join(errs, ", "))
+		}
+
+		if group.Name == DefaultMinerGroup || group.Name == AdversarialMinerGroup {
+			return fmt.Errorf("miner group %s: the name is reserved", group.Name)
+		}
+
+		if names[group.Name] {
+			return fmt.Errorf("miner group %s is defined more than once", group.Name)
+		}
+
+		names[group.Name] = true
+
+		if group.Count <= 0 {
+			return fmt.Errorf("miner group %s: count must be greater than 0", group.Name)
+		}
+	}
+
+	return nil
+}
+
+// GroupedMiners returns the number of miners that belong to configured groups
+func (c *Configuration) GroupedMiners() int {
+	total := 0
+
+	for _, group := range c.MinerGroups {
+		total += group.Count
+	}
+
+	return total
+}
+
+// MinerGroupOf assigns miners to groups in the order the groups are configured,
+// i.e., the first group gets miners 1 to Count, the next group the following
+// miners and so on. Remaining miners belong to the default group.
+func (c *Configuration) MinerGroupOf(minerNumber int) MinerGroup {
+	end := 0
+
+	for _, group := range c.MinerGroups {
+		end += group.Count
+
+		if minerNumber <= end {
+			return c.withDefaults(group)
+		}
+	}
+
+	return c.DefaultGroup()
+}
+
+func (c *Configuration) GetMinerGroup(name string) (MinerGroup, bool) {
+	for _, group := range c.MinerGroups {
+		if group.Name == name {
+			return c.withDefaults(group), true
+		}
+	}
+
+	if name == DefaultMinerGroup {
+		return c.DefaultGroup(), true
+	}
+
+	if name == AdversarialMinerGroup {
+		return c.AdversarialGroup(), true
+	}
+
+	return MinerGroup{}, false
+}
+
+// AdversarialGroup is the group of miners deployed using the adversarial-* settings
+func (c *Configuration) AdversarialGroup() MinerGroup {
+	image := c.AdversarialImage
+
+	if image == "" {
+		image = c.GoSmImage
+	}
+
+	return c.withDefaults(MinerGroup{
+		Name:        AdversarialMinerGroup,
+		Image:       image,
+		Flags:       c.AdversarialFlags,
+		Adversarial: true,
+	})
+}
+
+// AddAdversarialCohort appends the adversarial group for AdversarialFraction
+// of the miners. The adversarial miners are taken from the default group and
+// are always the last miners so that the bootstrap node and bootnodes stay
+// honest.
+func (c *Configuration) AddAdversarialCohort() error {
+	count := int(math.Round(c.AdversarialFraction * float64(c.NumberOfMiners)))
+
+	if count > 0 {
+		remaining := c.NumberOfMiners - c.GroupedMiners()
+
+		if remaining < count {
+			return fmt.Errorf("%d adversarial miners are needed but only %d miners aren't in a group", count, remaining)
+		}
+
+		if remaining > count {
+			c.MinerGroups = append(c.MinerGroups, MinerGroup{Name: DefaultMinerGroup, Count: remaining - count})
+		}
+
+		adversarialGroup := c.AdversarialGroup()
+		adversarialGroup.Count = count
+
+		c.MinerGroups = append(c.MinerGroups, adversarialGroup)
+	}
+
+	// the bootstrap node is miner 1 and the bootnodes are the next miners
+	lastBootnode := c.BootnodeAmount
+
+	if c.Bootstrap {
+		lastBootnode++
+	}
+
+	first := 1
+
+	for _, group := range c.MinerGroups {
+		if group.Adversarial && group.Count > 0 && first <= lastBootnode {
+			return fmt.Errorf("adversarial group %s starts at miner %d but miners 1 to %d are the bootstrap node and bootnodes", group.Name, first, lastBootnode)
+		}
+
+		first += group.Count
+	}
+
+	return nil
+}
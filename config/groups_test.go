@@ -0,0 +1,165 @@
+package config
+
+import (
+	"testing"
+)
+
+func TestMinerGroupOf(t *testing.T) {
+	c := Configuration{
+		GoSmImage:     "spacemeshos/go-spacemesh:v0.2",
+		MinerCPU:      "2",
+		MinerMemory:   "4",
+		MinerDiskSize: "20",
+		MinerGroups: []MinerGroup{
+			{Name: "a", Count: 2, Image: "spacemeshos/go-spacemesh:a"},
+			{Name: "b", Count: 3, CPU: "4"},
+		},
+	}
+
+	tests := []struct {
+		minerNumber int
+		group       string
+		image       string
+		cpu         string
+	}{
+		{1, "a", "spacemeshos/go-spacemesh:a", "2"},
+		{2, "a", "spacemeshos/go-spacemesh:a", "2"},
+		{3, "b", "spacemeshos/go-spacemesh:v0.2", "4"},
+		{5, "b", "spacemeshos/go-spacemesh:v0.2", "4"},
+		{6, DefaultMinerGroup, "spacemeshos/go-spacemesh:v0.2", "2"},
+		{100, DefaultMinerGroup, "spacemeshos/go-spacemesh:v0.2", "2"},
+	}
+
+	for _, test := range tests {
+		group := c.MinerGroupOf(test.minerNumber)
+
+		if group.Name != test.group || group.Image != test.image || group.CPU != test.cpu {
+			t.Errorf("miner %d: got group %s with image %s and cpu %s, expected group %s with image %s and cpu %s",
+				test.minerNumber, group.Name, group.Image, group.CPU, test.group, test.image, test.cpu)
+		}
+
+		if group.Memory != "4" || group.DiskSize != "20" {
+			t.Errorf("miner %d: network wide ram and disk size were not applied", test.minerNumber)
+		}
+	}
+}
+
+func TestValidateMinerGroups(t *testing.T) {
+	tests := []struct {
+		name   string
+		groups []MinerGroup
+		valid  bool
+	}{
+		{"no groups", nil, true},
+		{"valid groups", []MinerGroup{{Name: "a", Count: 1}, {Name: "canary-1.2", Count: 5}}, true},
+		{"empty name", []MinerGroup{{Name: "", Count: 1}}, false},
+		{"invalid label value", []MinerGroup{{Name: "a b", Count: 1}}, false},
+		{"name too long", []MinerGroup{{Name: "a123456789012345678901234567890123456789012345678901234567890123", Count: 1}}, false},
+		{"duplicate name", []MinerGroup{{Name: "a", Count: 1}, {Name: "a", Count: 2}}, false},
+		{"default group name", []MinerGroup{{Name: DefaultMinerGroup, Count: 1}}, false},
+		{"adversarial group name", []MinerGroup{{Name: AdversarialMinerGroup, Count: 1}}, false},
+		{"zero count", []MinerGroup{{Name: "a", Count: 0}}, false},
+		{"negative count", []MinerGroup{{Name: "a", Count: -1}}, false},
+	}
+
+	for _, test := range tests {
+		c := Configuration{MinerGroups: test.groups}
+
+		if err := c.ValidateMinerGroups(); (err == nil) != test.valid {
+			t.Errorf("%s: ValidateMinerGroups() = %v, expected valid %v", test.name, err, test.valid)
+		}
+	}
+}
+
+func TestAddAdversarialCohort(t *testing.T) {
+	tests := []struct {
+		name      string
+		miners    int
+		fraction  float64
+		bootstrap bool
+		bootnodes int
+		groups    []MinerGroup
+		expected  []MinerGroup
+		valid     bool
+	}{
+		{
+			name: "no adversarial miners", miners: 10, bootstrap: true, bootnodes: 2,
+			expected: nil, valid: true,
+		},
+		{
+			name: "taken from the default group", miners: 10, fraction: 0.2, bootstrap: true, bootnodes: 2,
+			expected: []MinerGroup{{Name: DefaultMinerGroup, Count: 8}, {Name: AdversarialMinerGroup, Count: 2}}, valid: true,
+		},
+		{
+			name: "after the configured groups", miners: 10, fraction: 0.2, bootstrap: true, bootnodes: 2,
+			groups:   []MinerGroup{{Name: "a", Count: 5}},
+			expected: []MinerGroup{{Name: "a", Count: 5}, {Name: DefaultMinerGroup, Count: 3}, {Name: AdversarialMinerGroup, Count: 2}}, valid: true,
+		},
+		{
+			name: "whole default group", miners: 10, fraction: 0.2, bootstrap: true, bootnodes: 2,
+			groups:   []MinerGroup{{Name: "a", Count: 8}},
+			expected: []MinerGroup{{Name: "a", Count: 8}, {Name: AdversarialMinerGroup, Count: 2}}, valid: true,
+		},
+		{
+			name: "groups cover all miners", miners: 10, fraction: 0.2, bootstrap: true, bootnodes: 2,
+			groups: []MinerGroup{{Name: "a", Count: 10}},
+		},
+		{
+			name: "overlaps the bootnodes", miners: 10, fraction: 0.5, bootstrap: true, bootnodes: 6,
+		},
+		{
+			name: "after the bootnodes without bootstrap node", miners: 10, fraction: 0.5, bootnodes: 5,
+			expected: []MinerGroup{{Name: DefaultMinerGroup, Count: 5}, {Name: AdversarialMinerGroup, Count: 5}}, valid: true,
+		},
+		{
+			name: "overlaps the bootstrap node", miners: 10, bootstrap: true,
+			groups: []MinerGroup{{Name: "a", Count: 1, Adversarial: true}},
+		},
+	}
+
+	for _, test := range tests {
+		c := Configuration{
+			NumberOfMiners:      test.miners,
+			AdversarialFraction: test.fraction,
+			Bootstrap:           test.bootstrap,
+			BootnodeAmount:      test.bootnodes,
+			MinerGroups:         test.groups,
+		}
+
+		err := c.AddAdversarialCohort()
+
+		if !test.valid {
+			if err == nil {
+				t.Errorf("%s: adversarial cohort was added", test.name)
+			}
+
+			continue
+		}
+
+		if err != nil {
+			t.Errorf("%s: %v", test.name, err)
+			continue
+		}
+
+		if c.NumberOfMiners != test.miners {
+			t.Errorf("%s: number of miners changed to %d", test.name, c.NumberOfMiners)
+		}
+
+		if len(c.MinerGroups) != len(test.expected) {
+			t.Errorf("%s: got %d groups, expected %d", test.name, len(c.MinerGroups), len(test.expected))
+			continue
+		}
+
+		for i, group := range c.MinerGroups {
+			expected := test.expected[i]
+
+			if group.Name != expected.Name || group.Count != expected.Count {
+				t.Errorf("%s: group %d is %s with %d miners, expected %s with %d miners", test.name, i+1, group.Name, group.Count, expected.Name, expected.Count)
+			}
+
+			if group.Adversarial != (group.Name == AdversarialMinerGroup) {
+				t.Errorf("%s: group %s has adversarial %v", test.name, group.Name, group.Adversarial)
+			}
+		}
+	}
+}
@@ -0,0 +1,43 @@
+package config
+
+import (
+	"fmt"
+
+	"github.com/spacemeshos/go-spacecraft/secrets"
+)
+
+// secretFields are the fields which may hold a secret reference, e.g.,
+// env:SLACK_TOKEN or vault:secret/data/spacecraft#slack-token
+func (c *Configuration) secretFields() map[string]*string {
+	return map[string]*string{
+		"slack-token":           &c.SlackToken,
+		"cloudflare-api-token":  &c.CloudflareAPIToken,
+		"github-token":          &c.GithubToken,
+		"coinbase-mnemonic":     &c.CoinbaseMnemonic,
+		"keystore-password":     &c.KeystorePassword,
+		"faucet-key":            &c.FaucetKey,
+		"alert-webhook-url":     &c.AlertWebhookURL,
+		"pagerduty-routing-key": &c.PagerDutyRoutingKey,
+		"pagerduty-token":       &c.PagerDutyToken,
+		"es-password":           &c.ESPassword,
+		"prometheus-password":   &c.PrometheusPassword,
+		"grafana-password":      &c.GrafanaPassword,
+		"dns-tsig-secret":       &c.DNSTSIGSecret,
+	}
+}
+
+// ResolveSecrets replaces the secret references with their values and
+// registers the values to be redacted from the output
+func (c *Configuration) ResolveSecrets() error {
+	for name, field := range c.secretFields() {
+		value, err := secrets.Resolve(*field)
+
+		if err != nil {
+			return fmt.Errorf("%s: %w", name, err)
+		}
+
+		*field = value
+	}
+
+	return nil
+}
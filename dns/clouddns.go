@@ -0,0 +1,77 @@
+package dns
+
+import (
+	"context"
+	"errors"
+
+	clouddns "google.golang.org/api/dns/v1"
+)
+
+// cloudDNSProvider manages the records in the managed zone of the root
+// domain in the GCP project
+type cloudDNSProvider struct {
+	service *clouddns.Service
+	zone    string
+}
+
+func newCloudDNSProvider() (*cloudDNSProvider, error) {
+	service, err := clouddns.NewService(context.Background())
+
+	if err != nil {
+		return nil, err
+	}
+
+	zones, err := service.ManagedZones.List(config.GCPProject).DnsName(config.RootDomain + ".").Do()
+
+	if err != nil {
+		return nil, err
+	}
+
+	if len(zones.ManagedZones) == 0 {
+		return nil, errors.New("cloud DNS managed zone " + config.RootDomain + " not found")
+	}
+
+	return &cloudDNSProvider{service: service, zone: zones.ManagedZones[0].Name}, nil
+}
+
+func (p *cloudDNSProvider) CreateRecord(record Record) error {
+	change := &clouddns.Change{
+		Additions: []*clouddns.ResourceRecordSet{{
+			Name:    record.Name + ".",
+			Type:    "A",
+			Ttl:     recordTTL,
+			Rrdatas: []string{record.IP},
+		}},
+	}
+
+	// replace the existing record, cloud DNS doesn't allow two record sets with the same name
+	existing, err := p.service.ResourceRecordSets.List(config.GCPProject, p.zone).Name(record.Name + ".").Type("A").Do()
+
+	if err != nil {
+		return err
+	}
+
+	change.Deletions = existing.Rrsets
+
+	_, err = p.service.Changes.Create(config.GCPProject, p.zone, change).Do()
+
+	return err
+}
+
+func (p *cloudDNSProvider) DeleteRecord(name string) error {
+	existing, err := p.service.ResourceRecordSets.List(config.GCPProject, p.zone).Name(name + ".").Type("A").Do()
+
+	if err != nil {
+		return err
+	}
+
+	if len(existing.Rrsets) == 0 {
+		return nil
+	}
+
+	_, err = p.service.Changes.Create(config.GCPProject, p.zone, &clouddns.Change{
+		Deletions: existing.Rrsets,
+	}).Do()
+
+	return err
+}
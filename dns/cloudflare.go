@@ -0,0 +1,61 @@
+package dns
+
+import (
+	"context"
+
+	cloudflare "github.com/cloudflare/cloudflare-go"
+)
+
+type cloudflareProvider struct {
+	api    *cloudflare.API
+	zoneID string
+}
+
+func newCloudflareProvider() (*cloudflareProvider, error) {
+	api, err := cloudflare.NewWithAPIToken(config.CloudflareAPIToken)
+
+	if err != nil {
+		return nil, err
+	}
+
+	id, err := api.ZoneIDByName(config.RootDomain)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &cloudflareProvider{api: api, zoneID: id}, nil
+}
+
+func (p *cloudflareProvider) CreateRecord(record Record) error {
+	proxied := record.Proxied
+
+	_, err := p.api.CreateDNSRecord(context.Background(), p.zoneID, cloudflare.DNSRecord{
+		Type:    "A",
+		Name:    record.Name,
+		Content: record.IP,
+		Proxied: &proxied,
+	})
+
+	return err
+}
+
+func (p *cloudflareProvider) DeleteRecord(name string) error {
+	records, err := p.api.DNSRecords(context.Background(), p.zoneID, cloudflare.DNSRecord{
+		Name: name,
+	})
+
+	if err != nil {
+		return err
+	}
+
+	for _, record := range records {
+		err = p.api.DeleteDNSRecord(context.Background(), p.zoneID, record.ID)
+
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,67 @@
+package dns
+
+import (
+	"errors"
+	"io/ioutil"
+	"os"
+	"strings"
+)
+
+// corednsProvider writes the records to a hosts file served by the hosts
+// plugin of CoreDNS, which reloads the file when it changes. CoreDNS doesn't
+// accept RFC2136 updates. It is meant for testing without access to a public
+// DNS provider.
+type corednsProvider struct {
+	path string
+}
+
+func newCoreDNSProvider() (*corednsProvider, error) {
+	if config.DNSHostsFile == "" {
+		return nil, errors.New("coredns DNS provider needs --dns-hosts-file")
+	}
+
+	return &corednsProvider{path: config.DNSHostsFile}, nil
+}
+
+// update rewrites the hosts file without the lines of name, and with line if
+// it isn't empty. The file is replaced atomically so that CoreDNS never reads
+// a partial file.
+func (p *corednsProvider) update(name string, line string) error {
+	data, err := ioutil.ReadFile(p.path)
+
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+
+	lines := []string{}
+
+	for _, existing := range strings.Split(string(data), "\n") {
+		fields := strings.Fields(existing)
+
+		if len(fields) == 0 || (len(fields) == 2 && fields[1] == name) {
+			continue
+		}
+
+		lines = append(lines, existing)
+	}
+
+	if line != "" {
+		lines = append(lines, line)
+	}
+
+	tmpPath := p.path + ".tmp"
+
+	if err = ioutil.WriteFile(tmpPath, []byte(strings.Join(lines, "\n")+"\n"), 0644); err != nil {
+		return err
+	}
+
+	return os.Rename(tmpPath, p.path)
+}
+
+func (p *corednsProvider) CreateRecord(record Record) error {
+	return p.update(record.Name, record.IP+" "+record.Name)
+}
+
+func (p *corednsProvider) DeleteRecord(name string) error {
+	return p.update(name, "")
+}
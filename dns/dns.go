@@ -0,0 +1,91 @@
+package dns
+
+import (
+	"errors"
+	"strings"
+
+	cfg "github.com/spacemeshos/go-spacecraft/config"
+)
+
+var config = &cfg.Config
+
+// recordTTL is used by the providers which don't proxy the records
+const recordTTL = 300
+
+// Record is an A record pointing to an ingress of a network
+type Record struct {
+	Name string
+	IP   string
+	// Proxied records are served through the cloudflare proxy, other
+	// providers ignore it
+	Proxied bool
+}
+
+// Provider creates and deletes the DNS records of networks. Deleting a record
+// which doesn't exist is not an error.
+type Provider interface {
+	CreateRecord(record Record) error
+	DeleteRecord(name string) error
+}
+
+// NewProvider returns the configured DNS provider. Without an API token the
+// cloudflare provider doesn't manage any records.
+func NewProvider() (Provider, error) {
+	switch config.DNSProvider {
+	case "cloudflare":
+		if !Enabled() {
+			return noneProvider{}, nil
+		}
+
+		return newCloudflareProvider()
+	case "route53":
+		return newRoute53Provider()
+	case "clouddns":
+		return newCloudDNSProvider()
+	case "rfc2136":
+		return newRFC2136Provider()
+	case "coredns":
+		return newCoreDNSProvider()
+	case "none":
+		return noneProvider{}, nil
+	}
+
+	return nil, errors.New("unknown DNS provider " + config.DNSProvider + ", use cloudflare, route53, clouddns, rfc2136, coredns or none")
+}
+
+// Enabled is false if no records are managed
+func Enabled() bool {
+	return config.DNSProvider != "none" && !(config.DNSProvider == "cloudflare" && config.CloudflareAPIToken == "")
+}
+
+// Domain returns the domain of a service of a network using the record
+// template, e.g., api-json-devnet-201.spacemesh.io
+func Domain(service string, networkName string) string {
+	name := strings.NewReplacer("{service}", service, "{network}", networkName).Replace(config.DNSRecordTemplate)
+
+	return name + "." + config.RootDomain
+}
+
+// Settings are the options which decide the provider and the names of the
+// records of a network by option name. The credentials aren't included.
+func Settings() map[string]*string {
+	return map[string]*string{
+		"dns-provider":        &config.DNSProvider,
+		"root-domain":         &config.RootDomain,
+		"dns-record-template": &config.DNSRecordTemplate,
+		"dns-server":          &config.DNSServer,
+		"dns-hosts-file":      &config.DNSHostsFile,
+		"dns-tsig-key":        &config.DNSTSIGKey,
+		"dns-tsig-algorithm":  &config.DNSTSIGAlgorithm,
+	}
+}
+
+type noneProvider struct{}
+
+func (noneProvider) CreateRecord(record Record) error {
+	return nil
+}
+
+func (noneProvider) DeleteRecord(name string) error {
+	return nil
+}
@@ -0,0 +1,68 @@
+package dns
+
+import (
+	"errors"
+	"net"
+	"time"
+
+	miekgdns "github.com/miekg/dns"
+)
+
+// rfc2136Provider sends dynamic updates to a DNS server like bind or knot,
+// signed with TSIG if a key is configured. It is meant for testing without
+// access to a public DNS provider.
+type rfc2136Provider struct {
+	zone string
+}
+
+func newRFC2136Provider() (*rfc2136Provider, error) {
+	if config.DNSServer == "" {
+		return nil, errors.New("rfc2136 DNS provider needs --dns-server")
+	}
+
+	return &rfc2136Provider{zone: miekgdns.Fqdn(config.RootDomain)}, nil
+}
+
+func (p *rfc2136Provider) send(msg *miekgdns.Msg) error {
+	client := &miekgdns.Client{Timeout: 10 * time.Second}
+
+	if config.DNSTSIGKey != "" {
+		keyName := miekgdns.Fqdn(config.DNSTSIGKey)
+		client.TsigSecret = map[string]string{keyName: config.DNSTSIGSecret}
+		msg.SetTsig(keyName, miekgdns.Fqdn(config.DNSTSIGAlgorithm), 300, time.Now().Unix())
+	}
+
+	response, _, err := client.Exchange(msg, config.DNSServer)
+
+	if err != nil {
+		return err
+	}
+
+	if response.Rcode != miekgdns.RcodeSuccess {
+		return errors.New("DNS update failed: " + miekgdns.RcodeToString[response.Rcode])
+	}
+
+	return nil
+}
+
+func (p *rfc2136Provider) CreateRecord(record Record) error {
+	name := miekgdns.Fqdn(record.Name)
+
+	msg := &miekgdns.Msg{}
+	msg.SetUpdate(p.zone)
+	msg.RemoveRRset([]miekgdns.RR{&miekgdns.A{Hdr: miekgdns.RR_Header{Name: name, Rrtype: miekgdns.TypeA}}})
+	msg.Insert([]miekgdns.RR{&miekgdns.A{
+		Hdr: miekgdns.RR_Header{Name: name, Rrtype: miekgdns.TypeA, Class: miekgdns.ClassINET, Ttl: recordTTL},
+		A:   net.ParseIP(record.IP),
+	}})
+
+	return p.send(msg)
+}
+
+func (p *rfc2136Provider) DeleteRecord(name string) error {
+	msg := &miekgdns.Msg{}
+	msg.SetUpdate(p.zone)
+	msg.RemoveRRset([]miekgdns.RR{&miekgdns.A{Hdr: miekgdns.RR_Header{Name: miekgdns.Fqdn(name), Rrtype: miekgdns.TypeA}}})
+
+	return p.send(msg)
+}
@@ -0,0 +1,86 @@
+package dns
+
+import (
+	"errors"
+	"strings"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/route53"
+)
+
+// route53Provider uses the AWS credentials of the environment
+type route53Provider struct {
+	client *route53.Route53
+	zoneID *string
+}
+
+func newRoute53Provider() (*route53Provider, error) {
+	sess, err := session.NewSession()
+
+	if err != nil {
+		return nil, err
+	}
+
+	client := route53.New(sess)
+
+	zones, err := client.ListHostedZonesByName(&route53.ListHostedZonesByNameInput{
+		DNSName: aws.String(config.RootDomain),
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	for _, zone := range zones.HostedZones {
+		if strings.TrimSuffix(aws.StringValue(zone.Name), ".") == config.RootDomain {
+			return &route53Provider{client: client, zoneID: zone.Id}, nil
+		}
+	}
+
+	return nil, errors.New("route53 hosted zone " + config.RootDomain + " not found")
+}
+
+func (p *route53Provider) change(action string, recordSet *route53.ResourceRecordSet) error {
+	_, err := p.client.ChangeResourceRecordSets(&route53.ChangeResourceRecordSetsInput{
+		HostedZoneId: p.zoneID,
+		ChangeBatch: &route53.ChangeBatch{
+			Changes: []*route53.Change{{
+				Action:            aws.String(action),
+				ResourceRecordSet: recordSet,
+			}},
+		},
+	})
+
+	return err
+}
+
+func (p *route53Provider) CreateRecord(record Record) error {
+	return p.change(route53.ChangeActionUpsert, &route53.ResourceRecordSet{
+		Name:            aws.String(record.Name),
+		Type:            aws.String(route53.RRTypeA),
+		TTL:             aws.Int64(recordTTL),
+		ResourceRecords: []*route53.ResourceRecord{{Value: aws.String(record.IP)}},
+	})
+}
+
+func (p *route53Provider) DeleteRecord(name string) error {
+	recordSets, err := p.client.ListResourceRecordSets(&route53.ListResourceRecordSetsInput{
+		HostedZoneId:    p.zoneID,
+		StartRecordName: aws.String(name),
+		StartRecordType: aws.String(route53.RRTypeA),
+		MaxItems:        aws.String("1"),
+	})
+
+	if err != nil {
+		return err
+	}
+
+	for _, recordSet := range recordSets.ResourceRecordSets {
+		if strings.TrimSuffix(aws.StringValue(recordSet.Name), ".") == name && aws.StringValue(recordSet.Type) == route53.RRTypeA {
+			return p.change(route53.ChangeActionDelete, recordSet)
+		}
+	}
+
+	return nil
+}
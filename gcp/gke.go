@@ -34,7 +34,7 @@ func getClient() (*container.ClusterManagerClient, error) {
 	c, err := container.NewClusterManagerClient(ctx)
 
 	if err != nil {
-		return nil, fmt.Errorf("could not authorize gcp", err)
+		return nil, fmt.Errorf("could not authorize gcp: %w", err)
 	}
 
 	return c, nil
@@ -99,22 +99,30 @@ func CreateKubernetesCluster() error {
 		}
 	}
 
-	minerCPUInt, _ := strconv.ParseInt(config.MinerCPU, 10, 8)
+	minersCPU, minersMemory := int64(0), int64(0)
+
+	for i := 1; i <= config.NumberOfMiners; i++ {
+		group := config.MinerGroupOf(i)
+		minerCPUInt, _ := strconv.ParseInt(group.CPU, 10, 8)
+		minerMemoryInt, _ := strconv.ParseInt(group.Memory, 10, 8)
+		minersCPU += minerCPUInt
+		minersMemory += minerMemoryInt
+	}
+
 	poetCPUInt, _ := strconv.ParseInt(config.PoetCPU, 10, 8)
 	esCPUInt, _ := strconv.ParseInt(config.ESCPU, 10, 8)
 	kibanaCPUInt, _ := strconv.ParseInt(config.KibanaCPU, 10, 8)
 	pyroscopeCPUInt, _ := strconv.ParseInt(config.PyroscopeCPU, 10, 8)
-	totalCPURequired := (minerCPUInt * int64(config.NumberOfMiners)) + (poetCPUInt * int64(config.NumberOfPoets)) + kibanaCPUInt + esCPUInt + pyroscopeCPUInt
+	totalCPURequired := minersCPU + (poetCPUInt * int64(config.NumberOfPoets)) + kibanaCPUInt + esCPUInt + pyroscopeCPUInt
 	totalCPUInstanceHas := int64(config.GCPMachineCPU)
 
 	nodeCount1 := int(math.Ceil((float64(totalCPURequired) / float64(totalCPUInstanceHas))))
 
-	minerMemoryInt, _ := strconv.ParseInt(config.MinerMemory, 10, 8)
 	poetMemoryInt, _ := strconv.ParseInt(config.PoetMemory, 10, 8)
 	esMemoryInt, _ := strconv.ParseInt(config.ESMemory, 10, 8)
 	kibanaMemoryInt, _ := strconv.ParseInt(config.KibanaMemory, 10, 8)
 	pyroscopeMemoryInt, _ := strconv.ParseInt(config.PyroscopeMemory, 10, 8)
-	totalMemoryRequired := (minerMemoryInt * int64(config.NumberOfMiners)) + (poetMemoryInt * int64(config.NumberOfPoets)) + kibanaMemoryInt + esMemoryInt + pyroscopeMemoryInt
+	totalMemoryRequired := minersMemory + (poetMemoryInt * int64(config.NumberOfPoets)) + kibanaMemoryInt + esMemoryInt + pyroscopeMemoryInt
 	totalMemoryInstanceHas := int64(config.GCPMachineMemory)
 
 	nodeCount2 := int(math.Ceil((float64(totalMemoryRequired) / float64(totalMemoryInstanceHas))))
@@ -202,7 +210,7 @@ func CreateKubernetesCluster() error {
 		} else if cluster.Status == containerpb.Cluster_RUNNING {
 			break
 		} else if cluster.Status == containerpb.Cluster_STOPPING || cluster.Status == containerpb.Cluster_ERROR || cluster.Status == containerpb.Cluster_DEGRADED {
-			return fmt.Errorf("an unknown occured while k8s cluster was being created. status: ", cluster.Status)
+			return fmt.Errorf("an unknown occured while k8s cluster was being created. status: %v", cluster.Status)
 		}
 	}
 
@@ -2,11 +2,14 @@ package gcp
 
 import (
 	"context"
+	"errors"
 	"io/ioutil"
+	"net/http"
 	"os"
 	"time"
 
 	"cloud.google.com/go/storage"
+	"google.golang.org/api/googleapi"
 )
 
 func UploadConfig(fileContent string) error {
@@ -56,37 +59,52 @@ func ReadConfig(networkName string) (string, error) {
 	return string(body), nil
 }
 
-func ReadWSConfig() (string, error) {
+// ErrDiscoveryChanged is returned by UploadWSConfig if the discovery file
+// was changed after it was read
+var ErrDiscoveryChanged = errors.New("discovery file was changed by someone else")
+
+func discoveryObject(client *storage.Client) *storage.ObjectHandle {
+	discoverFileName := "networks.json"
+
+	if config.Private {
+		discoverFileName = "networks.private.json"
+	}
+
+	return client.Bucket("sm-discovery-service").Object(discoverFileName)
+}
+
+// ReadWSConfig returns the discovery file and its generation. The generation
+// is 0 if the file doesn't exist.
+func ReadWSConfig() (string, int64, error) {
 	ctx := context.Background()
 	client, err := storage.NewClient(ctx)
 
 	if err != nil {
-		return "", err
+		return "", 0, err
 	}
 
 	defer client.Close()
 
-	discoverFileName := "networks.json"
-
-	if config.Private {
-		discoverFileName = "networks.private.json"
+	rc, err := discoveryObject(client).NewReader(ctx)
+	if err == storage.ErrObjectNotExist {
+		return "[]", 0, nil
 	}
-
-	rc, err := client.Bucket("sm-discovery-service").Object(discoverFileName).NewReader(ctx)
 	if err != nil {
-		return "", err
+		return "", 0, err
 	}
 	defer rc.Close()
 
 	body, err := ioutil.ReadAll(rc)
 	if err != nil {
-		return "", err
+		return "", 0, err
 	}
 
-	return string(body), nil
+	return string(body), rc.Attrs.Generation, nil
 }
 
-func UploadWSConfig(fileContent string) error {
+// UploadWSConfig replaces the discovery file if its generation is still the
+// one returned by ReadWSConfig, otherwise ErrDiscoveryChanged is returned
+func UploadWSConfig(fileContent string, generation int64) error {
 	ctx := context.Background()
 	client, err := storage.NewClient(ctx)
 
@@ -99,28 +117,28 @@ func UploadWSConfig(fileContent string) error {
 	ctx, cancel := context.WithTimeout(ctx, time.Second*10)
 	defer cancel()
 
-	discoverFileName := "networks.json"
-
-	if config.Private {
-		discoverFileName = "networks.private.json"
-	}
+	conditions := storage.Conditions{GenerationMatch: generation}
 
-	o := client.Bucket("sm-discovery-service").Object(discoverFileName)
-	if err := o.Delete(ctx); err != nil {
-		return err
+	if generation == 0 {
+		conditions = storage.Conditions{DoesNotExist: true}
 	}
 
-	wc := client.Bucket("sm-discovery-service").Object(discoverFileName).NewWriter(ctx)
+	wc := discoveryObject(client).If(conditions).NewWriter(ctx)
+	wc.ContentType = "application/json"
 
 	if _, err := wc.Write([]byte(fileContent)); err != nil {
 		return err
 	}
 
-	if err := wc.Close(); err != nil {
-		return err
+	err = wc.Close()
+
+	var apiErr *googleapi.Error
+
+	if errors.As(err, &apiErr) && apiErr.Code == http.StatusPreconditionFailed {
+		return ErrDiscoveryChanged
 	}
 
-	return nil
+	return err
 }
 
 func UploadReleaseBuild(fileName string, filePath string) error {
@@ -5,13 +5,16 @@ go 1.15
 require (
 	cloud.google.com/go v0.54.0
 	cloud.google.com/go/storage v1.6.0
+	filippo.io/edwards25519 v1.0.0
 	github.com/Jeffail/gabs/v2 v2.6.0
+	github.com/aws/aws-sdk-go v1.27.0
 	github.com/cloudflare/cloudflare-go v0.20.0
 	github.com/ethereum/go-ethereum v1.10.2
 	github.com/fatih/color v1.7.0
 	github.com/fsnotify/fsnotify v1.4.9 // indirect
 	github.com/google/go-github/v41 v41.0.0
 	github.com/magiconair/properties v1.8.4 // indirect
+	github.com/miekg/dns v1.0.14
 	github.com/mitchellh/go-homedir v1.1.0
 	github.com/mitchellh/mapstructure v1.4.1 // indirect
 	github.com/mittwald/go-helm-client v0.4.3
@@ -35,4 +38,5 @@ require (
 	k8s.io/api v0.20.5
 	k8s.io/apimachinery v0.20.5
 	k8s.io/client-go v0.20.5
+	sigs.k8s.io/yaml v1.2.0
 )
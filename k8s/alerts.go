@@ -2,24 +2,39 @@ package k8s
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"strings"
 	"time"
 
 	appsv1 "k8s.io/api/apps/v1"
 	apiv1 "k8s.io/api/core/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
-func (k8s *Kubernetes) DeploySpacemeshWatch() error {
-	fmt.Println("deploying spacemesh watch")
+const watchConfigDir = "/etc/spacemesh-watch"
 
-	deploymentClient := k8s.Client.AppsV1().Deployments(apiv1.NamespaceDefault)
+// watchScript runs spacemesh-watch with the targets and flags of the config map
+// and restarts it whenever kubelet updates the mounted config map
+const watchScript = `
+config() { cat ` + watchConfigDir + `/nodes ` + watchConfigDir + `/flags; }
+while true; do
+  current=$(config)
+  /bin/spacemesh-watch --nodes="$(cat ` + watchConfigDir + `/nodes)" $(cat ` + watchConfigDir + `/flags) $WATCH_ARGS &
+  pid=$!
+  while [ "$(config)" = "$current" ] && kill -0 $pid 2>/dev/null; do sleep 10; done
+  echo "spacemesh-watch config changed, restarting"
+  kill $pid 2>/dev/null
+  wait $pid
+done
+`
 
+func (k8s *Kubernetes) watchTargets() (string, error) {
 	miners, err := k8s.GetMiners()
 
 	if err != nil {
-		return err
+		return "", err
 	}
 
 	apiURLs := []string{}
@@ -27,27 +42,176 @@ func (k8s *Kubernetes) DeploySpacemeshWatch() error {
 	ip, err := k8s.GetExternalIP()
 
 	if err != nil {
-		return err
+		return "", err
 	}
 
 	for _, miner := range miners {
 		port, err := k8s.GetExternalPort(miner, "grpcport")
 		if err != nil {
-			return err
+			return "", err
 		}
 
 		apiURLs = append(apiURLs, ip+":"+port+"/"+miner)
 	}
 
-	command := []string{
-		"/bin/spacemesh-watch",
-		"--nodes=" + strings.Join(apiURLs[:], ","),
+	return strings.Join(apiURLs[:], ","), nil
+}
+
+// updateSpacemeshWatchConfig writes the current miners and, if updateFlags is
+// set, the watch flags to the config map which spacemesh-watch reloads
+func (k8s *Kubernetes) updateSpacemeshWatchConfig(updateFlags bool) error {
+	nodes, err := k8s.watchTargets()
+
+	if err != nil {
+		return err
+	}
+
+	configMapClient := k8s.Client.CoreV1().ConfigMaps(apiv1.NamespaceDefault)
+	configMap, err := configMapClient.Get(context.TODO(), "spacemesh-watch", metav1.GetOptions{})
+
+	if k8serrors.IsNotFound(err) {
+		configMap = &apiv1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: "spacemesh-watch",
+			},
+			Data: map[string]string{
+				"flags": strings.Join(config.WatchFlags, " "),
+			},
+		}
+	} else if err != nil {
+		return err
+	}
+
+	if configMap.Data == nil {
+		configMap.Data = map[string]string{}
+	}
+
+	configMap.Data["nodes"] = nodes
+
+	if updateFlags {
+		configMap.Data["flags"] = strings.Join(config.WatchFlags, " ")
+	}
+
+	fmt.Println("updating spacemesh-watch config")
+
+	if configMap.ResourceVersion == "" {
+		_, err = configMapClient.Create(context.TODO(), configMap, metav1.CreateOptions{})
+	} else {
+		_, err = configMapClient.Update(context.TODO(), configMap, metav1.UpdateOptions{})
+	}
+
+	return err
+}
+
+// UpdateSpacemeshWatch updates the targets and applies the image and the
+// watch flags of the config if updateImage and updateFlags are set
+func (k8s *Kubernetes) UpdateSpacemeshWatch(updateImage bool, updateFlags bool) error {
+	if err := k8s.migrateSpacemeshWatch(); err != nil {
+		return err
+	}
+
+	if err := k8s.updateSpacemeshWatchConfig(updateFlags); err != nil {
+		return err
+	}
+
+	if !updateImage {
+		return nil
+	}
+
+	return k8s.updateSpacemeshWatchImage()
+}
+
+// SyncSpacemeshWatch updates the watch targets if spacemesh-watch is deployed
+func (k8s *Kubernetes) SyncSpacemeshWatch() error {
+	_, err := k8s.Client.AppsV1().Deployments(apiv1.NamespaceDefault).Get(context.TODO(), "spacemesh-watch", metav1.GetOptions{})
+
+	if k8serrors.IsNotFound(err) {
+		return nil
+	}
+
+	if err != nil {
+		return err
+	}
+
+	if err = k8s.migrateSpacemeshWatch(); err != nil {
+		return err
+	}
+
+	return k8s.updateSpacemeshWatchConfig(false)
+}
+
+// migrateSpacemeshWatch changes a spacemesh-watch deployed with the targets
+// in its command to read them from the config map. The other arguments of
+// the command, e.g., the slack token, are kept.
+func (k8s *Kubernetes) migrateSpacemeshWatch() error {
+	deploymentClient := k8s.Client.AppsV1().Deployments(apiv1.NamespaceDefault)
+	deployment, err := deploymentClient.Get(context.TODO(), "spacemesh-watch", metav1.GetOptions{})
+
+	if err != nil {
+		return err
+	}
+
+	for _, volume := range deployment.Spec.Template.Spec.Volumes {
+		if volume.Name == "config" {
+			return nil
+		}
+	}
+
+	container := &deployment.Spec.Template.Spec.Containers[0]
+
+	if len(container.Args) != 1 || !strings.HasPrefix(container.Args[0], "/bin/spacemesh-watch ") {
+		return errors.New("spacemesh-watch has an unknown command, delete its deployment and create it again")
+	}
+
+	args := []string{}
+
+	for _, arg := range strings.Fields(container.Args[0])[1:] {
+		if !strings.HasPrefix(arg, "--nodes=") {
+			args = append(args, arg)
+		}
+	}
+
+	fmt.Println("migrating spacemesh-watch to the config map")
+
+	// the config map needs to exist before the pod mounts it
+	if err = k8s.updateSpacemeshWatchConfig(false); err != nil {
+		return err
+	}
+
+	container.Args = []string{watchScript}
+	container.Env = append(container.Env, apiv1.EnvVar{Name: "WATCH_ARGS", Value: strings.Join(args, " ")})
+	container.VolumeMounts = append(container.VolumeMounts, apiv1.VolumeMount{Name: "config", MountPath: watchConfigDir, ReadOnly: true})
+
+	deployment.Spec.Template.Spec.Volumes = append(deployment.Spec.Template.Spec.Volumes, apiv1.Volume{
+		Name: "config",
+		VolumeSource: apiv1.VolumeSource{
+			ConfigMap: &apiv1.ConfigMapVolumeSource{
+				LocalObjectReference: apiv1.LocalObjectReference{Name: "spacemesh-watch"},
+			},
+		},
+	})
+
+	_, err = deploymentClient.Update(context.TODO(), deployment, metav1.UpdateOptions{})
+
+	return err
+}
+
+func (k8s *Kubernetes) DeploySpacemeshWatch() error {
+	fmt.Println("deploying spacemesh watch")
+
+	deploymentClient := k8s.Client.AppsV1().Deployments(apiv1.NamespaceDefault)
+
+	if err := k8s.updateSpacemeshWatchConfig(true); err != nil {
+		return err
+	}
+
+	args := []string{
 		"--network-name=" + config.NetworkName,
 	}
 
 	if config.SlackToken != "" && config.SlackChannelId != "" {
-		command = append(command, "--slack-api-token="+config.SlackToken)
-		command = append(command, "--slack-channel-name="+config.SlackChannelId)
+		args = append(args, "--slack-api-token="+config.SlackToken)
+		args = append(args, "--slack-channel-name="+config.SlackChannelId)
 	}
 
 	deployment := &appsv1.Deployment{
@@ -73,7 +237,23 @@ func (k8s *Kubernetes) DeploySpacemeshWatch() error {
 							Name:    "spacemesh-watch",
 							Image:   config.SpacemeshWatchImage,
 							Command: []string{"/bin/sh", "-c"},
-							Args:    []string{strings.Join(command[:], " ")},
+							Args:    []string{watchScript},
+							Env: []apiv1.EnvVar{
+								{Name: "WATCH_ARGS", Value: strings.Join(args, " ")},
+							},
+							VolumeMounts: []apiv1.VolumeMount{
+								{Name: "config", MountPath: watchConfigDir, ReadOnly: true},
+							},
+						},
+					},
+					Volumes: []apiv1.Volume{
+						{
+							Name: "config",
+							VolumeSource: apiv1.VolumeSource{
+								ConfigMap: &apiv1.ConfigMapVolumeSource{
+									LocalObjectReference: apiv1.LocalObjectReference{Name: "spacemesh-watch"},
+								},
+							},
 						},
 					},
 				},
@@ -81,7 +261,7 @@ func (k8s *Kubernetes) DeploySpacemeshWatch() error {
 		},
 	}
 
-	_, err = deploymentClient.Create(context.TODO(), deployment, metav1.CreateOptions{})
+	_, err := deploymentClient.Create(context.TODO(), deployment, metav1.CreateOptions{})
 
 	if err != nil {
 		return err
@@ -105,6 +285,27 @@ func (k8s *Kubernetes) DeploySpacemeshWatch() error {
 	return nil
 }
 
+// updateSpacemeshWatchImage changes the image of spacemesh-watch, which restarts it
+func (k8s *Kubernetes) updateSpacemeshWatchImage() error {
+	deploymentClient := k8s.Client.AppsV1().Deployments(apiv1.NamespaceDefault)
+	deployment, err := deploymentClient.Get(context.TODO(), "spacemesh-watch", metav1.GetOptions{})
+
+	if err != nil {
+		return err
+	}
+
+	if deployment.Spec.Template.Spec.Containers[0].Image == config.SpacemeshWatchImage {
+		return nil
+	}
+
+	fmt.Println("updating spacemesh-watch image to " + config.SpacemeshWatchImage)
+
+	deployment.Spec.Template.Spec.Containers[0].Image = config.SpacemeshWatchImage
+	_, err = deploymentClient.Update(context.TODO(), deployment, metav1.UpdateOptions{})
+
+	return err
+}
+
 func (k8s *Kubernetes) DeleteSpacemeshWatch() error {
 	deploymentClient := k8s.Client.AppsV1().Deployments(apiv1.NamespaceDefault)
 
@@ -0,0 +1,305 @@
+package k8s
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	cfg "github.com/spacemeshos/go-spacecraft/config"
+	apiv1 "k8s.io/api/core/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	archiveRepository = "archive"
+	archivePolicy     = "spacecraft-archive"
+	archiveSecret     = "elasticsearch-archive-credentials"
+)
+
+type snapshotInfo struct {
+	Snapshot  string   `json:"snapshot"`
+	Indices   []string `json:"indices"`
+	State     string   `json:"state"`
+	StartTime int64    `json:"start_time_in_millis"`
+}
+
+// archivePath is the path of the logs snapshots of a network in the logs bucket
+func archivePath(networkName string) string {
+	return networkName + "-archive/logs"
+}
+
+func archiveCredentials() (string, error) {
+	credentials := config.ArchiveCredentials
+
+	if credentials == "" {
+		credentials = os.Getenv("GOOGLE_APPLICATION_CREDENTIALS")
+	}
+
+	if credentials == "" {
+		return "", errors.New("logs archive needs a GCP service account key, set --archive-credentials or GOOGLE_APPLICATION_CREDENTIALS")
+	}
+
+	return credentials, nil
+}
+
+// esArchiveValues stores the GCS credentials in the elasticsearch keystore and
+// installs the repository-gcs plugin before elasticsearch starts
+func (k8s *Kubernetes) esArchiveValues() (string, error) {
+	if !config.ArchiveLogs {
+		return "", nil
+	}
+
+	credentials, err := archiveCredentials()
+
+	if err != nil {
+		return "", err
+	}
+
+	credentialsData, err := ioutil.ReadFile(credentials)
+
+	if err != nil {
+		return "", err
+	}
+
+	secret := &apiv1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: archiveSecret,
+		},
+		Data: map[string][]byte{
+			"gcs.client.default.credentials_file": credentialsData,
+		},
+	}
+
+	secretsClient := k8s.Client.CoreV1().Secrets("default")
+	_, err = secretsClient.Create(context.Background(), secret, metav1.CreateOptions{})
+
+	// elasticsearch is redeployed with the current credentials
+	if k8serrors.IsAlreadyExists(err) {
+		_, err = secretsClient.Update(context.Background(), secret, metav1.UpdateOptions{})
+	}
+
+	if err != nil {
+		return "", err
+	}
+
+	return fmt.Sprintf(`
+			keystore:
+				- secretName: %s
+			extraVolumes:
+				- name: plugins
+					emptyDir: {}
+			extraVolumeMounts:
+				- name: plugins
+					mountPath: /usr/share/elasticsearch/plugins
+			extraInitContainers:
+				- name: install-plugins
+					image: docker.elastic.co/elasticsearch/elasticsearch:%s
+					command: ["sh", "-c", "bin/elasticsearch-plugin install --batch repository-gcs && cp -r plugins/. /plugins"]
+					volumeMounts:
+						- name: plugins
+							mountPath: /plugins`, archiveSecret, elasticsearchVersion()), nil
+}
+
+// elasticsearchVersion is the version of elasticsearch the plugins are
+// installed for. The elastic charts have the version of elasticsearch, a
+// local chart falls back to the default version.
+func elasticsearchVersion() string {
+	version := config.Chart("elasticsearch").Version
+
+	if version == "" {
+		version = cfg.DefaultCharts["elasticsearch"].Version
+	}
+
+	return version
+}
+
+func (k8s *Kubernetes) createArchiveRepository(name string, networkName string, readonly bool) error {
+	_, err := k8s.ESRequest(http.MethodPut, "/_snapshot/"+name, map[string]interface{}{
+		"type": "gcs",
+		"settings": map[string]interface{}{
+			"bucket":    config.LogsBucket,
+			"base_path": archivePath(networkName),
+			"readonly":  readonly,
+		},
+	})
+
+	return err
+}
+
+// SetupLogArchive creates the snapshot repository and a daily snapshot policy
+// which the log deletion policy waits for before deleting an index
+func (k8s *Kubernetes) SetupLogArchive() error {
+	err := k8s.createArchiveRepository(archiveRepository, config.NetworkName, false)
+
+	if err != nil {
+		return err
+	}
+
+	_, err = k8s.ESRequest(http.MethodPut, "/_slm/policy/"+archivePolicy, map[string]interface{}{
+		"schedule":   "0 30 1 * * ?",
+		"name":       "<logs-{now/d}>",
+		"repository": archiveRepository,
+		"config": map[string]interface{}{
+			"indices":              []string{"sm-*", "ws-*"},
+			"ignore_unavailable":   true,
+			"include_global_state": false,
+		},
+	})
+
+	return err
+}
+
+func (k8s *Kubernetes) hasArchiveRepository() (bool, error) {
+	data, err := k8s.ESRequest(http.MethodGet, "/_snapshot", nil)
+
+	if err != nil {
+		return false, err
+	}
+
+	repositories := map[string]interface{}{}
+
+	if err = json.Unmarshal(data, &repositories); err != nil {
+		return false, err
+	}
+
+	_, ok := repositories[archiveRepository]
+
+	return ok, nil
+}
+
+// ArchiveLogs takes a final snapshot of all the logs of the network if it was
+// created with the logs archive. The logs in a shared elasticsearch are kept
+// there.
+func (k8s *Kubernetes) ArchiveLogs() error {
+	es, err := k8s.GetESConnection()
+
+	if err != nil {
+		return err
+	}
+
+	if es.External {
+		return nil
+	}
+
+	ok, err := k8s.hasArchiveRepository()
+
+	if err != nil {
+		return err
+	}
+
+	if !ok {
+		fmt.Println("logs archive is not configured, skipping")
+		return nil
+	}
+
+	fmt.Println("archiving logs")
+
+	_, err = k8s.ESRequest(http.MethodPut, fmt.Sprintf("/_snapshot/%s/final-%d?wait_for_completion=true", archiveRepository, time.Now().Unix()), map[string]interface{}{
+		"indices":              "sm-*,ws-*",
+		"ignore_unavailable":   true,
+		"include_global_state": false,
+	})
+
+	return err
+}
+
+// RestoreLogs restores the archived logs of a network with the indices
+// prefixed by its name. Every index is restored from its latest snapshot.
+func (k8s *Kubernetes) RestoreLogs(networkName string) (int, error) {
+	repository := archiveRepository + "-" + networkName
+
+	err := k8s.createArchiveRepository(repository, networkName, true)
+
+	if err != nil {
+		return 0, err
+	}
+
+	data, err := k8s.ESRequest(http.MethodGet, "/_snapshot/"+repository+"/_all", nil)
+
+	if err != nil {
+		return 0, err
+	}
+
+	response := struct {
+		Snapshots []snapshotInfo `json:"snapshots"`
+	}{}
+
+	if err = json.Unmarshal(data, &response); err != nil {
+		return 0, err
+	}
+
+	if len(response.Snapshots) == 0 {
+		return 0, errors.New("no archived logs found for " + networkName)
+	}
+
+	sort.Slice(response.Snapshots, func(i, j int) bool {
+		return response.Snapshots[i].StartTime > response.Snapshots[j].StartTime
+	})
+
+	data, err = k8s.ESRequest(http.MethodGet, "/_cat/indices/"+networkName+"-*?format=json&h=index", nil)
+
+	if err != nil {
+		return 0, err
+	}
+
+	existing := []struct {
+		Index string `json:"index"`
+	}{}
+
+	if err = json.Unmarshal(data, &existing); err != nil {
+		return 0, err
+	}
+
+	restored := map[string]bool{}
+
+	for _, index := range existing {
+		restored[strings.TrimPrefix(index.Index, networkName+"-")] = true
+	}
+
+	count := 0
+
+	for _, snapshot := range response.Snapshots {
+		if snapshot.State != "SUCCESS" && snapshot.State != "PARTIAL" {
+			continue
+		}
+
+		indices := []string{}
+
+		for _, index := range snapshot.Indices {
+			if (strings.HasPrefix(index, "sm-") || strings.HasPrefix(index, "ws-")) && !restored[index] {
+				indices = append(indices, index)
+				restored[index] = true
+			}
+		}
+
+		if len(indices) == 0 {
+			continue
+		}
+
+		fmt.Println("restoring " + strings.Join(indices, ", ") + " from " + snapshot.Snapshot)
+
+		_, err = k8s.ESRequest(http.MethodPost, "/_snapshot/"+repository+"/"+snapshot.Snapshot+"/_restore?wait_for_completion=true", map[string]interface{}{
+			"indices":               strings.Join(indices, ","),
+			"rename_pattern":        "(.+)",
+			"rename_replacement":    networkName + "-$1",
+			"include_global_state":  false,
+			"ignore_index_settings": []string{"index.lifecycle.name"},
+		})
+
+		if err != nil {
+			return count, err
+		}
+
+		count += len(indices)
+	}
+
+	return count, nil
+}
@@ -0,0 +1,421 @@
+package k8s
+
+import (
+	"context"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	helm "github.com/mittwald/go-helm-client"
+	apiv1 "k8s.io/api/core/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic"
+)
+
+const (
+	certManagerNamespace = "cert-manager"
+	// clusterIssuer issues the certificates of all the ingresses
+	clusterIssuer   = "spacecraft"
+	dnsCredentials  = "spacecraft-dns-credentials"
+	selfSignedCA    = "spacecraft-ca"
+	selfSignedRoot  = "spacecraft-selfsigned"
+	certificateName = "cert-manager.io/certificate-name"
+	issuerName      = "cert-manager.io/issuer-name"
+)
+
+var (
+	clusterIssuerResource = schema.GroupVersionResource{Group: "cert-manager.io", Version: "v1", Resource: "clusterissuers"}
+	certificateResource   = schema.GroupVersionResource{Group: "cert-manager.io", Version: "v1", Resource: "certificates"}
+)
+
+// CertificateInfo is a TLS secret of the network and the certificate in it
+type CertificateInfo struct {
+	Name      string
+	Namespace string
+	Issuer    string
+	DNSNames  []string
+	NotAfter  time.Time
+}
+
+// ManagedTLS is true if the certificates are issued by cert-manager instead
+// of being read from TLSCert and TLSKey
+func ManagedTLS() bool {
+	return config.TLSIssuer != "files"
+}
+
+// DeployCertManager installs cert-manager and creates the cluster issuer of
+// the network. It can be run again on a network which already has it.
+func (k8s *Kubernetes) DeployCertManager() error {
+	if config.TLSIssuer != "acme" && config.TLSIssuer != "selfsigned" {
+		return errors.New("unknown TLS issuer " + config.TLSIssuer + ", use files, acme or selfsigned")
+	}
+
+	namespace := &apiv1.Namespace{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: certManagerNamespace,
+		},
+	}
+
+	if _, err := k8s.Client.CoreV1().Namespaces().Create(context.TODO(), namespace, metav1.CreateOptions{}); err != nil && !k8serrors.IsAlreadyExists(err) {
+		return err
+	}
+
+	opt := &helm.RestConfClientOptions{
+		Options: &helm.Options{
+			Debug:     true,
+			Linting:   true,
+			Namespace: certManagerNamespace,
+		},
+		RestConfig: k8s.RestConfig,
+	}
+
+	client, err := helm.NewClientFromRestConf(opt)
+	if err != nil {
+		return err
+	}
+
+	certManagerSpec := helm.ChartSpec{
+		ReleaseName: "cert-manager",
+		Namespace:   certManagerNamespace,
+		Wait:        true,
+		ValuesYaml: sanitizeYaml(`
+			installCRDs: true
+		`),
+	}
+
+	if err = installChart(client, "cert-manager", &certManagerSpec); err != nil {
+		return err
+	}
+
+	issuers := []*unstructured.Unstructured{}
+
+	if config.TLSIssuer == "acme" {
+		solver, err := k8s.dns01Solver()
+
+		if err != nil {
+			return err
+		}
+
+		issuers = append(issuers, newClusterIssuer(clusterIssuer, map[string]interface{}{
+			"acme": map[string]interface{}{
+				"server": config.ACMEServer,
+				"email":  config.ACMEEmail,
+				"privateKeySecretRef": map[string]interface{}{
+					"name": "spacecraft-acme-account",
+				},
+				"solvers": []interface{}{
+					map[string]interface{}{"dns01": solver},
+				},
+			},
+		}))
+	} else {
+		// the root issuer signs the CA which signs the certificates of the
+		// ingresses, so that only the CA has to be trusted locally
+		issuers = append(issuers,
+			newClusterIssuer(selfSignedRoot, map[string]interface{}{
+				"selfSigned": map[string]interface{}{},
+			}),
+			newClusterIssuer(clusterIssuer, map[string]interface{}{
+				"ca": map[string]interface{}{
+					"secretName": selfSignedCA,
+				},
+			}),
+		)
+	}
+
+	dynamicClient, err := dynamic.NewForConfig(k8s.RestConfig)
+
+	if err != nil {
+		return err
+	}
+
+	for _, issuer := range issuers {
+		if err = k8s.applyCertManagerObject(dynamicClient.Resource(clusterIssuerResource), issuer); err != nil {
+			return err
+		}
+	}
+
+	if config.TLSIssuer == "selfsigned" {
+		ca := newCertificate(certManagerNamespace, selfSignedCA, selfSignedRoot, nil)
+		spec := ca.Object["spec"].(map[string]interface{})
+		spec["isCA"] = true
+		spec["commonName"] = "spacecraft " + config.NetworkName + " CA"
+
+		return k8s.applyCertManagerObject(dynamicClient.Resource(certificateResource).Namespace(certManagerNamespace), ca)
+	}
+
+	return nil
+}
+
+// dns01Solver returns the DNS-01 solver of the configured DNS provider. The
+// credentials of the provider are copied to a secret in the cert-manager
+// namespace.
+func (k8s *Kubernetes) dns01Solver() (map[string]interface{}, error) {
+	secretRef := func(key string) map[string]interface{} {
+		return map[string]interface{}{"name": dnsCredentials, "key": key}
+	}
+
+	credentials := map[string][]byte{}
+	solver := map[string]interface{}{}
+
+	switch config.DNSProvider {
+	case "cloudflare":
+		if config.CloudflareAPIToken == "" {
+			return nil, errors.New("acme needs --cloudflare-api-token to solve DNS-01 challenges")
+		}
+
+		credentials["api-token"] = []byte(config.CloudflareAPIToken)
+		solver["cloudflare"] = map[string]interface{}{
+			"apiTokenSecretRef": secretRef("api-token"),
+		}
+	case "route53":
+		if os.Getenv("AWS_ACCESS_KEY_ID") == "" || os.Getenv("AWS_SECRET_ACCESS_KEY") == "" {
+			return nil, errors.New("acme needs AWS_ACCESS_KEY_ID and AWS_SECRET_ACCESS_KEY to solve DNS-01 challenges with route53")
+		}
+
+		region := os.Getenv("AWS_REGION")
+
+		if region == "" {
+			region = "us-east-1"
+		}
+
+		credentials["secret-access-key"] = []byte(os.Getenv("AWS_SECRET_ACCESS_KEY"))
+		solver["route53"] = map[string]interface{}{
+			"region":                   region,
+			"accessKeyID":              os.Getenv("AWS_ACCESS_KEY_ID"),
+			"secretAccessKeySecretRef": secretRef("secret-access-key"),
+		}
+	case "clouddns":
+		key, err := ioutil.ReadFile(os.Getenv("GOOGLE_APPLICATION_CREDENTIALS"))
+
+		if err != nil {
+			return nil, fmt.Errorf("acme needs GOOGLE_APPLICATION_CREDENTIALS to solve DNS-01 challenges with clouddns: %w", err)
+		}
+
+		credentials["key.json"] = key
+		solver["cloudDNS"] = map[string]interface{}{
+			"project":                 config.GCPProject,
+			"serviceAccountSecretRef": secretRef("key.json"),
+		}
+	case "rfc2136":
+		rfc2136 := map[string]interface{}{
+			"nameserver": config.DNSServer,
+		}
+
+		if config.DNSTSIGKey != "" {
+			credentials["tsig-secret"] = []byte(config.DNSTSIGSecret)
+			rfc2136["tsigKeyName"] = config.DNSTSIGKey
+			// cert-manager names the algorithms HMACSHA256 etc.
+			rfc2136["tsigAlgorithm"] = strings.ToUpper(strings.ReplaceAll(config.DNSTSIGAlgorithm, "-", ""))
+			rfc2136["tsigSecretSecretRef"] = secretRef("tsig-secret")
+		}
+
+		solver["rfc2136"] = rfc2136
+	default:
+		return nil, errors.New("acme can't solve DNS-01 challenges with DNS provider " + config.DNSProvider + ", use --tls-issuer selfsigned")
+	}
+
+	secretsClient := k8s.Client.CoreV1().Secrets(certManagerNamespace)
+
+	secret := &apiv1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: dnsCredentials,
+		},
+		Data: credentials,
+	}
+
+	_, err := secretsClient.Create(context.Background(), secret, metav1.CreateOptions{})
+
+	if k8serrors.IsAlreadyExists(err) {
+		_, err = secretsClient.Update(context.Background(), secret, metav1.UpdateOptions{})
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	return solver, nil
+}
+
+func newClusterIssuer(name string, spec map[string]interface{}) *unstructured.Unstructured {
+	return &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "cert-manager.io/v1",
+		"kind":       "ClusterIssuer",
+		"metadata": map[string]interface{}{
+			"name": name,
+			"labels": map[string]interface{}{
+				"app.kubernetes.io/managed-by": "spacecraft",
+			},
+		},
+		"spec": spec,
+	}}
+}
+
+func newCertificate(namespace string, secretName string, issuer string, dnsNames []string) *unstructured.Unstructured {
+	spec := map[string]interface{}{
+		"secretName": secretName,
+		"issuerRef": map[string]interface{}{
+			"name": issuer,
+			"kind": "ClusterIssuer",
+		},
+	}
+
+	if len(dnsNames) > 0 {
+		names := []interface{}{}
+
+		for _, name := range dnsNames {
+			names = append(names, name)
+		}
+
+		spec["dnsNames"] = names
+	}
+
+	return &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "cert-manager.io/v1",
+		"kind":       "Certificate",
+		"metadata": map[string]interface{}{
+			"name":      secretName,
+			"namespace": namespace,
+			"labels": map[string]interface{}{
+				"app.kubernetes.io/managed-by": "spacecraft",
+			},
+		},
+		"spec": spec,
+	}}
+}
+
+// applyCertManagerObject creates or updates the object. The cert-manager
+// webhook may not accept requests right after the chart is installed so
+// creating is retried for a minute.
+func (k8s *Kubernetes) applyCertManagerObject(client dynamic.ResourceInterface, object *unstructured.Unstructured) error {
+	fmt.Println("applying " + object.GetKind() + " " + object.GetName())
+
+	retries := 0
+
+	for range time.Tick(5 * time.Second) {
+		existing, err := client.Get(context.Background(), object.GetName(), metav1.GetOptions{})
+
+		if err == nil {
+			object.SetResourceVersion(existing.GetResourceVersion())
+			_, err = client.Update(context.Background(), object, metav1.UpdateOptions{})
+		} else if k8serrors.IsNotFound(err) {
+			_, err = client.Create(context.Background(), object, metav1.CreateOptions{})
+		}
+
+		if err == nil {
+			break
+		}
+
+		if retries++; retries == 12 {
+			return err
+		}
+
+		fmt.Println("waiting for cert-manager webhook")
+	}
+
+	return nil
+}
+
+// createCertificate requests a certificate for the services from the cluster
+// issuer, cert-manager stores it in secretName and renews it before it
+// expires. Nothing is done if the certificates aren't managed.
+func (k8s *Kubernetes) createCertificate(namespace string, secretName string, services ...string) error {
+	if !ManagedTLS() {
+		return nil
+	}
+
+	dynamicClient, err := dynamic.NewForConfig(k8s.RestConfig)
+
+	if err != nil {
+		return err
+	}
+
+	hosts := []string{}
+
+	for _, service := range services {
+		hosts = append(hosts, Domain(service))
+	}
+
+	certificate := newCertificate(namespace, secretName, clusterIssuer, hosts)
+
+	return k8s.applyCertManagerObject(dynamicClient.Resource(certificateResource).Namespace(namespace), certificate)
+}
+
+// ingressTLS returns the tls value of the ingress of a chart, it is empty if
+// the certificates aren't managed
+func ingressTLS(secretName string, services ...string) string {
+	if !ManagedTLS() {
+		return "[]"
+	}
+
+	hosts := []string{}
+
+	for _, service := range services {
+		hosts = append(hosts, Domain(service))
+	}
+
+	return fmt.Sprintf("[ { secretName: %s, hosts: [ %s ] } ]", secretName, strings.Join(hosts, ", "))
+}
+
+// ListCertificates returns the certificates of all the TLS secrets of the
+// network sorted by expiry
+func (k8s *Kubernetes) ListCertificates() ([]CertificateInfo, error) {
+	secrets, err := k8s.Client.CoreV1().Secrets("").List(context.Background(), metav1.ListOptions{
+		FieldSelector: "type=kubernetes.io/tls",
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	certificates := []CertificateInfo{}
+
+	for _, secret := range secrets.Items {
+		block, _ := pem.Decode(secret.Data["tls.crt"])
+
+		if block == nil {
+			continue
+		}
+
+		cert, err := x509.ParseCertificate(block.Bytes)
+
+		if err != nil {
+			return nil, fmt.Errorf("%s/%s: %w", secret.Namespace, secret.Name, err)
+		}
+
+		issuer := secret.Annotations[issuerName]
+
+		if secret.Annotations[certificateName] == "" {
+			issuer = "file"
+		}
+
+		dnsNames := cert.DNSNames
+
+		if len(dnsNames) == 0 {
+			dnsNames = []string{cert.Subject.CommonName}
+		}
+
+		certificates = append(certificates, CertificateInfo{
+			Name:      secret.Name,
+			Namespace: secret.Namespace,
+			Issuer:    issuer,
+			DNSNames:  dnsNames,
+			NotAfter:  cert.NotAfter,
+		})
+	}
+
+	sort.Slice(certificates, func(i, j int) bool {
+		return certificates[i].NotAfter.Before(certificates[j].NotAfter)
+	})
+
+	return certificates, nil
+}
@@ -0,0 +1,372 @@
+package k8s
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic"
+)
+
+const chaosNamespace = "chaos-testing"
+const chaosPauseAnnotation = "experiment.chaos-mesh.org/pause"
+
+type ChaosStart struct {
+	Epoch *int   `json:"epoch,omitempty"`
+	Layer *int   `json:"layer,omitempty"`
+	After string `json:"after,omitempty"`
+}
+
+type ChaosExperiment struct {
+	Name            string      `json:"name"`
+	Action          string      `json:"action"`
+	Target          string      `json:"target"`
+	Percent         int         `json:"percent,omitempty"`
+	Duration        string      `json:"duration,omitempty"`
+	Latency         string      `json:"latency,omitempty"`
+	Jitter          string      `json:"jitter,omitempty"`
+	Loss            string      `json:"loss,omitempty"`
+	PartitionTarget string      `json:"partitionTarget,omitempty"`
+	TimeOffset      string      `json:"timeOffset,omitempty"`
+	VolumePath      string      `json:"volumePath,omitempty"`
+	Start           *ChaosStart `json:"start,omitempty"`
+}
+
+type ChaosExperimentInfo struct {
+	Name   string
+	Kind   string
+	Paused bool
+	Start  string
+}
+
+var chaosKinds = map[string]string{
+	"PodChaos":     "podchaos",
+	"NetworkChaos": "networkchaos",
+	"TimeChaos":    "timechaos",
+	"IOChaos":      "iochaos",
+	"Workflow":     "workflows",
+}
+
+// workflowChaosFields are the fields of the workflow templates which embed
+// the experiment of a kind
+var workflowChaosFields = map[string]string{
+	"PodChaos":     "podChaos",
+	"NetworkChaos": "networkChaos",
+	"TimeChaos":    "timeChaos",
+	"IOChaos":      "ioChaos",
+}
+
+func chaosResource(kind string) schema.GroupVersionResource {
+	return schema.GroupVersionResource{Group: "chaos-mesh.org", Version: "v1alpha1", Resource: chaosKinds[kind]}
+}
+
+// chaosSelector converts spacecraft roles and names to chaos mesh selectors
+func chaosSelector(target string) (map[string]interface{}, error) {
+	labels := map[string]interface{}{}
+
+	switch {
+	case target == "bootstrap":
+		labels["app"] = "miner"
+		labels["role"] = RoleBootstrap
+	case target == "bootnodes":
+		labels["app"] = "miner"
+		labels["role"] = RoleBootnode
+	case target == "miners":
+		labels["app"] = "miner"
+	case target == "poets":
+		labels["app"] = "poet"
+	case strings.HasPrefix(target, "group:"):
+		labels["app"] = "miner"
+		labels["group"] = strings.TrimPrefix(target, "group:")
+	case strings.HasPrefix(target, "cohort:"):
+		labels["app"] = "miner"
+		labels["cohort"] = strings.TrimPrefix(target, "cohort:")
+	case strings.HasPrefix(target, "miner-") || strings.HasPrefix(target, "poet-"):
+		labels["name"] = target
+	default:
+		return nil, errors.New("unknown chaos target: " + target)
+	}
+
+	return map[string]interface{}{
+		"namespaces":     []interface{}{"default"},
+		"labelSelectors": labels,
+	}, nil
+}
+
+func chaosSpec(experiment ChaosExperiment) (string, map[string]interface{}, error) {
+	selector, err := chaosSelector(experiment.Target)
+
+	if err != nil {
+		return "", nil, err
+	}
+
+	spec := map[string]interface{}{
+		"selector": selector,
+		"mode":     "all",
+	}
+
+	if experiment.Percent > 0 && experiment.Percent < 100 {
+		spec["mode"] = "fixed-percent"
+		spec["value"] = strconv.Itoa(experiment.Percent)
+	}
+
+	if experiment.Duration != "" && experiment.Action != "pod-kill" {
+		spec["duration"] = experiment.Duration
+	}
+
+	kind := ""
+
+	switch experiment.Action {
+	case "pod-kill":
+		kind = "PodChaos"
+		spec["action"] = "pod-kill"
+	case "network-delay":
+		kind = "NetworkChaos"
+		spec["action"] = "delay"
+		delay := map[string]interface{}{"latency": experiment.Latency}
+
+		if experiment.Jitter != "" {
+			delay["jitter"] = experiment.Jitter
+		}
+
+		spec["delay"] = delay
+	case "network-loss":
+		kind = "NetworkChaos"
+		spec["action"] = "loss"
+		spec["loss"] = map[string]interface{}{"loss": experiment.Loss}
+	case "network-partition":
+		kind = "NetworkChaos"
+		spec["action"] = "partition"
+		spec["direction"] = "both"
+
+		if experiment.PartitionTarget != "" {
+			target, err := chaosSelector(experiment.PartitionTarget)
+
+			if err != nil {
+				return "", nil, err
+			}
+
+			spec["target"] = map[string]interface{}{
+				"mode":     "all",
+				"selector": target,
+			}
+		}
+	case "clock-skew":
+		kind = "TimeChaos"
+		spec["timeOffset"] = experiment.TimeOffset
+	case "io-stress":
+		kind = "IOChaos"
+		volumePath := experiment.VolumePath
+
+		if volumePath == "" {
+			volumePath = "/root/data"
+		}
+
+		spec["action"] = "latency"
+		spec["volumePath"] = volumePath
+		spec["delay"] = experiment.Latency
+		spec["percent"] = int64(100)
+	default:
+		return "", nil, errors.New("unknown chaos action: " + experiment.Action)
+	}
+
+	return kind, spec, nil
+}
+
+// chaosWorkflowSpec returns the spec of a workflow which waits before it
+// runs the experiment. The duration of the experiment is the deadline of its
+// workflow template.
+func chaosWorkflowSpec(experiment ChaosExperiment, wait time.Duration) (map[string]interface{}, error) {
+	kind, spec, err := chaosSpec(experiment)
+
+	if err != nil {
+		return nil, err
+	}
+
+	delete(spec, "duration")
+
+	chaosTemplate := map[string]interface{}{
+		"name":                    experiment.Name,
+		"templateType":            kind,
+		workflowChaosFields[kind]: spec,
+	}
+
+	if experiment.Duration != "" && experiment.Action != "pod-kill" {
+		chaosTemplate["deadline"] = experiment.Duration
+	}
+
+	return map[string]interface{}{
+		"entry": "entry",
+		"templates": []interface{}{
+			map[string]interface{}{
+				"name":         "entry",
+				"templateType": "Serial",
+				"children":     []interface{}{"wait", experiment.Name},
+			},
+			map[string]interface{}{
+				"name":         "wait",
+				"templateType": "Suspend",
+				"deadline":     wait.Round(time.Second).String(),
+			},
+			chaosTemplate,
+		},
+	}, nil
+}
+
+// CreateChaosExperiment creates the experiment. An experiment which starts
+// later is created as a workflow which waits in the cluster until startAt,
+// the start time is recorded in an annotation.
+func (k8s *Kubernetes) CreateChaosExperiment(experiment ChaosExperiment, startAt time.Time) error {
+	client, err := dynamic.NewForConfig(k8s.RestConfig)
+
+	if err != nil {
+		return err
+	}
+
+	kind, spec, err := chaosSpec(experiment)
+
+	if err != nil {
+		return err
+	}
+
+	metadata := map[string]interface{}{
+		"name":      experiment.Name,
+		"namespace": chaosNamespace,
+		"labels": map[string]interface{}{
+			"app.kubernetes.io/managed-by": "spacecraft",
+		},
+	}
+
+	if !startAt.IsZero() {
+		metadata["annotations"] = map[string]interface{}{
+			"spacecraft/start": startAt.UTC().Format(time.RFC3339),
+		}
+	}
+
+	if wait := time.Until(startAt); wait > 0 {
+		kind = "Workflow"
+		spec, err = chaosWorkflowSpec(experiment, wait)
+
+		if err != nil {
+			return err
+		}
+	}
+
+	object := &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "chaos-mesh.org/v1alpha1",
+		"kind":       kind,
+		"metadata":   metadata,
+		"spec":       spec,
+	}}
+
+	fmt.Println("creating " + kind + " " + experiment.Name)
+
+	_, err = client.Resource(chaosResource(kind)).Namespace(chaosNamespace).Create(context.Background(), object, metav1.CreateOptions{})
+
+	return err
+}
+
+func (k8s *Kubernetes) ListChaosExperiments() ([]ChaosExperimentInfo, error) {
+	client, err := dynamic.NewForConfig(k8s.RestConfig)
+
+	if err != nil {
+		return nil, err
+	}
+
+	experiments := []ChaosExperimentInfo{}
+
+	for kind := range chaosKinds {
+		list, err := client.Resource(chaosResource(kind)).Namespace(chaosNamespace).List(context.Background(), metav1.ListOptions{
+			LabelSelector: "app.kubernetes.io/managed-by=spacecraft",
+		})
+
+		// older chaos mesh versions don't have every kind
+		if k8serrors.IsNotFound(err) {
+			continue
+		}
+
+		if err != nil {
+			return nil, err
+		}
+
+		for _, item := range list.Items {
+			annotations := item.GetAnnotations()
+
+			experiments = append(experiments, ChaosExperimentInfo{
+				Name:   item.GetName(),
+				Kind:   kind,
+				Paused: annotations[chaosPauseAnnotation] == "true",
+				Start:  annotations["spacecraft/start"],
+			})
+		}
+	}
+
+	return experiments, nil
+}
+
+func (k8s *Kubernetes) findChaosExperiment(name string) (dynamic.ResourceInterface, *unstructured.Unstructured, error) {
+	client, err := dynamic.NewForConfig(k8s.RestConfig)
+
+	if err != nil {
+		return nil, nil, err
+	}
+
+	for kind := range chaosKinds {
+		resourceClient := client.Resource(chaosResource(kind)).Namespace(chaosNamespace)
+		object, err := resourceClient.Get(context.Background(), name, metav1.GetOptions{})
+
+		if err == nil {
+			return resourceClient, object, nil
+		}
+	}
+
+	return nil, nil, errors.New("chaos experiment " + name + " not found")
+}
+
+// PauseChaosExperiment pauses or resumes the experiment using the chaos mesh pause annotation
+func (k8s *Kubernetes) PauseChaosExperiment(name string, pause bool) error {
+	resourceClient, object, err := k8s.findChaosExperiment(name)
+
+	if err != nil {
+		return err
+	}
+
+	if object.GetKind() == "Workflow" {
+		return errors.New("chaos experiment " + name + " waits for its start and can't be paused, delete it instead")
+	}
+
+	annotations := object.GetAnnotations()
+
+	if annotations == nil {
+		annotations = map[string]string{}
+	}
+
+	if pause {
+		annotations[chaosPauseAnnotation] = "true"
+	} else {
+		delete(annotations, chaosPauseAnnotation)
+	}
+
+	object.SetAnnotations(annotations)
+
+	_, err = resourceClient.Update(context.Background(), object, metav1.UpdateOptions{})
+
+	return err
+}
+
+func (k8s *Kubernetes) DeleteChaosExperiment(name string) error {
+	resourceClient, _, err := k8s.findChaosExperiment(name)
+
+	if err != nil {
+		return err
+	}
+
+	return resourceClient.Delete(context.Background(), name, metav1.DeleteOptions{})
+}
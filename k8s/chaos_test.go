@@ -0,0 +1,188 @@
+package k8s
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func testSelector(labels map[string]interface{}) map[string]interface{} {
+	return map[string]interface{}{
+		"namespaces":     []interface{}{"default"},
+		"labelSelectors": labels,
+	}
+}
+
+func TestChaosSpec(t *testing.T) {
+	miners := testSelector(map[string]interface{}{"app": "miner"})
+
+	tests := []struct {
+		name       string
+		experiment ChaosExperiment
+		kind       string
+		spec       map[string]interface{}
+	}{
+		{
+			name:       "pod kill ignores the duration",
+			experiment: ChaosExperiment{Action: "pod-kill", Target: "bootnodes", Duration: "5m"},
+			kind:       "PodChaos",
+			spec: map[string]interface{}{
+				"selector": testSelector(map[string]interface{}{"app": "miner", "role": RoleBootnode}),
+				"mode":     "all",
+				"action":   "pod-kill",
+			},
+		},
+		{
+			name:       "network delay of a percentage",
+			experiment: ChaosExperiment{Action: "network-delay", Target: "miners", Percent: 30, Duration: "10m", Latency: "200ms", Jitter: "50ms"},
+			kind:       "NetworkChaos",
+			spec: map[string]interface{}{
+				"selector": miners,
+				"mode":     "fixed-percent",
+				"value":    "30",
+				"duration": "10m",
+				"action":   "delay",
+				"delay":    map[string]interface{}{"latency": "200ms", "jitter": "50ms"},
+			},
+		},
+		{
+			name:       "network loss of all miners",
+			experiment: ChaosExperiment{Action: "network-loss", Target: "group:a", Percent: 100, Loss: "25"},
+			kind:       "NetworkChaos",
+			spec: map[string]interface{}{
+				"selector": testSelector(map[string]interface{}{"app": "miner", "group": "a"}),
+				"mode":     "all",
+				"action":   "loss",
+				"loss":     map[string]interface{}{"loss": "25"},
+			},
+		},
+		{
+			name:       "network partition",
+			experiment: ChaosExperiment{Action: "network-partition", Target: "cohort:adversarial", PartitionTarget: "poets"},
+			kind:       "NetworkChaos",
+			spec: map[string]interface{}{
+				"selector":  testSelector(map[string]interface{}{"app": "miner", "cohort": "adversarial"}),
+				"mode":      "all",
+				"action":    "partition",
+				"direction": "both",
+				"target": map[string]interface{}{
+					"mode":     "all",
+					"selector": testSelector(map[string]interface{}{"app": "poet"}),
+				},
+			},
+		},
+		{
+			name:       "clock skew",
+			experiment: ChaosExperiment{Action: "clock-skew", Target: "miner-3", TimeOffset: "-5m"},
+			kind:       "TimeChaos",
+			spec: map[string]interface{}{
+				"selector":   testSelector(map[string]interface{}{"name": "miner-3"}),
+				"mode":       "all",
+				"timeOffset": "-5m",
+			},
+		},
+		{
+			name:       "io stress with the default volume",
+			experiment: ChaosExperiment{Action: "io-stress", Target: "bootstrap", Latency: "100ms"},
+			kind:       "IOChaos",
+			spec: map[string]interface{}{
+				"selector":   testSelector(map[string]interface{}{"app": "miner", "role": RoleBootstrap}),
+				"mode":       "all",
+				"action":     "latency",
+				"volumePath": "/root/data",
+				"delay":      "100ms",
+				"percent":    int64(100),
+			},
+		},
+	}
+
+	for _, test := range tests {
+		kind, spec, err := chaosSpec(test.experiment)
+
+		if err != nil {
+			t.Errorf("%s: %v", test.name, err)
+			continue
+		}
+
+		if kind != test.kind {
+			t.Errorf("%s: kind is %s, expected %s", test.name, kind, test.kind)
+		}
+
+		if !reflect.DeepEqual(spec, test.spec) {
+			t.Errorf("%s: spec is %v, expected %v", test.name, spec, test.spec)
+		}
+	}
+}
+
+func TestChaosSpecErrors(t *testing.T) {
+	tests := []struct {
+		name       string
+		experiment ChaosExperiment
+	}{
+		{"unknown action", ChaosExperiment{Action: "disk-fill", Target: "miners"}},
+		{"unknown target", ChaosExperiment{Action: "pod-kill", Target: "explorer"}},
+		{"unknown partition target", ChaosExperiment{Action: "network-partition", Target: "miners", PartitionTarget: "dash"}},
+	}
+
+	for _, test := range tests {
+		if _, _, err := chaosSpec(test.experiment); err == nil {
+			t.Errorf("%s: experiment was accepted", test.name)
+		}
+	}
+}
+
+func TestChaosWorkflowSpec(t *testing.T) {
+	experiment := ChaosExperiment{Name: "slow-miners", Action: "network-loss", Target: "miners", Duration: "10m", Loss: "25"}
+	spec, err := chaosWorkflowSpec(experiment, 90*time.Second+400*time.Millisecond)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := map[string]interface{}{
+		"entry": "entry",
+		"templates": []interface{}{
+			map[string]interface{}{
+				"name":         "entry",
+				"templateType": "Serial",
+				"children":     []interface{}{"wait", "slow-miners"},
+			},
+			map[string]interface{}{
+				"name":         "wait",
+				"templateType": "Suspend",
+				"deadline":     "1m30s",
+			},
+			map[string]interface{}{
+				"name":         "slow-miners",
+				"templateType": "NetworkChaos",
+				"deadline":     "10m",
+				"networkChaos": map[string]interface{}{
+					"selector": testSelector(map[string]interface{}{"app": "miner"}),
+					"mode":     "all",
+					"action":   "loss",
+					"loss":     map[string]interface{}{"loss": "25"},
+				},
+			},
+		},
+	}
+
+	if !reflect.DeepEqual(spec, expected) {
+		t.Errorf("spec is %v, expected %v", spec, expected)
+	}
+
+	experiment = ChaosExperiment{Name: "kill-bootnodes", Action: "pod-kill", Target: "bootnodes", Duration: "5m"}
+
+	if spec, err = chaosWorkflowSpec(experiment, time.Minute); err != nil {
+		t.Fatal(err)
+	}
+
+	podKill := spec["templates"].([]interface{})[2].(map[string]interface{})
+
+	if _, ok := podKill["deadline"]; ok || podKill["podChaos"] == nil {
+		t.Errorf("pod kill template is %v, expected a podChaos without deadline", podKill)
+	}
+
+	if _, err = chaosWorkflowSpec(ChaosExperiment{Name: "x", Action: "unknown", Target: "miners"}, time.Minute); err == nil {
+		t.Error("unknown action was accepted")
+	}
+}
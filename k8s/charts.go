@@ -0,0 +1,179 @@
+package k8s
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sort"
+
+	helm "github.com/mittwald/go-helm-client"
+	"helm.sh/helm/v3/pkg/action"
+	"helm.sh/helm/v3/pkg/repo"
+	"sigs.k8s.io/yaml"
+)
+
+// releaseCharts maps the releases whose name isn't the name of their entry
+// in the chart catalog
+var releaseCharts = map[string]string{
+	"loki":        "loki-stack",
+	"prometheus":  "kube-prometheus-stack",
+	"filebeat-ws": "filebeat",
+}
+
+// InstalledChart is a helm release of a network and its chart
+type InstalledChart struct {
+	Release        string
+	Namespace      string
+	Chart          string
+	Version        string
+	AppVersion     string
+	Status         string
+	CatalogVersion string
+	local          bool
+}
+
+// Pinned is true if the catalog has a version for the chart
+func (c InstalledChart) Pinned() bool {
+	return !c.local && c.CatalogVersion != "" && c.CatalogVersion != "latest"
+}
+
+// installChart installs or upgrades a release of a chart of the catalog. The
+// repo of the chart is added unless it's a local chart, and the values of
+// the catalog are merged over the values of spec.
+func installChart(client helm.Client, name string, spec *helm.ChartSpec) error {
+	chart := config.Chart(name)
+
+	if chart.Chart == "" {
+		return fmt.Errorf("chart %s is not in the chart catalog", name)
+	}
+
+	spec.ChartName = chart.Chart
+	spec.Version = chart.Version
+
+	if !chart.Local() {
+		chartRepo := repo.Entry{
+			Name: name,
+			URL:  chart.Repo,
+		}
+
+		if err := client.AddOrUpdateChartRepo(chartRepo); err != nil {
+			return err
+		}
+
+		spec.ChartName = name + "/" + chart.Chart
+	}
+
+	if chart.Values != "" {
+		values, err := mergeValues(spec.ValuesYaml, chart.Values)
+
+		if err != nil {
+			return fmt.Errorf("values of chart %s: %w", name, err)
+		}
+
+		spec.ValuesYaml = values
+	}
+
+	return client.InstallOrUpgradeChart(context.Background(), spec)
+}
+
+// mergeValues merges the override YAML over the values YAML
+func mergeValues(values string, override string) (string, error) {
+	base := map[string]interface{}{}
+
+	if err := yaml.Unmarshal([]byte(values), &base); err != nil {
+		return "", err
+	}
+
+	overrides := map[string]interface{}{}
+
+	if err := yaml.Unmarshal([]byte(override), &overrides); err != nil {
+		return "", err
+	}
+
+	merged, err := yaml.Marshal(mergeMaps(base, overrides))
+
+	if err != nil {
+		return "", err
+	}
+
+	return string(merged), nil
+}
+
+func mergeMaps(base map[string]interface{}, overrides map[string]interface{}) map[string]interface{} {
+	for key, value := range overrides {
+		baseMap, baseIsMap := base[key].(map[string]interface{})
+		valueMap, valueIsMap := value.(map[string]interface{})
+
+		if baseIsMap && valueIsMap {
+			base[key] = mergeMaps(baseMap, valueMap)
+		} else {
+			base[key] = value
+		}
+	}
+
+	return base
+}
+
+// ListInstalledCharts returns the helm releases of all namespaces with the
+// version of their chart in the catalog
+func (k8s *Kubernetes) ListInstalledCharts() ([]InstalledChart, error) {
+	actionConfig := new(action.Configuration)
+
+	// an empty namespace lists the releases of all namespaces
+	clientGetter := helm.NewRESTClientGetter("", nil, k8s.RestConfig)
+
+	if err := actionConfig.Init(clientGetter, "", os.Getenv("HELM_DRIVER"), func(format string, v ...interface{}) {}); err != nil {
+		return nil, err
+	}
+
+	list := action.NewList(actionConfig)
+	list.All = true
+	list.AllNamespaces = true
+
+	releases, err := list.Run()
+
+	if err != nil {
+		return nil, err
+	}
+
+	charts := []InstalledChart{}
+
+	for _, release := range releases {
+		name, ok := releaseCharts[release.Name]
+
+		if !ok {
+			name = release.Name
+		}
+
+		catalogVersion := ""
+		chart := config.Chart(name)
+
+		switch {
+		case chart.Chart == "":
+			// not deployed by spacecraft
+		case chart.Local():
+			catalogVersion = chart.Chart
+		case chart.Version == "":
+			catalogVersion = "latest"
+		default:
+			catalogVersion = chart.Version
+		}
+
+		charts = append(charts, InstalledChart{
+			Release:        release.Name,
+			Namespace:      release.Namespace,
+			Chart:          release.Chart.Metadata.Name,
+			Version:        release.Chart.Metadata.Version,
+			AppVersion:     release.Chart.Metadata.AppVersion,
+			Status:         release.Info.Status.String(),
+			CatalogVersion: catalogVersion,
+			local:          chart.Chart != "" && chart.Local(),
+		})
+	}
+
+	sort.Slice(charts, func(i, j int) bool {
+		return charts[i].Namespace+"/"+charts[i].Release < charts[j].Namespace+"/"+charts[j].Release
+	})
+
+	return charts, nil
+}
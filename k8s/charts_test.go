@@ -0,0 +1,108 @@
+package k8s
+
+import (
+	"reflect"
+	"testing"
+
+	cfg "github.com/spacemeshos/go-spacecraft/config"
+	"sigs.k8s.io/yaml"
+)
+
+func TestMergeValues(t *testing.T) {
+	tests := []struct {
+		name     string
+		values   string
+		override string
+		expected string
+		valid    bool
+	}{
+		{
+			name:     "nested values are merged",
+			values:   "controller:\n  replicaCount: 1\n  service:\n    type: LoadBalancer\n",
+			override: "controller:\n  service:\n    externalTrafficPolicy: Local\n",
+			expected: "controller:\n  replicaCount: 1\n  service:\n    type: LoadBalancer\n    externalTrafficPolicy: Local\n",
+			valid:    true,
+		},
+		{
+			name:     "scalars and lists are replaced",
+			values:   "replicas: 3\nargs: [a, b]\n",
+			override: "replicas: 1\nargs: [c]\n",
+			expected: "replicas: 1\nargs: [c]\n",
+			valid:    true,
+		},
+		{
+			name:     "a map replaces a scalar",
+			values:   "resources: none\n",
+			override: "resources:\n  cpu: 1\n",
+			expected: "resources:\n  cpu: 1\n",
+			valid:    true,
+		},
+		{
+			name:     "empty override",
+			values:   "replicas: 3\n",
+			override: "",
+			expected: "replicas: 3\n",
+			valid:    true,
+		},
+		{
+			name:     "invalid override",
+			values:   "replicas: 3\n",
+			override: "replicas: [\n",
+		},
+	}
+
+	for _, test := range tests {
+		merged, err := mergeValues(test.values, test.override)
+
+		if !test.valid {
+			if err == nil {
+				t.Errorf("%s: merged %s", test.name, merged)
+			}
+
+			continue
+		}
+
+		if err != nil {
+			t.Errorf("%s: %v", test.name, err)
+			continue
+		}
+
+		got := map[string]interface{}{}
+		expected := map[string]interface{}{}
+
+		if err = yaml.Unmarshal([]byte(merged), &got); err != nil {
+			t.Fatal(err)
+		}
+
+		if err = yaml.Unmarshal([]byte(test.expected), &expected); err != nil {
+			t.Fatal(err)
+		}
+
+		if !reflect.DeepEqual(got, expected) {
+			t.Errorf("%s: merged %v, expected %v", test.name, got, expected)
+		}
+	}
+}
+
+func TestElasticsearchVersion(t *testing.T) {
+	saved := *config
+	defer func() { *config = saved }()
+
+	tests := []struct {
+		name     string
+		charts   cfg.ChartCatalog
+		expected string
+	}{
+		{"default chart", nil, cfg.DefaultCharts["elasticsearch"].Version},
+		{"pinned version", cfg.ChartCatalog{"elasticsearch": {Version: "7.16.3"}}, "7.16.3"},
+		{"local chart", cfg.ChartCatalog{"elasticsearch": {Chart: "./charts/elasticsearch"}}, cfg.DefaultCharts["elasticsearch"].Version},
+	}
+
+	for _, test := range tests {
+		config.Charts = test.charts
+
+		if version := elasticsearchVersion(); version != test.expected {
+			t.Errorf("%s: elasticsearchVersion() = %s, expected %s", test.name, version, test.expected)
+		}
+	}
+}
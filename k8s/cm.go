@@ -3,7 +3,6 @@ package k8s
 import (
 	"context"
 
-	"helm.sh/helm/v3/pkg/repo"
 	apiv1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 
@@ -37,22 +36,11 @@ func (k8s *Kubernetes) DeployChaosMesh() error {
 		return err
 	}
 
-	chartRepo := repo.Entry{
-		Name: "chaos-mesh",
-		URL:  "https://charts.chaos-mesh.org",
-	}
-
-	if err := client.AddOrUpdateChartRepo(chartRepo); err != nil {
-		return err
-	}
-
 	ingressSpec := helm.ChartSpec{
 		ReleaseName: "chaos-mesh",
-		ChartName:   "chaos-mesh/chaos-mesh",
 		Namespace:   "chaos-testing",
 		Wait:        true,
 		Force:       true,
-		Version:     config.ChaosMeshVersion,
 		ValuesYaml: sanitizeYaml(`
 			chaosDaemon:
 				runtime: containerd
@@ -60,7 +48,7 @@ func (k8s *Kubernetes) DeployChaosMesh() error {
 		`),
 	}
 
-	if err = client.InstallOrUpgradeChart(context.Background(), &ingressSpec); err != nil {
+	if err = installChart(client, "chaos-mesh", &ingressSpec); err != nil {
 		return err
 	}
 
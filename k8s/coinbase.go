@@ -0,0 +1,217 @@
+package k8s
+
+import (
+	"bytes"
+	"context"
+	"crypto/ed25519"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/accounts/keystore"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"golang.org/x/crypto/pbkdf2"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// CoinbaseKeyCurve is the curve of the coinbase keys. It's stored in the
+// keystore and the coinbase secrets because the keys of networks created
+// before were secp256k1 keys, which would silently give different addresses
+// if they were read as ed25519 keys.
+const CoinbaseKeyCurve = "ed25519"
+
+// LegacyCoinbaseKeyCurve is the curve of the coinbase secrets and keystores
+// without a curve
+const LegacyCoinbaseKeyCurve = "secp256k1"
+
+type CoinbaseKeystore struct {
+	Network string              `json:"network"`
+	Curve   string              `json:"curve"`
+	Crypto  keystore.CryptoJSON `json:"crypto"`
+}
+
+// checkCoinbaseCurve returns an error if the keys of source aren't ed25519 keys
+func checkCoinbaseCurve(source string, curve string) error {
+	if curve == CoinbaseKeyCurve {
+		return nil
+	}
+
+	if curve == "" {
+		curve = LegacyCoinbaseKeyCurve
+	}
+
+	return errors.New(source + " has " + curve + " coinbase keys, only " + CoinbaseKeyCurve + " keys are supported")
+}
+
+// mnemonicSeed derives the seed of a mnemonic the same way as BIP-39
+func mnemonicSeed(mnemonic string) []byte {
+	normalized := strings.Join(strings.Fields(mnemonic), " ")
+	return pbkdf2.Key([]byte(normalized), []byte("mnemonic"), 2048, 64, sha512.New)
+}
+
+// DeriveCoinbaseKey derives the coinbase key of a miner from the mnemonic.
+// The same mnemonic, network name and miner number always produce the same key.
+func DeriveCoinbaseKey(mnemonic string, networkName string, minerNumber string) (ed25519.PrivateKey, error) {
+	if strings.TrimSpace(mnemonic) == "" {
+		return nil, errors.New("mnemonic is empty")
+	}
+
+	mac := hmac.New(sha256.New, mnemonicSeed(mnemonic))
+	mac.Write([]byte(networkName + "/miner-" + minerNumber))
+
+	return ed25519.NewKeyFromSeed(mac.Sum(nil)), nil
+}
+
+// ParseCoinbaseKey parses a hex encoded ed25519 private key. Only the full
+// key with the public key is accepted, so a 32 byte secp256k1 key can't be
+// mistaken for an ed25519 seed.
+func ParseCoinbaseKey(key string) (ed25519.PrivateKey, error) {
+	data, err := hex.DecodeString(strings.TrimPrefix(key, "0x"))
+
+	if err != nil {
+		return nil, err
+	}
+
+	if len(data) != ed25519.PrivateKeySize {
+		return nil, errors.New("invalid coinbase key length, expected an ed25519 private key")
+	}
+
+	privateKey := ed25519.NewKeyFromSeed(data[:ed25519.SeedSize])
+
+	if !bytes.Equal(privateKey, data) {
+		return nil, errors.New("invalid ed25519 coinbase key")
+	}
+
+	return privateKey, nil
+}
+
+// coinbaseKey returns the hex encoded ed25519 key pair of the miner. Spacemesh
+// derives the coinbase address from the last 20 bytes of the public key and
+// signs transactions using ed25519, so the keys can be used to spend rewards.
+func (k8s *Kubernetes) coinbaseKey(minerNumber string) (string, string, error) {
+	var privateKey ed25519.PrivateKey
+	var err error
+
+	if importedKey, ok := k8s.CoinbaseKeys["miner-"+minerNumber]; ok {
+		privateKey, err = ParseCoinbaseKey(importedKey)
+	} else if config.CoinbaseMnemonic != "" {
+		privateKey, err = DeriveCoinbaseKey(config.CoinbaseMnemonic, config.NetworkName, minerNumber)
+	} else {
+		_, privateKey, err = ed25519.GenerateKey(rand.Reader)
+	}
+
+	if err != nil {
+		return "", "", err
+	}
+
+	publicKey := privateKey.Public().(ed25519.PublicKey)
+
+	return hexutil.Encode(privateKey), hexutil.Encode(publicKey), nil
+}
+
+// GetCoinbaseKeys returns the private coinbase keys of all managed miners by
+// curve and miner name. The secrets of networks created by earlier versions
+// have secp256k1 keys and no curve.
+func (k8s *Kubernetes) GetCoinbaseKeys() (map[string]map[string]string, error) {
+	secretsClient := k8s.Client.CoreV1().Secrets("default")
+	secrets, err := secretsClient.List(context.Background(), metav1.ListOptions{})
+
+	if err != nil {
+		return nil, err
+	}
+
+	keys := map[string]map[string]string{}
+
+	for _, secret := range secrets.Items {
+		if !strings.HasSuffix(secret.Name, "-coinbase") {
+			continue
+		}
+
+		val, ok := secret.Data["privateKey"]
+
+		if !ok {
+			continue
+		}
+
+		curve := string(secret.Data["curve"])
+
+		if curve == "" {
+			curve = LegacyCoinbaseKeyCurve
+		}
+
+		if keys[curve] == nil {
+			keys[curve] = map[string]string{}
+		}
+
+		keys[curve][strings.TrimSuffix(secret.Name, "-coinbase")] = string(val)
+	}
+
+	return keys, nil
+}
+
+// EncryptCoinbaseKeys encrypts coinbase keys of the curve into a keystore
+func EncryptCoinbaseKeys(networkName string, curve string, keys map[string]string, password string) ([]byte, error) {
+	if password == "" {
+		return nil, errors.New("please provide a password to encrypt the keys")
+	}
+
+	data, err := json.Marshal(keys)
+
+	if err != nil {
+		return nil, err
+	}
+
+	cryptoJSON, err := keystore.EncryptDataV3(data, []byte(password), keystore.StandardScryptN, keystore.StandardScryptP)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return json.MarshalIndent(CoinbaseKeystore{Network: networkName, Curve: curve, Crypto: cryptoJSON}, "", "  ")
+}
+
+// ReadCoinbaseKeystore decrypts the coinbase keys exported by exportKeys. The
+// keystore must have been exported from the same network.
+func ReadCoinbaseKeystore(path string, password string) (map[string]string, error) {
+	data, err := ioutil.ReadFile(path)
+
+	if err != nil {
+		return nil, err
+	}
+
+	var file CoinbaseKeystore
+
+	if err = json.Unmarshal(data, &file); err != nil {
+		return nil, err
+	}
+
+	if err = checkCoinbaseCurve("keystore "+path, file.Curve); err != nil {
+		return nil, err
+	}
+
+	// the keys of a network are derived for its name, so the keys of another
+	// network would reuse its coinbase accounts
+	if file.Network != config.NetworkName {
+		return nil, errors.New("keystore " + path + " has the keys of network " + file.Network + ", not " + config.NetworkName)
+	}
+
+	plain, err := keystore.DecryptDataV3(file.Crypto, password)
+
+	if err != nil {
+		return nil, err
+	}
+
+	keys := map[string]string{}
+
+	if err = json.Unmarshal(plain, &keys); err != nil {
+		return nil, err
+	}
+
+	return keys, nil
+}
@@ -0,0 +1,211 @@
+package k8s
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"encoding/hex"
+	"encoding/json"
+	"io/ioutil"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+const testMnemonic = "abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon about"
+
+func TestDeriveCoinbaseKey(t *testing.T) {
+	reference, err := DeriveCoinbaseKey(testMnemonic, "devnet-1", "1")
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name        string
+		mnemonic    string
+		networkName string
+		minerNumber string
+		same        bool
+	}{
+		{"same inputs", testMnemonic, "devnet-1", "1", true},
+		{"extra whitespace", "  abandon abandon abandon abandon abandon abandon\tabandon abandon abandon abandon abandon  about ", "devnet-1", "1", true},
+		{"other miner", testMnemonic, "devnet-1", "2", false},
+		{"other network", testMnemonic, "devnet-2", "1", false},
+		{"other mnemonic", "zoo zoo zoo zoo zoo zoo zoo zoo zoo zoo zoo wrong", "devnet-1", "1", false},
+	}
+
+	for _, test := range tests {
+		key, err := DeriveCoinbaseKey(test.mnemonic, test.networkName, test.minerNumber)
+
+		if err != nil {
+			t.Errorf("%s: %v", test.name, err)
+			continue
+		}
+
+		if len(key) != ed25519.PrivateKeySize {
+			t.Errorf("%s: key has %d bytes", test.name, len(key))
+		}
+
+		if bytes.Equal(key, reference) != test.same {
+			t.Errorf("%s: key equal to the reference key is %v, expected %v", test.name, !test.same, test.same)
+		}
+	}
+
+	if _, err = DeriveCoinbaseKey(" ", "devnet-1", "1"); err == nil {
+		t.Error("an empty mnemonic was accepted")
+	}
+}
+
+func TestParseCoinbaseKey(t *testing.T) {
+	key, err := DeriveCoinbaseKey(testMnemonic, "devnet-1", "1")
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	otherKey, err := DeriveCoinbaseKey(testMnemonic, "devnet-1", "2")
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	encoded := hex.EncodeToString(key)
+	seed := hex.EncodeToString(key.Seed())
+	mismatched := seed + hex.EncodeToString(otherKey.Public().(ed25519.PublicKey))
+
+	tests := []struct {
+		name  string
+		key   string
+		valid bool
+	}{
+		{"full key", encoded, true},
+		{"0x prefix", "0x" + encoded, true},
+		{"seed only", seed, false},
+		{"public key of another key", mismatched, false},
+		{"not hex", "zz" + encoded[2:], false},
+		{"empty", "", false},
+	}
+
+	for _, test := range tests {
+		parsed, err := ParseCoinbaseKey(test.key)
+
+		if !test.valid {
+			if err == nil {
+				t.Errorf("%s: key was accepted", test.name)
+			}
+
+			continue
+		}
+
+		if err != nil {
+			t.Errorf("%s: %v", test.name, err)
+			continue
+		}
+
+		if !bytes.Equal(parsed, key) {
+			t.Errorf("%s: parsed %x, expected %x", test.name, parsed, key)
+		}
+	}
+}
+
+func TestCheckCoinbaseCurve(t *testing.T) {
+	tests := []struct {
+		curve string
+		valid bool
+	}{
+		{CoinbaseKeyCurve, true},
+		{"", false},
+		{LegacyCoinbaseKeyCurve, false},
+	}
+
+	for _, test := range tests {
+		if err := checkCoinbaseCurve("test", test.curve); (err == nil) != test.valid {
+			t.Errorf("checkCoinbaseCurve(%q) = %v, expected valid %v", test.curve, err, test.valid)
+		}
+	}
+}
+
+func TestCoinbaseKeystore(t *testing.T) {
+	savedNetworkName := config.NetworkName
+	defer func() { config.NetworkName = savedNetworkName }()
+
+	keys := map[string]string{"miner-1": "0x01", "miner-2": "0x02"}
+	data, err := EncryptCoinbaseKeys("devnet-1", CoinbaseKeyCurve, keys, "secret")
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	dir := t.TempDir()
+	path := filepath.Join(dir, "keys.json")
+
+	if err = ioutil.WriteFile(path, data, 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	file := CoinbaseKeystore{}
+
+	if err = json.Unmarshal(data, &file); err != nil {
+		t.Fatal(err)
+	}
+
+	file.Curve = ""
+	legacy, err := json.Marshal(file)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	legacyPath := filepath.Join(dir, "legacy.json")
+
+	if err = ioutil.WriteFile(legacyPath, legacy, 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	file.Curve = LegacyCoinbaseKeyCurve
+	exportedLegacy, err := json.Marshal(file)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	exportedLegacyPath := filepath.Join(dir, "keys-secp256k1.json")
+
+	if err = ioutil.WriteFile(exportedLegacyPath, exportedLegacy, 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name        string
+		path        string
+		networkName string
+		valid       bool
+	}{
+		{"same network", path, "devnet-1", true},
+		{"other network", path, "devnet-2", false},
+		{"keys without curve", legacyPath, "devnet-1", false},
+		{"exported secp256k1 keys", exportedLegacyPath, "devnet-1", false},
+	}
+
+	for _, test := range tests {
+		config.NetworkName = test.networkName
+		read, err := ReadCoinbaseKeystore(test.path, "secret")
+
+		if !test.valid {
+			if err == nil {
+				t.Errorf("%s: keystore was accepted", test.name)
+			}
+
+			continue
+		}
+
+		if err != nil {
+			t.Errorf("%s: %v", test.name, err)
+			continue
+		}
+
+		if !reflect.DeepEqual(read, keys) {
+			t.Errorf("%s: read %v, expected %v", test.name, read, keys)
+		}
+	}
+}
@@ -0,0 +1,372 @@
+package k8s
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"net/url"
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/spacemeshos/go-spacecraft/gcp"
+)
+
+// discoveryRetries is how many times an update of the discovery file is
+// retried if it was changed by someone else meanwhile
+const discoveryRetries = 5
+
+// Validate checks the network against the schema of the discovery service
+func (n Network) Validate() error {
+	if n.NetName == "" {
+		return errors.New("netName is empty")
+	}
+
+	if n.NetID <= 0 || n.NetID != math.Trunc(n.NetID) {
+		return fmt.Errorf("%s: netID %v is not a positive integer", n.NetName, n.NetID)
+	}
+
+	urls := map[string]string{
+		"conf":                 n.Conf,
+		"grpcAPI":              n.GrpcApi,
+		"jsonAPI":              n.JsonApi,
+		"explorer":             n.Explorer,
+		"explorerAPI":          n.ExplorerAPI,
+		"explorerConf":         n.ExplorerConf,
+		"dash":                 n.Dash,
+		"dashAPI":              n.DashApi,
+		"smappBaseDownloadUrl": n.SmappBaseDownloadUrl,
+		"nodeBaseDownloadUrl":  n.NodeBaseDownloadUrl,
+	}
+
+	if n.Faucet != "" {
+		urls["faucet"] = n.Faucet
+	}
+
+	for field, value := range urls {
+		u, err := url.Parse(value)
+
+		if err != nil || u.Host == "" {
+			return fmt.Errorf("%s: %s %q is not a URL", n.NetName, field, value)
+		}
+
+		scheme := map[string]bool{"http": true, "https": true}
+
+		if field == "dashAPI" {
+			scheme = map[string]bool{"ws": true, "wss": true}
+		}
+
+		if !scheme[u.Scheme] {
+			return fmt.Errorf("%s: %s %q has an invalid scheme", n.NetName, field, value)
+		}
+	}
+
+	required := map[string]string{
+		"minNodeVersion":     n.MinNodeVersion,
+		"maxNodeVersion":     n.MaxNodeVersion,
+		"minSmappRelease":    n.MinSmappRelease,
+		"latestSmappRelease": n.LatestSmappRelease,
+	}
+
+	for field, value := range required {
+		if value == "" {
+			return fmt.Errorf("%s: %s is empty", n.NetName, field)
+		}
+	}
+
+	return nil
+}
+
+// UnmarshalJSON decodes a network and keeps the unknown fields in Extra
+func (n *Network) UnmarshalJSON(data []byte) error {
+	type network Network
+
+	if err := json.Unmarshal(data, (*network)(n)); err != nil {
+		return err
+	}
+
+	fields := map[string]json.RawMessage{}
+
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return err
+	}
+
+	for _, name := range networkFields() {
+		delete(fields, name)
+	}
+
+	n.Extra = nil
+
+	if len(fields) > 0 {
+		n.Extra = fields
+	}
+
+	return nil
+}
+
+// MarshalJSON encodes a network with the fields of Extra
+func (n Network) MarshalJSON() ([]byte, error) {
+	type network Network
+
+	data, err := json.Marshal(network(n))
+
+	if err != nil || len(n.Extra) == 0 {
+		return data, err
+	}
+
+	fields := map[string]json.RawMessage{}
+
+	if err = json.Unmarshal(data, &fields); err != nil {
+		return nil, err
+	}
+
+	for name, value := range n.Extra {
+		if _, ok := fields[name]; !ok {
+			fields[name] = value
+		}
+	}
+
+	return json.Marshal(fields)
+}
+
+// networkFields are the JSON names of the fields of Network
+func networkFields() []string {
+	names := []string{}
+	networkType := reflect.TypeOf(Network{})
+
+	for i := 0; i < networkType.NumField(); i++ {
+		name := strings.Split(networkType.Field(i).Tag.Get("json"), ",")[0]
+
+		if name != "" && name != "-" {
+			names = append(names, name)
+		}
+	}
+
+	return names
+}
+
+// discoveryFile is the content of the discovery file. The entries which
+// can't be decoded into a Network are kept as they are in invalid.
+type discoveryFile struct {
+	networks []Network
+	invalid  []json.RawMessage
+}
+
+// parseDiscovery decodes the discovery file. It isn't validated, so that an
+// old or hand edited entry doesn't block the changes of other networks.
+func parseDiscovery(content string) (*discoveryFile, error) {
+	file := &discoveryFile{networks: []Network{}}
+
+	if strings.TrimSpace(content) == "" {
+		return file, nil
+	}
+
+	entries := []json.RawMessage{}
+
+	if err := json.Unmarshal([]byte(content), &entries); err != nil {
+		return nil, fmt.Errorf("invalid discovery file: %w", err)
+	}
+
+	names := map[string]bool{}
+
+	for _, entry := range entries {
+		network := Network{}
+
+		if err := json.Unmarshal(entry, &network); err != nil || network.NetName == "" || names[network.NetName] {
+			fmt.Println("keeping invalid discovery entry as it is: " + string(entry))
+			file.invalid = append(file.invalid, entry)
+			continue
+		}
+
+		names[network.NetName] = true
+		file.networks = append(file.networks, network)
+	}
+
+	return file, nil
+}
+
+// ParseNetwork decodes and validates a network of the discovery service
+func ParseNetwork(content string) (Network, error) {
+	network := Network{}
+
+	if err := json.Unmarshal([]byte(content), &network); err != nil {
+		return network, fmt.Errorf("invalid network: %w", err)
+	}
+
+	return network, network.Validate()
+}
+
+// ListDiscovery returns the networks of the discovery service
+func ListDiscovery() ([]Network, error) {
+	content, _, err := gcp.ReadWSConfig()
+
+	if err != nil {
+		return nil, err
+	}
+
+	file, err := parseDiscovery(content)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return file.networks, nil
+}
+
+// validateChanges validates the networks which update added or changed
+func validateChanges(before []Network, after []Network) error {
+	previous := map[string]Network{}
+
+	for _, network := range before {
+		previous[network.NetName] = network
+	}
+
+	names := map[string]bool{}
+
+	for _, network := range after {
+		if names[network.NetName] {
+			return errors.New("network " + network.NetName + " is in the discovery service more than once")
+		}
+
+		names[network.NetName] = true
+
+		if old, ok := previous[network.NetName]; ok && reflect.DeepEqual(old, network) {
+			continue
+		}
+
+		if err := network.Validate(); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// UpdateDiscovery applies update to the networks of the discovery service.
+// The file is only written if it wasn't changed since it was read, otherwise
+// update is applied again to the new content.
+func UpdateDiscovery(update func(networks []Network) ([]Network, error)) error {
+	for attempt := 1; ; attempt++ {
+		content, generation, err := gcp.ReadWSConfig()
+
+		if err != nil {
+			return err
+		}
+
+		file, err := parseDiscovery(content)
+
+		if err != nil {
+			return err
+		}
+
+		before := make([]Network, len(file.networks))
+		copy(before, file.networks)
+
+		networks, err := update(file.networks)
+
+		if err != nil {
+			return err
+		}
+
+		if err = validateChanges(before, networks); err != nil {
+			return err
+		}
+
+		entries := []interface{}{}
+
+		for _, network := range networks {
+			entries = append(entries, network)
+		}
+
+		names := map[string]bool{}
+
+		for _, network := range networks {
+			names[network.NetName] = true
+		}
+
+		// an invalid entry is dropped if a network with its name replaced it
+		for _, entry := range file.invalid {
+			named := struct {
+				NetName string `json:"netName"`
+			}{}
+
+			if json.Unmarshal(entry, &named) == nil && names[named.NetName] {
+				continue
+			}
+
+			entries = append(entries, entry)
+		}
+
+		data, err := json.MarshalIndent(entries, "", "  ")
+
+		if err != nil {
+			return err
+		}
+
+		err = gcp.UploadWSConfig(string(data), generation)
+
+		if err != gcp.ErrDiscoveryChanged || attempt == discoveryRetries {
+			return err
+		}
+
+		fmt.Println("discovery file was changed meanwhile, retrying")
+		time.Sleep(time.Duration(attempt) * time.Second)
+	}
+}
+
+// UpdateNetworkInDiscovery applies update to a network of the discovery
+// service
+func UpdateNetworkInDiscovery(name string, update func(network *Network)) error {
+	return UpdateDiscovery(func(networks []Network) ([]Network, error) {
+		for i := range networks {
+			if networks[i].NetName == name {
+				update(&networks[i])
+
+				return networks, nil
+			}
+		}
+
+		return nil, errors.New("network " + name + " is not in the discovery service")
+	})
+}
+
+// RemoveNetworkFromDiscovery removes a network from the discovery service, it
+// isn't an error if the network isn't there
+func RemoveNetworkFromDiscovery(name string) error {
+	return UpdateDiscovery(func(networks []Network) ([]Network, error) {
+		remaining := []Network{}
+
+		for _, network := range networks {
+			if network.NetName != name {
+				remaining = append(remaining, network)
+			}
+		}
+
+		return remaining, nil
+	})
+}
+
+// imageRepoTag splits a docker image into the repository and tag of the
+// discovery service
+func imageRepoTag(image string) (string, string) {
+	imageSplit := strings.Split(image, ":")
+
+	if len(imageSplit) == 2 {
+		return imageSplit[0], imageSplit[1]
+	}
+
+	return "", "latest"
+}
+
+// UpdateNodeVersionInDiscovery sets the node versions of the network in the
+// discovery service to image after all miners were upgraded
+func UpdateNodeVersionInDiscovery(image string) error {
+	repository, tag := imageRepoTag(image)
+
+	return UpdateNetworkInDiscovery(config.NetworkName, func(network *Network) {
+		network.Repository = repository
+		network.MinNodeVersion = tag
+		network.MaxNodeVersion = tag
+	})
+}
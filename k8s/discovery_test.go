@@ -0,0 +1,181 @@
+package k8s
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+func testNetwork(name string) Network {
+	return Network{
+		NetName:              name,
+		NetID:                1,
+		Conf:                 "https://storage.googleapis.com/spacecraft-data/" + name + "-archive/config.json",
+		GrpcApi:              "https://api-" + name + ".spacemesh.io/",
+		JsonApi:              "https://api-json-" + name + ".spacemesh.io/",
+		Explorer:             "https://explorer-" + name + ".spacemesh.io/",
+		ExplorerAPI:          "https://explorer-api-" + name + ".spacemesh.io/",
+		ExplorerConf:         "https://explorer-api-" + name + ".spacemesh.io/network-info",
+		Dash:                 "https://dash-" + name + ".spacemesh.io/",
+		DashApi:              "wss://dash-api-" + name + ".spacemesh.io/ws",
+		MinNodeVersion:       "v0.2.1",
+		MaxNodeVersion:       "v0.2.1",
+		MinSmappRelease:      "0.1.20",
+		LatestSmappRelease:   "0.1.20",
+		SmappBaseDownloadUrl: "https://storage.googleapis.com/smapp/",
+		NodeBaseDownloadUrl:  "https://storage.googleapis.com/go-spacemesh-release-builds/",
+	}
+}
+
+func TestNetworkValidate(t *testing.T) {
+	tests := []struct {
+		name   string
+		update func(network *Network)
+		valid  bool
+	}{
+		{"valid network", func(network *Network) {}, true},
+		{"with faucet", func(network *Network) { network.Faucet = "https://faucet-devnet.spacemesh.io/" }, true},
+		{"empty name", func(network *Network) { network.NetName = "" }, false},
+		{"zero netID", func(network *Network) { network.NetID = 0 }, false},
+		{"fractional netID", func(network *Network) { network.NetID = 1.5 }, false},
+		{"URL without host", func(network *Network) { network.GrpcApi = "api-devnet" }, false},
+		{"empty URL", func(network *Network) { network.Explorer = "" }, false},
+		{"invalid scheme", func(network *Network) { network.Conf = "ftp://example.com/config.json" }, false},
+		{"http dash API", func(network *Network) { network.DashApi = "https://dash-api-devnet.spacemesh.io/ws" }, false},
+		{"invalid faucet", func(network *Network) { network.Faucet = "faucet" }, false},
+		{"empty node version", func(network *Network) { network.MinNodeVersion = "" }, false},
+		{"empty smapp release", func(network *Network) { network.LatestSmappRelease = "" }, false},
+	}
+
+	for _, test := range tests {
+		network := testNetwork("devnet")
+		test.update(&network)
+
+		if err := network.Validate(); (err == nil) != test.valid {
+			t.Errorf("%s: Validate() = %v, expected valid %v", test.name, err, test.valid)
+		}
+	}
+}
+
+func TestNetworkExtraFields(t *testing.T) {
+	network := testNetwork("devnet")
+	data, err := json.Marshal(network)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	fields := map[string]json.RawMessage{}
+
+	if err = json.Unmarshal(data, &fields); err != nil {
+		t.Fatal(err)
+	}
+
+	fields["genesisTime"] = json.RawMessage(`"2021-05-01T00:00:00Z"`)
+	fields["explorerVersion"] = json.RawMessage(`"v1.0.0"`)
+
+	if data, err = json.Marshal(fields); err != nil {
+		t.Fatal(err)
+	}
+
+	decoded := Network{}
+
+	if err = json.Unmarshal(data, &decoded); err != nil {
+		t.Fatal(err)
+	}
+
+	if decoded.ExplorerVersion != "v1.0.0" {
+		t.Errorf("explorerVersion is %q, expected v1.0.0", decoded.ExplorerVersion)
+	}
+
+	expectedExtra := map[string]json.RawMessage{"genesisTime": json.RawMessage(`"2021-05-01T00:00:00Z"`)}
+
+	if !reflect.DeepEqual(decoded.Extra, expectedExtra) {
+		t.Errorf("extra fields are %s, expected %s", decoded.Extra, expectedExtra)
+	}
+
+	encoded, err := json.Marshal(decoded)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	roundTrip := map[string]interface{}{}
+	expected := map[string]interface{}{}
+
+	if err = json.Unmarshal(encoded, &roundTrip); err != nil {
+		t.Fatal(err)
+	}
+
+	if err = json.Unmarshal(data, &expected); err != nil {
+		t.Fatal(err)
+	}
+
+	if !reflect.DeepEqual(roundTrip, expected) {
+		t.Errorf("encoded %s, expected %s", encoded, data)
+	}
+}
+
+func TestParseDiscovery(t *testing.T) {
+	valid, err := json.Marshal(testNetwork("devnet-1"))
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	content := "[" + string(valid) + `, {"netName": "devnet-2", "netID": "two"}, {"netID": 3}, ` + string(valid) + "]"
+	file, err := parseDiscovery(content)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(file.networks) != 1 || file.networks[0].NetName != "devnet-1" {
+		t.Errorf("networks are %v, expected devnet-1", file.networks)
+	}
+
+	if len(file.invalid) != 3 {
+		t.Errorf("%d invalid entries, expected 3", len(file.invalid))
+	}
+
+	for _, content := range []string{"", "  \n"} {
+		if file, err = parseDiscovery(content); err != nil || len(file.networks) != 0 {
+			t.Errorf("empty discovery file %q: %v, %v", content, file, err)
+		}
+	}
+
+	if _, err = parseDiscovery("{}"); err == nil {
+		t.Error("discovery file which is no list was accepted")
+	}
+}
+
+func TestValidateChanges(t *testing.T) {
+	legacy := testNetwork("legacy")
+	legacy.MinSmappRelease = ""
+
+	changed := testNetwork("devnet")
+	changed.DashVersion = "v2"
+
+	invalid := testNetwork("devnet")
+	invalid.MaxNodeVersion = ""
+
+	before := []Network{legacy, testNetwork("devnet")}
+
+	tests := []struct {
+		name  string
+		after []Network
+		valid bool
+	}{
+		{"unchanged invalid entry is kept", []Network{legacy, changed}, true},
+		{"new network", []Network{legacy, testNetwork("devnet"), testNetwork("testnet")}, true},
+		{"removed network", []Network{testNetwork("devnet")}, true},
+		{"changed network is invalid", []Network{legacy, invalid}, false},
+		{"duplicate network", []Network{legacy, testNetwork("devnet"), changed}, false},
+	}
+
+	for _, test := range tests {
+		if err := validateChanges(before, test.after); (err == nil) != test.valid {
+			t.Errorf("%s: validateChanges() = %v, expected valid %v", test.name, err, test.valid)
+		}
+	}
+}
@@ -0,0 +1,162 @@
+package k8s
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/spacemeshos/go-spacecraft/dns"
+	apiv1 "k8s.io/api/core/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+type serviceRecord struct {
+	service string
+	proxied bool
+}
+
+// dnsRecords are the records of every component of a network, they point to
+// the ingress of the component. Cloudflare can't proxy GRPC so the API
+// records are not proxied.
+var dnsRecords = map[string][]serviceRecord{
+	"ws":      {{"api", false}, {"api-json", false}, {"dash-api", true}, {"explorer-api", true}},
+	"faucet":  {{"faucet", true}},
+	"kibana":  {{"kibana", true}},
+	"metrics": {{"grafana", true}, {"prometheus", true}},
+	"loki":    {{"grafana", true}},
+}
+
+// dnsSettings is the config map the DNS settings of a network are saved in,
+// so that later commands manage the records in the same zone
+const dnsSettings = "spacecraft-dns"
+
+// SaveDNSSettings saves the DNS settings of the network in the cluster
+func (k8s *Kubernetes) SaveDNSSettings() error {
+	data := map[string]string{}
+
+	for option, value := range dns.Settings() {
+		data[option] = *value
+	}
+
+	configMap := &apiv1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: dnsSettings,
+		},
+		Data: data,
+	}
+
+	configMapClient := k8s.Client.CoreV1().ConfigMaps("default")
+	_, err := configMapClient.Create(context.Background(), configMap, metav1.CreateOptions{})
+
+	if k8serrors.IsAlreadyExists(err) {
+		_, err = configMapClient.Update(context.Background(), configMap, metav1.UpdateOptions{})
+	}
+
+	return err
+}
+
+// LoadDNSSettings replaces the DNS options with the settings the network was
+// created with. Networks created before the settings were saved keep the
+// options.
+func (k8s *Kubernetes) LoadDNSSettings() error {
+	configMap, err := k8s.Client.CoreV1().ConfigMaps("default").Get(context.Background(), dnsSettings, metav1.GetOptions{})
+
+	if k8serrors.IsNotFound(err) {
+		return nil
+	}
+
+	if err != nil {
+		return err
+	}
+
+	for option, value := range dns.Settings() {
+		if saved, ok := configMap.Data[option]; ok {
+			*value = saved
+		}
+	}
+
+	return nil
+}
+
+// Domain returns the domain of a service of the network, e.g., kibana
+func Domain(service string) string {
+	return dns.Domain(service, config.NetworkName)
+}
+
+// createDNSRecords waits for the IP of the ingress of the component and
+// creates its records
+func (k8s *Kubernetes) createDNSRecords(component string, namespace string, ingressName string) error {
+	if !dns.Enabled() {
+		return nil
+	}
+
+	provider, err := dns.NewProvider()
+
+	if err != nil {
+		return err
+	}
+
+	ingressClient := k8s.Client.ExtensionsV1beta1().Ingresses(namespace)
+
+	for range time.Tick(5 * time.Second) {
+		ingress, err := ingressClient.Get(context.Background(), ingressName, metav1.GetOptions{})
+
+		if err != nil {
+			return err
+		}
+
+		fmt.Println("waiting for " + component + " ingress")
+
+		if len(ingress.Status.LoadBalancer.Ingress) == 1 {
+			for _, record := range dnsRecords[component] {
+				err = provider.CreateRecord(dns.Record{
+					Name:    Domain(record.service),
+					IP:      ingress.Status.LoadBalancer.Ingress[0].IP,
+					Proxied: record.proxied,
+				})
+
+				if err != nil {
+					return err
+				}
+			}
+
+			break
+		}
+	}
+
+	return nil
+}
+
+// DeleteDNSRecords deletes the records of the components of the network
+func (k8s *Kubernetes) DeleteDNSRecords(components ...string) error {
+	if !dns.Enabled() {
+		return nil
+	}
+
+	provider, err := dns.NewProvider()
+
+	if err != nil {
+		return err
+	}
+
+	deleted := map[string]bool{}
+
+	for _, component := range components {
+		for _, record := range dnsRecords[component] {
+			name := Domain(record.service)
+
+			if deleted[name] {
+				continue
+			}
+
+			if err = provider.DeleteRecord(name); err != nil {
+				return err
+			}
+
+			deleted[name] = true
+		}
+	}
+
+	return nil
+}
@@ -1,27 +1,19 @@
 package k8s
 
 import (
-	"bytes"
 	"context"
 	"encoding/base64"
-	"errors"
 	"fmt"
-	"io"
 	"io/ioutil"
-	"mime/multipart"
 	"net/http"
-	"os"
-	"path/filepath"
 	"strings"
 
 	apiv1 "k8s.io/api/core/v1"
 
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 
-	cloudflare "github.com/cloudflare/cloudflare-go"
 	helm "github.com/mittwald/go-helm-client"
 	"github.com/sethvargo/go-password/password"
-	"helm.sh/helm/v3/pkg/repo"
 )
 
 func sanitizeYaml(yaml string) string {
@@ -33,7 +25,13 @@ func basicAuth(username, password string) string {
 	return base64.StdEncoding.EncodeToString([]byte(auth))
 }
 
+// DeployELK deploys elasticsearch, kibana and filebeat. If an external
+// elasticsearch is configured only filebeat is deployed.
 func (k8s *Kubernetes) DeployELK() error {
+	if config.ESURL != "" {
+		return k8s.deployFilebeatForExternalES()
+	}
+
 	pass, err := password.Generate(32, 10, 0, false, false)
 	if err != nil {
 		return err
@@ -70,37 +68,6 @@ func (k8s *Kubernetes) DeployELK() error {
 		return err
 	}
 
-	chartRepo := repo.Entry{
-		Name: "ingress-nginx",
-		URL:  "https://kubernetes.github.io/ingress-nginx",
-	}
-
-	if err := client.AddOrUpdateChartRepo(chartRepo); err != nil {
-		return err
-	}
-
-	ingressSpec := helm.ChartSpec{
-		ReleaseName: "ingress-nginx",
-		ChartName:   "ingress-nginx/ingress-nginx",
-		Namespace:   "kube-system",
-		Wait:        true,
-		Force:       true,
-		Version:     "3.34.0",
-	}
-
-	if err = client.InstallOrUpgradeChart(context.Background(), &ingressSpec); err != nil {
-		return err
-	}
-
-	chartRepo = repo.Entry{
-		Name: "elastic",
-		URL:  "https://helm.elastic.co",
-	}
-
-	if err := client.AddOrUpdateChartRepo(chartRepo); err != nil {
-		return err
-	}
-
 	certData, err := ioutil.ReadFile(config.ESCert)
 
 	if err != nil {
@@ -127,13 +94,17 @@ func (k8s *Kubernetes) DeployELK() error {
 		clusterHealthCheckParams = ""
 	}
 
+	archiveValues, err := k8s.esArchiveValues()
+
+	if err != nil {
+		return err
+	}
+
 	elasticSearchSpec := helm.ChartSpec{
 		ReleaseName: "elasticsearch",
-		ChartName:   "elastic/elasticsearch",
 		Namespace:   "default",
 		Wait:        true,
 		Force:       true,
-		Version:     "7.15.0",
 		ValuesYaml: sanitizeYaml(fmt.Sprintf(`
 			replicas: %s
 			minimumMasterNodes: %s
@@ -176,22 +147,25 @@ func (k8s *Kubernetes) DeployELK() error {
 						secretKeyRef:
 							name: elastic-credentials
 							key: username
-		`, config.ESReplicas, config.ESMasterNodes, clusterHealthCheckParams, config.ESDiskSize, config.ESCPU, config.ESMemory, config.ESCPU, config.ESMemory, config.ESHeapMemory, config.ESHeapMemory)),
+			%s
+		`, config.ESReplicas, config.ESMasterNodes, clusterHealthCheckParams, config.ESDiskSize, config.ESCPU, config.ESMemory, config.ESCPU, config.ESMemory, config.ESHeapMemory, config.ESHeapMemory, archiveValues)),
 	}
 
-	if err = client.InstallOrUpgradeChart(context.Background(), &elasticSearchSpec); err != nil {
+	if err = installChart(client, "elasticsearch", &elasticSearchSpec); err != nil {
+		return err
+	}
+
+	if err = k8s.createLogsTemplate(); err != nil {
 		return err
 	}
 
 	kibanaSpec := helm.ChartSpec{
 		ReleaseName: "kibana",
-		ChartName:   "elastic/kibana",
 		Namespace:   "default",
 		Wait:        true,
 		Force:       true,
 		SkipCRDs:    true,
 		UpgradeCRDs: false,
-		Version:     "7.15.0",
 		ValuesYaml: sanitizeYaml(fmt.Sprintf(`
 			service:
 				type: NodePort
@@ -218,277 +192,117 @@ func (k8s *Kubernetes) DeployELK() error {
 				annotations:
 					kubernetes.io/ingress.class: nginx
 				hosts:
-					- host: kibana-%s.spacemesh.io
+					- host: %s
 						paths:
 							- path: /
-		`, config.KibanaCPU, config.KibanaMemory, config.KibanaCPU, config.KibanaMemory, config.NetworkName)),
+				tls: %s
+		`, config.KibanaCPU, config.KibanaMemory, config.KibanaCPU, config.KibanaMemory, Domain("kibana"), ingressTLS("kibana-tls", "kibana"))),
 	}
 
-	if err = client.InstallOrUpgradeChart(context.Background(), &kibanaSpec); err != nil {
+	if err = installChart(client, "kibana", &kibanaSpec); err != nil {
 		if !strings.Contains(err.Error(), "failed to replace object") {
 			return err
 		}
 	}
 
+	if err = k8s.createCertificate("default", "kibana-tls", "kibana"); err != nil {
+		return err
+	}
+
+	if err = k8s.installFilebeat(client, &ESConnection{}); err != nil {
+		return err
+	}
+
+	if err = k8s.ImportKibanaSavedObjects(config.KibanaSavedObjects); err != nil {
+		return err
+	}
+
+	return k8s.createDNSRecords("kibana", "default", "kibana-kibana")
+}
+
+// installFilebeat installs filebeat for the miners and poets, and for the web services
+func (k8s *Kubernetes) installFilebeat(client helm.Client, es *ESConnection) error {
 	filebeatSpec := helm.ChartSpec{
 		ReleaseName: "filebeat",
-		ChartName:   "elastic/filebeat",
 		Namespace:   "default",
 		Wait:        true,
 		Force:       true,
-		Version:     "7.15.0",
-		ValuesYaml: sanitizeYaml(`
-			daemonset:
-				extraEnvs:
-				- name: 'ELASTICSEARCH_USERNAME'
-					valueFrom:
-						secretKeyRef:
-							name: elastic-credentials
-							key: username
-				- name: 'ELASTICSEARCH_PASSWORD'
-					valueFrom:
-						secretKeyRef:
-							name: elastic-credentials
-							key: password
-				resources: {}
-				filebeatConfig:
-					filebeat.yml: |
-						processors:
-							- script:
-									lang: javascript
-									id: my_filter
-									source: >
-										function process(event) {
-											var message = event.Get('message')
-											try {
-												var msg = JSON.parse(message)
-												Object.keys(msg).forEach(function(k) {msg[k] = msg[k].toString()})
-												event.Put("name", event.Get('kubernetes.labels.name'))
-												delete msg.T
-												event.Put("sm", JSON.stringify(msg))
-												event.Delete("message")
-											} catch(e) {
-												var message = event.Get('message')
-												var sm = { message: message }
-												event.Delete("message")
-												event.Put("sm", JSON.stringify(sm));
-												event.Put("name", event.Get('kubernetes.labels.name'))
-											}
-										}
-							- drop_fields:
-									fields: ["log", "cloud", "ecs", "agent", "input", "tags", "docker", "container", "host", "kubernetes"]
-							- decode_json_fields:
-									fields: ["sm"]
-									target: "sm"
-									process_array: false
-									max_depth: 2
-									overwrite_keys: true
-									add_error_key: true
-						filebeat:
-							autodiscover.providers:
-							- type: kubernetes
-								templates:
-									- condition.contains:
-											kubernetes.container.name: miner
-										config:
-											- type: container
-												paths:
-													- /var/log/containers/*-${data.kubernetes.container.id}.log
-									- condition.contains:
-											kubernetes.container.name: poet
-										config:
-											- type: container
-												paths:
-													- /var/log/containers/*-${data.kubernetes.container.id}.log
-						output.elasticsearch:
-							host: '${NODE_NAME}'
-							hosts: '${ELASTICSEARCH_HOSTS:elasticsearch-master:9200}'
-							username: "${ELASTICSEARCH_USERNAME}"
-							password: "${ELASTICSEARCH_PASSWORD}"
-							index: "sm-%{+YYYY.MM.dd}"
-							worker: 3
-							bulk_max_size: 1000
-						setup.template.enabled: false
-						setup.ilm.enabled: false
-		`),
-	}
-
-	if err = client.InstallOrUpgradeChart(context.Background(), &filebeatSpec); err != nil {
+		ValuesYaml:  filebeatValues(es, "kubernetes.labels.name", "sm-%{+YYYY.MM.dd}", smAutodiscover),
+	}
+
+	if err := installChart(client, "filebeat", &filebeatSpec); err != nil {
 		return err
 	}
 
 	filebeatSpecWS := helm.ChartSpec{
 		ReleaseName: "filebeat-ws",
-		ChartName:   "elastic/filebeat",
 		Namespace:   "default",
 		Wait:        true,
 		Force:       true,
-		Version:     "7.15.0",
-		ValuesYaml: sanitizeYaml(`
-			daemonset:
-				extraEnvs:
-				- name: 'ELASTICSEARCH_USERNAME'
-					valueFrom:
-						secretKeyRef:
-							name: elastic-credentials
-							key: username
-				- name: 'ELASTICSEARCH_PASSWORD'
-					valueFrom:
-						secretKeyRef:
-							name: elastic-credentials
-							key: password
-				resources: {}
-				filebeatConfig:
-					filebeat.yml: |
-						processors:
-							- script:
-									lang: javascript
-									id: my_filter
-									source: >
-										function process(event) {
-											var message = event.Get('message')
-											try {
-												var msg = JSON.parse(message)
-												Object.keys(msg).forEach(function(k) {msg[k] = msg[k].toString()})
-												event.Put("name", event.Get("kubernetes.labels.app_kubernetes_io/instance"))
-												delete msg.T
-												event.Put("sm", JSON.stringify(msg))
-												event.Delete("message")
-											} catch(e) {
-												var message = event.Get('message')
-												var sm = { message: message }
-												event.Delete("message")
-												event.Put("sm", JSON.stringify(sm));
-												event.Put("name", event.Get("kubernetes.labels.app_kubernetes_io/instance"))
-											}
-										}
-							- drop_fields:
-									fields: ["log", "cloud", "ecs", "agent", "input", "tags", "docker", "container", "host", "kubernetes"]
-							- decode_json_fields:
-									fields: ["sm"]
-									target: "sm"
-									process_array: false
-									max_depth: 2
-									overwrite_keys: true
-									add_error_key: true
-						filebeat:
-							autodiscover.providers:
-							- type: kubernetes
-								templates:
-									- condition:
-											equals:
-												kubernetes.namespace: ws
-									- condition.contains:
-											kubernetes.container.name: node
-										config:
-											- type: container
-												paths:
-													- /var/log/containers/*-${data.kubernetes.container.id}.log
-						output.elasticsearch:
-							host: '${NODE_NAME}'
-							hosts: '${ELASTICSEARCH_HOSTS:elasticsearch-master:9200}'
-							username: "${ELASTICSEARCH_USERNAME}"
-							password: "${ELASTICSEARCH_PASSWORD}"
-							index: "ws-%{+YYYY.MM.dd}"
-							worker: 3
-							bulk_max_size: 1000
-						setup.template.enabled: false
-						setup.ilm.enabled: false
-		`),
-	}
-
-	if err = client.InstallOrUpgradeChart(context.Background(), &filebeatSpecWS); err != nil {
-		return err
+		ValuesYaml:  filebeatValues(es, "kubernetes.labels.app_kubernetes_io/instance", "ws-%{+YYYY.MM.dd}", wsAutodiscover),
 	}
 
-	kibanaURL, err := k8s.GetKibanaURL()
+	return installChart(client, "filebeat", &filebeatSpecWS)
+}
+
+// deployFilebeatForExternalES sends the logs to an external elasticsearch with
+// the indices prefixed by the network name
+func (k8s *Kubernetes) deployFilebeatForExternalES() error {
+	es, err := externalESConnection()
 
 	if err != nil {
 		return err
 	}
 
-	url := "http://" + kibanaURL + "/api/saved_objects/_import"
-	method := "POST"
-
-	payload := &bytes.Buffer{}
-	writer := multipart.NewWriter(payload)
+	k8s.Password = es.Password
+	k8s.es = es
 
-	file, err := os.Open(config.KibanaSavedObjects)
-	if err != nil {
-		return err
+	secret := &apiv1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "elastic-credentials",
+		},
+		StringData: map[string]string{
+			"username": es.Username,
+			"password": es.Password,
+		},
 	}
-	defer file.Close()
+	_, err = k8s.Client.CoreV1().Secrets("default").Create(context.Background(), secret, metav1.CreateOptions{})
 
-	part, err := writer.CreateFormFile("file", filepath.Base(config.KibanaSavedObjects))
-	if err != nil {
-		return err
-	}
-	_, err = io.Copy(part, file)
 	if err != nil {
 		return err
 	}
 
-	err = writer.Close()
-	if err != nil {
+	if err = k8s.createExternalESSecret(es); err != nil {
 		return err
 	}
 
-	httpClient := &http.Client{}
-	req, err := http.NewRequest(method, url, payload)
+	opt := &helm.RestConfClientOptions{
+		Options: &helm.Options{
+			Debug:   true,
+			Linting: true,
+		},
+		RestConfig: k8s.RestConfig,
+	}
 
+	client, err := helm.NewClientFromRestConf(opt)
 	if err != nil {
 		return err
 	}
-	req.Header.Add("kbn-xsrf", "true")
-	req.Header.Set("Content-Type", writer.FormDataContentType())
-	req.Header.Add("Authorization", "Basic "+basicAuth("elastic", pass))
-
-	_, err = httpClient.Do(req)
 
-	if err != nil {
+	if err = k8s.createLogsTemplate(); err != nil {
 		return err
 	}
 
-	if config.CloudflareAPIToken != "" {
-		ingressClient := k8s.Client.ExtensionsV1beta1().Ingresses("default")
-		ingress, err := ingressClient.Get(context.Background(), "kibana-kibana", metav1.GetOptions{})
-
-		if err != nil {
-			return err
-		}
-
-		ip := ingress.Status.LoadBalancer.Ingress[0].IP
-
-		api, err := cloudflare.NewWithAPIToken(config.CloudflareAPIToken)
-
-		if err != nil {
-			return err
-		}
-
-		id, err := api.ZoneIDByName("spacemesh.io")
-
-		if err != nil {
-			return err
-		}
-
-		proxied := true
+	return k8s.installFilebeat(client, es)
+}
 
-		_, err = api.CreateDNSRecord(context.Background(), id, cloudflare.DNSRecord{
-			Type:    "A",
-			Name:    "kibana-" + config.NetworkName + ".spacemesh.io",
-			Content: ip,
-			Proxied: &proxied,
-		})
+func (k8s *Kubernetes) DeployFilebeatForWS() error {
+	es, err := k8s.GetESConnection()
 
-		if err != nil {
-			return err
-		}
+	if err != nil {
+		return err
 	}
 
-	return nil
-}
-
-func (k8s *Kubernetes) DeployFilebeatForWS() error {
 	opt := &helm.RestConfClientOptions{
 		Options: &helm.Options{
 			Debug:   true,
@@ -502,96 +316,15 @@ func (k8s *Kubernetes) DeployFilebeatForWS() error {
 		return err
 	}
 
-	chartRepo := repo.Entry{
-		Name: "elastic",
-		URL:  "https://helm.elastic.co",
-	}
-
-	if err := client.AddOrUpdateChartRepo(chartRepo); err != nil {
-		return err
-	}
-
 	filebeatSpecWS := helm.ChartSpec{
 		ReleaseName: "filebeat-ws",
-		ChartName:   "elastic/filebeat",
 		Namespace:   "default",
 		Wait:        true,
 		Force:       true,
-		Version:     "7.15.0",
-		ValuesYaml: sanitizeYaml(`
-			daemonset:
-				extraEnvs:
-				- name: 'ELASTICSEARCH_USERNAME'
-					valueFrom:
-						secretKeyRef:
-							name: elastic-credentials
-							key: username
-				- name: 'ELASTICSEARCH_PASSWORD'
-					valueFrom:
-						secretKeyRef:
-							name: elastic-credentials
-							key: password
-				resources: {}
-				filebeatConfig:
-					filebeat.yml: |
-						processors:
-							- script:
-									lang: javascript
-									id: my_filter
-									source: >
-										function process(event) {
-											var message = event.Get('message')
-											try {
-												var msg = JSON.parse(message)
-												Object.keys(msg).forEach(function(k) {msg[k] = msg[k].toString()})
-												event.Put("name", event.Get("kubernetes.labels.app_kubernetes_io/instance"))
-												delete msg.T
-												event.Put("sm", JSON.stringify(msg))
-												event.Delete("message")
-											} catch(e) {
-												var message = event.Get('message')
-												var sm = { message: message }
-												event.Delete("message")
-												event.Put("sm", JSON.stringify(sm));
-												event.Put("name", event.Get("kubernetes.labels.app_kubernetes_io/instance"))
-											}
-										}
-							- drop_fields:
-									fields: ["log", "cloud", "ecs", "agent", "input", "tags", "docker", "container", "host", "kubernetes"]
-							- decode_json_fields:
-									fields: ["sm"]
-									target: "sm"
-									process_array: false
-									max_depth: 2
-									overwrite_keys: true
-									add_error_key: true
-						filebeat:
-							autodiscover.providers:
-							- type: kubernetes
-								templates:
-									- condition:
-											equals:
-												kubernetes.namespace: ws
-									- condition.contains:
-											kubernetes.container.name: node
-										config:
-											- type: container
-												paths:
-													- /var/log/containers/*-${data.kubernetes.container.id}.log
-						output.elasticsearch:
-							host: '${NODE_NAME}'
-							hosts: '${ELASTICSEARCH_HOSTS:elasticsearch-master:9200}'
-							username: "${ELASTICSEARCH_USERNAME}"
-							password: "${ELASTICSEARCH_PASSWORD}"
-							index: "ws-%{+YYYY.MM.dd}"
-							worker: 3
-							bulk_max_size: 1000
-						setup.template.enabled: false
-						setup.ilm.enabled: false
-		`),
-	}
-
-	if err := client.InstallOrUpgradeChart(context.Background(), &filebeatSpecWS); err != nil {
+		ValuesYaml:  filebeatValues(es, "kubernetes.labels.app_kubernetes_io/instance", "ws-%{+YYYY.MM.dd}", wsAutodiscover),
+	}
+
+	if err = installChart(client, "filebeat", &filebeatSpecWS); err != nil {
 		return err
 	}
 
@@ -641,197 +374,65 @@ func (k8s *Kubernetes) GetESURL() (string, error) {
 
 }
 
+// SetupLogDeletionPolicy deletes the sm-* indices after LogsExpiry days. In a
+// shared elasticsearch the policy and the templates are created per network.
 func (k8s *Kubernetes) SetupLogDeletionPolicy() error {
-	esURL, err := k8s.GetESURL()
-
-	httpClient := &http.Client{}
+	es, err := k8s.GetESConnection()
 
 	if err != nil {
 		return err
 	}
 
-	req, err := http.NewRequest(http.MethodPut, "http://"+esURL+"/_ilm/policy/cleanup-history", bytes.NewBuffer([]byte(fmt.Sprintf("{\"policy\":{\"phases\":{\"hot\":{\"actions\":{}},\"delete\":{\"min_age\":\"%sd\",\"actions\":{\"delete\":{}}}}}}", config.LogsExpiry))))
-	if err != nil {
-		return err
-	}
-
-	req.Header.Add("Content-Type", "application/json")
-	req.Header.Add("Authorization", "Basic "+basicAuth("elastic", k8s.Password))
-
-	resp, err := httpClient.Do(req)
-
-	if err != nil {
-		return err
-	}
-
-	if !(resp.StatusCode >= 200 && resp.StatusCode <= 299) {
-		defer resp.Body.Close()
-		body, err := ioutil.ReadAll(resp.Body)
+	deleteActions := map[string]interface{}{"delete": map[string]interface{}{}}
 
-		if err != nil {
-			return err
-		}
-
-		return errors.New(string(body))
+	// indices are only deleted once they are included in a snapshot
+	if config.ArchiveLogs && !es.External {
+		deleteActions["wait_for_snapshot"] = map[string]interface{}{"policy": archivePolicy}
 	}
 
-	req, err = http.NewRequest(http.MethodPut, "http://"+esURL+"/sm-*/_settings?pretty", bytes.NewBuffer([]byte("{\"lifecycle.name\":\"cleanup-history\"}")))
-	if err != nil {
-		return err
-	}
-
-	req.Header.Add("Content-Type", "application/json")
-	req.Header.Add("Authorization", "Basic "+basicAuth("elastic", k8s.Password))
-
-	resp, err = httpClient.Do(req)
-
-	if err != nil {
-		return err
-	}
-
-	if !(resp.StatusCode >= 200 && resp.StatusCode <= 299) {
-		defer resp.Body.Close()
-		body, err := ioutil.ReadAll(resp.Body)
-
-		if err != nil {
-			return err
-		}
-
-		return errors.New(string(body))
-	}
-
-	req, err = http.NewRequest(http.MethodPut, "http://"+esURL+"/_template/logging_policy_template?pretty", bytes.NewBuffer([]byte("{\"index_patterns\":[\"sm-*\"],\"settings\":{\"index.lifecycle.name\":\"cleanup-history\"}}")))
-	if err != nil {
-		return err
-	}
-
-	req.Header.Add("Content-Type", "application/json")
-	req.Header.Add("Authorization", "Basic "+basicAuth("elastic", k8s.Password))
-
-	resp, err = httpClient.Do(req)
+	_, err = k8s.ESRequest(http.MethodPut, "/_ilm/policy/"+es.Index("cleanup-history"), map[string]interface{}{
+		"policy": map[string]interface{}{
+			"phases": map[string]interface{}{
+				"hot": map[string]interface{}{"actions": map[string]interface{}{}},
+				"delete": map[string]interface{}{
+					"min_age": config.LogsExpiry + "d",
+					"actions": deleteActions,
+				},
+			},
+		},
+	})
 
 	if err != nil {
 		return err
 	}
 
-	if !(resp.StatusCode >= 200 && resp.StatusCode <= 299) {
-		defer resp.Body.Close()
-		body, err := ioutil.ReadAll(resp.Body)
-
-		if err != nil {
-			return err
-		}
-
-		return errors.New(string(body))
-	}
-
-	return nil
+	return k8s.applyLogDeletionPolicy(es, "sm-*", "logging_policy_template")
 }
 
 func (k8s *Kubernetes) SetupLogDeletionPolicyForWS() error {
-	pass, err := k8s.GetKibanaPassword()
-
-	if err != nil {
-		return err
-	}
-
-	k8s.Password = pass
-
-	esURL, err := k8s.GetESURL()
-
-	if err != nil {
-		return err
-	}
-
-	httpClient := &http.Client{}
-
-	if err != nil {
-		return err
-	}
+	es, err := k8s.GetESConnection()
 
-	req, err := http.NewRequest(http.MethodPut, "http://"+esURL+"/ws-*/_settings?pretty", bytes.NewBuffer([]byte("{\"lifecycle.name\":\"cleanup-history\"}")))
 	if err != nil {
 		return err
 	}
 
-	req.Header.Add("Content-Type", "application/json")
-	req.Header.Add("Authorization", "Basic "+basicAuth("elastic", k8s.Password))
-
-	resp, err := httpClient.Do(req)
-
-	if err != nil {
-		return err
-	}
-
-	if !(resp.StatusCode >= 200 && resp.StatusCode <= 299) {
-		defer resp.Body.Close()
-		body, err := ioutil.ReadAll(resp.Body)
-
-		if err != nil {
-			return err
-		}
-
-		return errors.New(string(body))
-	}
-
-	req, err = http.NewRequest(http.MethodPut, "http://"+esURL+"/_template/logging_policy_template?pretty", bytes.NewBuffer([]byte("{\"index_patterns\":[\"ws-*\"],\"settings\":{\"index.lifecycle.name\":\"cleanup-history\"}}")))
-	if err != nil {
-		return err
-	}
-
-	req.Header.Add("Content-Type", "application/json")
-	req.Header.Add("Authorization", "Basic "+basicAuth("elastic", k8s.Password))
+	return k8s.applyLogDeletionPolicy(es, "ws-*", "ws_logging_policy_template")
+}
 
-	resp, err = httpClient.Do(req)
+// applyLogDeletionPolicy applies the policy to the existing and future indices
+func (k8s *Kubernetes) applyLogDeletionPolicy(es *ESConnection, pattern string, template string) error {
+	_, err := k8s.ESRequest(http.MethodPut, "/"+es.Index(pattern)+"/_settings", map[string]interface{}{
+		"lifecycle.name": es.Index("cleanup-history"),
+	})
 
 	if err != nil {
 		return err
 	}
 
-	if !(resp.StatusCode >= 200 && resp.StatusCode <= 299) {
-		defer resp.Body.Close()
-		body, err := ioutil.ReadAll(resp.Body)
-
-		if err != nil {
-			return err
-		}
-
-		return errors.New(string(body))
-	}
-
-	return nil
-}
-
-func (k8s *Kubernetes) DeleteELKDNSRecords() error {
-	if config.CloudflareAPIToken != "" {
-		api, err := cloudflare.NewWithAPIToken(config.CloudflareAPIToken)
-
-		if err != nil {
-			return err
-		}
-
-		id, err := api.ZoneIDByName("spacemesh.io")
-
-		if err != nil {
-			return err
-		}
-
-		records, err := api.DNSRecords(context.Background(), id, cloudflare.DNSRecord{
-			Name: "kibana-" + config.NetworkName + ".spacemesh.io",
-		})
-
-		if err != nil {
-			return err
-		}
+	_, err = k8s.ESRequest(http.MethodPut, "/_template/"+es.Index(template), map[string]interface{}{
+		"index_patterns": []string{es.Index(pattern)},
+		"settings":       map[string]interface{}{"index.lifecycle.name": es.Index("cleanup-history")},
+	})
 
-		if len(records) == 1 {
-			err = api.DeleteDNSRecord(context.Background(), id, records[0].ID)
-
-			if err != nil {
-				return err
-			}
-		}
-	}
-
-	return nil
+	return err
 }
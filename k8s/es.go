@@ -0,0 +1,217 @@
+package k8s
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"strings"
+
+	apiv1 "k8s.io/api/core/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// esExternalSecret stores the connection to an external elasticsearch shared by networks
+const esExternalSecret = "elasticsearch-external"
+
+// ESConnection is the elasticsearch which stores the logs of the network
+type ESConnection struct {
+	URL      string
+	Username string
+	Password string
+	// KibanaURL is only set for an external elasticsearch
+	KibanaURL string
+	// External is set if the elasticsearch is shared with other networks
+	External bool
+	// IndexPrefix is prepended to the log indices in a shared elasticsearch
+	IndexPrefix string
+	CA          []byte
+}
+
+// Index returns the name of a log index or index pattern, e.g., sm-*
+func (es *ESConnection) Index(name string) string {
+	return es.IndexPrefix + name
+}
+
+func (es *ESConnection) httpClient() (*http.Client, error) {
+	if len(es.CA) == 0 {
+		return http.DefaultClient, nil
+	}
+
+	pool := x509.NewCertPool()
+
+	if !pool.AppendCertsFromPEM(es.CA) {
+		return nil, errors.New("invalid elasticsearch CA certificate")
+	}
+
+	return &http.Client{
+		Transport: &http.Transport{
+			TLSClientConfig: &tls.Config{RootCAs: pool},
+		},
+	}, nil
+}
+
+// externalESConnection is the external elasticsearch configured for a new network
+func externalESConnection() (*ESConnection, error) {
+	es := &ESConnection{
+		URL:         strings.TrimSuffix(config.ESURL, "/"),
+		Username:    config.ESUsername,
+		Password:    config.ESPassword,
+		KibanaURL:   strings.TrimSuffix(config.KibanaURL, "/"),
+		External:    true,
+		IndexPrefix: config.NetworkName + "-",
+	}
+
+	if config.ESCA != "" {
+		ca, err := ioutil.ReadFile(config.ESCA)
+
+		if err != nil {
+			return nil, err
+		}
+
+		es.CA = ca
+	}
+
+	return es, nil
+}
+
+// createExternalESSecret stores the external elasticsearch connection for
+// the other commands, the credentials are stored in elastic-credentials
+func (k8s *Kubernetes) createExternalESSecret(es *ESConnection) error {
+	secret := &apiv1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: esExternalSecret,
+		},
+		Data: map[string][]byte{
+			"url":    []byte(es.URL),
+			"kibana": []byte(es.KibanaURL),
+			"prefix": []byte(es.IndexPrefix),
+		},
+	}
+
+	if len(es.CA) > 0 {
+		secret.Data["ca.crt"] = es.CA
+	}
+
+	_, err := k8s.Client.CoreV1().Secrets("default").Create(context.Background(), secret, metav1.CreateOptions{})
+
+	return err
+}
+
+// GetESConnection returns the external elasticsearch of the network if one is
+// configured, otherwise the elasticsearch deployed in the network
+func (k8s *Kubernetes) GetESConnection() (*ESConnection, error) {
+	if k8s.es != nil {
+		return k8s.es, nil
+	}
+
+	if k8s.Password == "" {
+		pass, err := k8s.GetKibanaPassword()
+
+		if err != nil {
+			return nil, err
+		}
+
+		k8s.Password = pass
+	}
+
+	secret, err := k8s.Client.CoreV1().Secrets("default").Get(context.Background(), esExternalSecret, metav1.GetOptions{})
+
+	if err != nil && !k8serrors.IsNotFound(err) {
+		return nil, err
+	}
+
+	if err == nil {
+		username, err := k8s.GetSecret("elastic-credentials", "username")
+
+		if err != nil {
+			return nil, err
+		}
+
+		k8s.es = &ESConnection{
+			URL:         string(secret.Data["url"]),
+			Username:    username,
+			Password:    k8s.Password,
+			KibanaURL:   string(secret.Data["kibana"]),
+			External:    true,
+			IndexPrefix: string(secret.Data["prefix"]),
+			CA:          secret.Data["ca.crt"],
+		}
+
+		return k8s.es, nil
+	}
+
+	esURL, err := k8s.GetESURL()
+
+	if err != nil {
+		return nil, err
+	}
+
+	k8s.es = &ESConnection{
+		URL:      "http://" + esURL,
+		Username: "elastic",
+		Password: k8s.Password,
+	}
+
+	return k8s.es, nil
+}
+
+// ESRequest sends a request to the elasticsearch of the network and returns
+// the response body
+func (k8s *Kubernetes) ESRequest(method string, path string, body interface{}) ([]byte, error) {
+	es, err := k8s.GetESConnection()
+
+	if err != nil {
+		return nil, err
+	}
+
+	httpClient, err := es.httpClient()
+
+	if err != nil {
+		return nil, err
+	}
+
+	payload := []byte{}
+
+	if body != nil {
+		payload, err = json.Marshal(body)
+
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	req, err := http.NewRequest(method, es.URL+path, bytes.NewBuffer(payload))
+
+	if err != nil {
+		return nil, err
+	}
+
+	req.Header.Add("Content-Type", "application/json")
+	req.Header.Add("Authorization", "Basic "+basicAuth(es.Username, es.Password))
+
+	resp, err := httpClient.Do(req)
+
+	if err != nil {
+		return nil, err
+	}
+
+	defer resp.Body.Close()
+
+	respBody, err := ioutil.ReadAll(resp.Body)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if !(resp.StatusCode >= 200 && resp.StatusCode <= 299) {
+		return nil, errors.New(string(respBody))
+	}
+
+	return respBody, nil
+}
@@ -0,0 +1,229 @@
+package k8s
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+
+	appsv1 "k8s.io/api/apps/v1"
+	apiv1 "k8s.io/api/core/v1"
+	"k8s.io/api/extensions/v1beta1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+)
+
+// FaucetURL is the public URL of the faucet added to the discovery service
+func FaucetURL() string {
+	return "https://" + Domain("faucet") + "/"
+}
+
+// DeployFaucet deploys the faucet in the ws namespace behind the ws ingress
+// controller. The faucet is funded by the coinbase key of FaucetMiner.
+func (k8s *Kubernetes) DeployFaucet() error {
+	privateKey, err := k8s.GetSecret("miner-"+config.FaucetMiner+"-coinbase", "privateKey")
+
+	if err != nil {
+		return err
+	}
+
+	curve, _ := k8s.GetSecret("miner-"+config.FaucetMiner+"-coinbase", "curve")
+
+	if err = checkCoinbaseCurve("secret miner-"+config.FaucetMiner+"-coinbase", curve); err != nil {
+		return err
+	}
+
+	fmt.Println("creating faucet secret")
+
+	_, err = k8s.Client.CoreV1().Secrets("ws").Create(context.Background(), &apiv1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "faucet",
+		},
+		StringData: map[string]string{
+			"key": privateKey,
+		},
+	}, metav1.CreateOptions{})
+
+	if err != nil {
+		return err
+	}
+
+	fmt.Println("creating faucet volume")
+
+	fs := apiv1.PersistentVolumeFilesystem
+
+	_, err = k8s.Client.CoreV1().PersistentVolumeClaims("ws").Create(context.Background(), &apiv1.PersistentVolumeClaim{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "faucet",
+		},
+		Spec: apiv1.PersistentVolumeClaimSpec{
+			AccessModes: []apiv1.PersistentVolumeAccessMode{apiv1.ReadWriteOnce},
+			Resources: apiv1.ResourceRequirements{
+				Requests: apiv1.ResourceList{
+					apiv1.ResourceStorage: resource.MustParse("1Gi"),
+				},
+			},
+			VolumeMode: &fs,
+		},
+	}, metav1.CreateOptions{})
+
+	if err != nil {
+		return err
+	}
+
+	labels := map[string]string{"app": "faucet"}
+
+	fmt.Println("creating faucet deployment")
+
+	deployment := &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "faucet",
+		},
+		Spec: appsv1.DeploymentSpec{
+			Replicas: int32Ptr(1),
+			Selector: &metav1.LabelSelector{
+				MatchLabels: labels,
+			},
+			// the volume can only be attached to one pod
+			Strategy: appsv1.DeploymentStrategy{
+				Type: appsv1.RecreateDeploymentStrategyType,
+			},
+			Template: apiv1.PodTemplateSpec{
+				ObjectMeta: metav1.ObjectMeta{
+					Labels: labels,
+				},
+				Spec: apiv1.PodSpec{
+					Volumes: []apiv1.Volume{
+						{
+							Name: "data",
+							VolumeSource: apiv1.VolumeSource{
+								PersistentVolumeClaim: &apiv1.PersistentVolumeClaimVolumeSource{
+									ClaimName: "faucet",
+								},
+							},
+						},
+					},
+					Containers: []apiv1.Container{
+						{
+							Name:  "faucet",
+							Image: config.FaucetImage,
+							Args: []string{
+								"faucet",
+								"--host=miner-" + config.FaucetMiner + ".default.svc.cluster.local:6000",
+								"--faucet-port=" + strconv.Itoa(config.FaucetPort),
+								"--faucet-amount=" + strconv.FormatUint(config.FaucetAmount, 10),
+								"--faucet-interval=" + config.FaucetInterval,
+								"--faucet-state=/data/drips.json",
+							},
+							VolumeMounts: []apiv1.VolumeMount{
+								{
+									Name:      "data",
+									MountPath: "/data",
+								},
+							},
+							Env: []apiv1.EnvVar{
+								{
+									Name: "SPACECRAFT_FAUCET_KEY",
+									ValueFrom: &apiv1.EnvVarSource{
+										SecretKeyRef: &apiv1.SecretKeySelector{
+											LocalObjectReference: apiv1.LocalObjectReference{Name: "faucet"},
+											Key:                  "key",
+										},
+									},
+								},
+							},
+							Ports: []apiv1.ContainerPort{
+								{
+									Name:          "http",
+									ContainerPort: int32(config.FaucetPort),
+								},
+							},
+							ReadinessProbe: &apiv1.Probe{
+								Handler: apiv1.Handler{
+									HTTPGet: &apiv1.HTTPGetAction{
+										Path: "/health",
+										Port: intstr.FromInt(config.FaucetPort),
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+
+	if _, err = k8s.Client.AppsV1().Deployments("ws").Create(context.Background(), deployment, metav1.CreateOptions{}); err != nil {
+		return err
+	}
+
+	fmt.Println("creating faucet service")
+
+	_, err = k8s.Client.CoreV1().Services("ws").Create(context.Background(), &apiv1.Service{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "faucet",
+		},
+		Spec: apiv1.ServiceSpec{
+			Ports: []apiv1.ServicePort{
+				{Name: "http", Port: 80, TargetPort: intstr.FromInt(config.FaucetPort)},
+			},
+			Selector: labels,
+		},
+	}, metav1.CreateOptions{})
+
+	if err != nil {
+		return err
+	}
+
+	fmt.Println("creating faucet ingress")
+
+	ingressTLS := []v1beta1.IngressTLS{}
+
+	if ManagedTLS() {
+		ingressTLS = append(ingressTLS, v1beta1.IngressTLS{
+			Hosts:      []string{Domain("faucet")},
+			SecretName: "faucet-tls",
+		})
+	}
+
+	ingressClient := k8s.Client.ExtensionsV1beta1().Ingresses("ws")
+	_, err = ingressClient.Create(context.Background(), &v1beta1.Ingress{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "faucet",
+			Annotations: map[string]string{
+				"kubernetes.io/ingress.class": "nginx",
+			},
+		},
+		Spec: v1beta1.IngressSpec{
+			TLS: ingressTLS,
+			Rules: []v1beta1.IngressRule{
+				{
+					Host: Domain("faucet"),
+					IngressRuleValue: v1beta1.IngressRuleValue{
+						HTTP: &v1beta1.HTTPIngressRuleValue{
+							Paths: []v1beta1.HTTPIngressPath{
+								{
+									Path: "/",
+									Backend: v1beta1.IngressBackend{
+										ServiceName: "faucet",
+										ServicePort: intstr.FromInt(80),
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+	}, metav1.CreateOptions{})
+
+	if err != nil {
+		return err
+	}
+
+	if err = k8s.createCertificate("ws", "faucet-tls", "faucet"); err != nil {
+		return err
+	}
+
+	return k8s.createDNSRecords("faucet", "ws", "faucet")
+}
@@ -0,0 +1,229 @@
+package k8s
+
+import (
+	"fmt"
+	"net/http"
+)
+
+// filebeatProcessors parses the JSON logs of go-spacemesh into the sm field.
+// The fields mapped by the logs index template keep their types and all the
+// other fields are converted to strings. Durations are converted to seconds.
+func filebeatProcessors(nameField string) string {
+	return fmt.Sprintf(`processors:
+							- script:
+									lang: javascript
+									id: my_filter
+									source: >
+										var typedFields = { layer_id: toNumber, epoch_id: toNumber, duration: toSeconds };
+										var durationUnits = { ns: 1e-9, us: 1e-6, "µs": 1e-6, ms: 1e-3, s: 1, m: 60, h: 3600 };
+										function toNumber(value) {
+											if (typeof value === "number") {
+												return value;
+											}
+											if (typeof value === "string" && /^-?\d+(\.\d+)?$/.test(value)) {
+												return Number(value);
+											}
+											return null;
+										}
+										function toSeconds(value) {
+											if (typeof value === "number") {
+												return value;
+											}
+											if (typeof value !== "string") {
+												return null;
+											}
+											var re = /(\d+(?:\.\d+)?)(ns|us|µs|ms|s|m|h)/g;
+											var seconds = 0;
+											var matched = "";
+											var part;
+											while ((part = re.exec(value)) !== null) {
+												seconds += parseFloat(part[1]) * durationUnits[part[2]];
+												matched += part[0];
+											}
+											return matched !== "" && matched === value ? seconds : null;
+										}
+										function toText(value) {
+											if (value === null || value === undefined) {
+												return "null";
+											}
+											if (typeof value === "object") {
+												return JSON.stringify(value);
+											}
+											return value.toString();
+										}
+										function process(event) {
+											var message = event.Get('message');
+											try {
+												var msg = JSON.parse(message);
+												Object.keys(msg).forEach(function(k) {
+													var value = msg[k];
+													if (typedFields[k]) {
+														var typed = typedFields[k](value);
+														if (typed !== null) {
+															msg[k] = typed;
+															return;
+														}
+														delete msg[k];
+														k = k + "_text";
+													}
+													msg[k] = toText(value);
+												});
+												event.Put("name", event.Get("%s"));
+												delete msg.T;
+												event.Put("sm", JSON.stringify(msg));
+												event.Delete("message");
+											} catch(e) {
+												var sm = { message: message };
+												event.Delete("message");
+												event.Put("sm", JSON.stringify(sm));
+												event.Put("name", event.Get("%s"));
+											}
+										}
+							- drop_fields:
+									fields: ["log", "cloud", "ecs", "agent", "input", "tags", "docker", "container", "host", "kubernetes"]
+							- decode_json_fields:
+									fields: ["sm"]
+									target: "sm"
+									process_array: false
+									max_depth: 2
+									overwrite_keys: true
+									add_error_key: true`, nameField, nameField)
+}
+
+// smAutodiscover collects the logs of miners and poets
+const smAutodiscover = `filebeat:
+							autodiscover.providers:
+							- type: kubernetes
+								templates:
+									- condition.contains:
+											kubernetes.container.name: miner
+										config:
+											- type: container
+												paths:
+													- /var/log/containers/*-${data.kubernetes.container.id}.log
+									- condition.contains:
+											kubernetes.container.name: poet
+										config:
+											- type: container
+												paths:
+													- /var/log/containers/*-${data.kubernetes.container.id}.log`
+
+// wsAutodiscover collects the logs of the web services
+const wsAutodiscover = `filebeat:
+							autodiscover.providers:
+							- type: kubernetes
+								templates:
+									- condition:
+											equals:
+												kubernetes.namespace: ws
+									- condition.contains:
+											kubernetes.container.name: node
+										config:
+											- type: container
+												paths:
+													- /var/log/containers/*-${data.kubernetes.container.id}.log`
+
+// filebeatValues configures filebeat to send the logs collected with the
+// autodiscover config to elasticsearch
+func filebeatValues(es *ESConnection, nameField string, index string, autodiscover string) string {
+	extraEnvs := ""
+	secretMounts := ""
+	ssl := ""
+
+	if es.External {
+		extraEnvs = fmt.Sprintf(`
+				- name: ELASTICSEARCH_HOSTS
+					value: %s`, es.URL)
+	}
+
+	if len(es.CA) > 0 {
+		secretMounts = fmt.Sprintf(`
+				secretMounts:
+				- name: elasticsearch-ca
+					secretName: %s
+					path: /usr/share/filebeat/certs`, esExternalSecret)
+		ssl = `
+							ssl.certificate_authorities: ["/usr/share/filebeat/certs/ca.crt"]`
+	}
+
+	return sanitizeYaml(fmt.Sprintf(`
+			daemonset:
+				extraEnvs:
+				- name: 'ELASTICSEARCH_USERNAME'
+					valueFrom:
+						secretKeyRef:
+							name: elastic-credentials
+							key: username
+				- name: 'ELASTICSEARCH_PASSWORD'
+					valueFrom:
+						secretKeyRef:
+							name: elastic-credentials
+							key: password%s%s
+				resources: {}
+				filebeatConfig:
+					filebeat.yml: |
+						%s
+						%s
+						output.elasticsearch:
+							host: '${NODE_NAME}'
+							hosts: '${ELASTICSEARCH_HOSTS:elasticsearch-master:9200}'
+							username: "${ELASTICSEARCH_USERNAME}"
+							password: "${ELASTICSEARCH_PASSWORD}"
+							index: "%s"
+							worker: 3
+							bulk_max_size: 1000%s
+						setup.template.enabled: false
+						setup.ilm.enabled: false
+		`, extraEnvs, secretMounts, filebeatProcessors(nameField), autodiscover, es.Index(index), ssl))
+}
+
+// createLogsTemplate maps the known fields of go-spacemesh logs with their
+// types and all the other fields as strings. It is merged with the log
+// deletion policy template.
+func (k8s *Kubernetes) createLogsTemplate() error {
+	es, err := k8s.GetESConnection()
+
+	if err != nil {
+		return err
+	}
+
+	keyword := map[string]interface{}{"type": "keyword"}
+	text := map[string]interface{}{
+		"type": "text",
+		"fields": map[string]interface{}{
+			"keyword": map[string]interface{}{"type": "keyword", "ignore_above": 256},
+		},
+	}
+
+	_, err = k8s.ESRequest(http.MethodPut, "/_template/"+es.Index("spacemesh-logs"), map[string]interface{}{
+		"index_patterns": []string{es.Index("sm-*"), es.Index("ws-*")},
+		"order":          1,
+		"mappings": map[string]interface{}{
+			"dynamic_templates": []interface{}{
+				map[string]interface{}{
+					"sm_strings": map[string]interface{}{
+						"path_match":         "sm.*",
+						"match_mapping_type": "string",
+						"mapping":            text,
+					},
+				},
+			},
+			"properties": map[string]interface{}{
+				"sm": map[string]interface{}{
+					"properties": map[string]interface{}{
+						"layer_id": map[string]interface{}{"type": "long"},
+						"epoch_id": map[string]interface{}{"type": "long"},
+						"duration": map[string]interface{}{"type": "double"},
+						"node_id":  keyword,
+						"module":   keyword,
+						"N":        keyword,
+						"L":        text,
+						"M":        text,
+					},
+				},
+			},
+		},
+	})
+
+	return err
+}
@@ -0,0 +1,153 @@
+package k8s
+
+import (
+	"context"
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+
+	apiv1 "k8s.io/api/core/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// go-spacemesh loads its libp2p identity from <data-dir>/p2p/p2p.key
+const p2pKeyDir = "/root/data/node/p2p"
+
+const base58Alphabet = "123456789ABCDEFGHJKLMNPQRSTUVWXYZabcdefghijkmnopqrstuvwxyz"
+
+// identityInfo mirrors the p2p.key file format of go-spacemesh
+type identityInfo struct {
+	Key []byte
+	ID  string
+}
+
+func base58Encode(input []byte) string {
+	x := new(big.Int).SetBytes(input)
+	base := big.NewInt(58)
+	mod := new(big.Int)
+
+	encoded := []byte{}
+
+	for x.Sign() > 0 {
+		x.DivMod(x, base, mod)
+		encoded = append(encoded, base58Alphabet[mod.Int64()])
+	}
+
+	for _, b := range input {
+		if b != 0 {
+			break
+		}
+
+		encoded = append(encoded, base58Alphabet[0])
+	}
+
+	for i, j := 0, len(encoded)-1; i < j; i, j = i+1, j-1 {
+		encoded[i], encoded[j] = encoded[j], encoded[i]
+	}
+
+	return string(encoded)
+}
+
+// generateIdentity creates an ed25519 libp2p key and returns the serialized
+// p2p.key file together with the peer ID derived from it
+func generateIdentity() (string, string, error) {
+	publicKey, privateKey, err := ed25519.GenerateKey(rand.Reader)
+
+	if err != nil {
+		return "", "", err
+	}
+
+	// libp2p protobuf encoding: field 1 is the key type (1 = Ed25519), field 2 the key data
+	marshaledPrivateKey := append([]byte{0x08, 0x01, 0x12, byte(len(privateKey))}, privateKey...)
+	marshaledPublicKey := append([]byte{0x08, 0x01, 0x12, byte(len(publicKey))}, publicKey...)
+
+	// keys this small are inlined in the peer ID using the identity multihash
+	peerID := base58Encode(append([]byte{0x00, byte(len(marshaledPublicKey))}, marshaledPublicKey...))
+
+	keyFile, err := json.Marshal(identityInfo{Key: marshaledPrivateKey, ID: peerID})
+
+	if err != nil {
+		return "", "", err
+	}
+
+	return string(keyFile), peerID, nil
+}
+
+func (k8s *Kubernetes) CreateMinerIdentity(minerNumber string) (string, error) {
+	keyFile, peerID, err := generateIdentity()
+
+	if err != nil {
+		return "", err
+	}
+
+	fmt.Println("creating miner-" + minerNumber + " identity secret")
+
+	secretsClient := k8s.Client.CoreV1().Secrets("default")
+	secret := &apiv1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "miner-" + minerNumber + "-identity",
+		},
+		StringData: map[string]string{
+			"p2p.key": keyFile,
+			"id":      peerID,
+		},
+	}
+	_, err = secretsClient.Create(context.Background(), secret, metav1.CreateOptions{})
+
+	if err != nil {
+		return "", err
+	}
+
+	return peerID, nil
+}
+
+func (k8s *Kubernetes) GetMinerIdentity(minerNumber string) (string, error) {
+	secret, err := k8s.Client.CoreV1().Secrets("default").Get(context.Background(), "miner-"+minerNumber+"-identity", metav1.GetOptions{})
+
+	if err != nil {
+		return "", err
+	}
+
+	if val, ok := secret.Data["id"]; ok {
+		return string(val), nil
+	}
+
+	return "", errors.New("identity of miner-" + minerNumber + " not found")
+}
+
+func (k8s *Kubernetes) getOrCreateMinerIdentity(minerNumber string) (string, error) {
+	peerID, err := k8s.GetMinerIdentity(minerNumber)
+
+	if err == nil {
+		return peerID, nil
+	}
+
+	if !k8serrors.IsNotFound(err) {
+		return "", err
+	}
+
+	return k8s.CreateMinerIdentity(minerNumber)
+}
+
+// BootnodeAddress creates the identity of a miner that will be pinned to
+// nodeName, unless it already exists, and returns its p2p multiaddr before
+// the miner is deployed
+func (k8s *Kubernetes) BootnodeAddress(minerNumber string, nodeName string) (string, error) {
+	peerID, err := k8s.getOrCreateMinerIdentity(minerNumber)
+
+	if err != nil {
+		return "", err
+	}
+
+	externalIP, err := k8s.getExternalIpOfNode(nodeName)
+
+	if err != nil {
+		return "", err
+	}
+
+	return minerAddress(externalIP, minerNumber, peerID), nil
+}
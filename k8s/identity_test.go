@@ -0,0 +1,89 @@
+package k8s
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"encoding/hex"
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestBase58Encode(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"", ""},
+		{"61", "2g"},
+		{"626262", "a3gV"},
+		{"636363", "aPEr"},
+		{"572e4794", "3EFU7m"},
+		{"10c8511e", "Rt5zm"},
+		{"516b6fcd0f", "ABnLTmg"},
+		{"ecac89cad93923c02321", "EJDM8drfXA6uyA"},
+		{"00000000000000000000", "1111111111"},
+		{"000000287fb4cd", "111233QC4"},
+		{"00eb15231dfceb60925886b67d065299925915aeb172c06647", "1NS17iag9jJgTHD1VXjvLCEnZuQ3rJDE9L"},
+	}
+
+	for _, test := range tests {
+		input, err := hex.DecodeString(test.input)
+
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if encoded := base58Encode(input); encoded != test.expected {
+			t.Errorf("base58Encode(%s) = %s, expected %s", test.input, encoded, test.expected)
+		}
+	}
+}
+
+func TestGenerateIdentity(t *testing.T) {
+	keyFile, peerID, err := generateIdentity()
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	info := identityInfo{}
+
+	if err = json.Unmarshal([]byte(keyFile), &info); err != nil {
+		t.Fatal(err)
+	}
+
+	if info.ID != peerID {
+		t.Errorf("ID of the key file is %s, expected %s", info.ID, peerID)
+	}
+
+	prefix := []byte{0x08, 0x01, 0x12, ed25519.PrivateKeySize}
+
+	if len(info.Key) != len(prefix)+ed25519.PrivateKeySize || !bytes.HasPrefix(info.Key, prefix) {
+		t.Fatalf("key %x is not a marshaled ed25519 private key", info.Key)
+	}
+
+	// ed25519 peer IDs are the identity multihash of the public key
+	if !strings.HasPrefix(peerID, "12D3KooW") {
+		t.Errorf("peer ID %s is not an ed25519 peer ID", peerID)
+	}
+
+	privateKey := ed25519.PrivateKey(info.Key[len(prefix):])
+	publicKey := privateKey.Public().(ed25519.PublicKey)
+	marshaledPublicKey := append([]byte{0x08, 0x01, 0x12, ed25519.PublicKeySize}, publicKey...)
+	expected := base58Encode(append([]byte{0x00, byte(len(marshaledPublicKey))}, marshaledPublicKey...))
+
+	if peerID != expected {
+		t.Errorf("peer ID is %s, expected %s for the public key of the key file", peerID, expected)
+	}
+
+	_, otherPeerID, err := generateIdentity()
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if otherPeerID == peerID {
+		t.Error("generateIdentity returned the same identity twice")
+	}
+}
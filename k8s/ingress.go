@@ -0,0 +1,111 @@
+package k8s
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	helm "github.com/mittwald/go-helm-client"
+	apiv1 "k8s.io/api/core/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// ingressControllerPods is the headless service whose DNS name resolves to
+// the addresses of the ingress controller pods, which the faucet trusts
+const ingressControllerPods = "ingress-nginx-controller-pods"
+
+// cloudflareIPRanges are the addresses cloudflare proxies requests from,
+// see https://www.cloudflare.com/ips/
+var cloudflareIPRanges = []string{
+	"173.245.48.0/20", "103.21.244.0/22", "103.22.200.0/22", "103.31.4.0/22",
+	"141.101.64.0/18", "108.162.192.0/18", "190.93.240.0/20", "188.114.96.0/20",
+	"197.234.240.0/22", "198.41.128.0/17", "162.158.0.0/15", "104.16.0.0/13",
+	"104.24.0.0/14", "172.64.0.0/13", "131.0.72.0/22",
+	"2400:cb00::/32", "2606:4700::/32", "2803:f800::/32", "2405:b500::/32",
+	"2405:8100::/32", "2a06:98c0::/29", "2c0f:f248::/32",
+}
+
+// ingressNginxValues keeps the client IP, which the faucet rate limits by.
+// The load balancer doesn't replace it with a node IP, and with cloudflare
+// CF-Connecting-IP is only trusted from the cloudflare ranges. nginx passes
+// the result as X-Real-IP.
+func ingressNginxValues() string {
+	values := `
+		controller:
+			service:
+				externalTrafficPolicy: Local
+	`
+
+	if config.DNSProvider == "cloudflare" {
+		values += fmt.Sprintf(`
+			config:
+				enable-real-ip: "true"
+				forwarded-for-header: CF-Connecting-IP
+				proxy-real-ip-cidr: "%s"
+		`, strings.Join(cloudflareIPRanges, ","))
+	}
+
+	return sanitizeYaml(values)
+}
+
+// DeployIngressNginx deploys the ingress controller used by kibana, grafana
+// and the web services
+func (k8s *Kubernetes) DeployIngressNginx() error {
+	opt := &helm.RestConfClientOptions{
+		Options: &helm.Options{
+			Debug:   true,
+			Linting: true,
+		},
+		RestConfig: k8s.RestConfig,
+	}
+
+	client, err := helm.NewClientFromRestConf(opt)
+	if err != nil {
+		return err
+	}
+
+	ingressSpec := helm.ChartSpec{
+		ReleaseName: "ingress-nginx",
+		Namespace:   "kube-system",
+		Wait:        true,
+		Force:       true,
+		ValuesYaml:  ingressNginxValues(),
+	}
+
+	if err = installChart(client, "ingress-nginx", &ingressSpec); err != nil {
+		return err
+	}
+
+	return k8s.createIngressControllerPodsService()
+}
+
+// createIngressControllerPodsService creates the headless service of the
+// ingress controller pods. Their addresses change when the pods are replaced,
+// so the faucet resolves the name of the service instead.
+func (k8s *Kubernetes) createIngressControllerPodsService() error {
+	fmt.Println("creating " + ingressControllerPods + " service")
+
+	_, err := k8s.Client.CoreV1().Services("kube-system").Create(context.Background(), &apiv1.Service{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: ingressControllerPods,
+		},
+		Spec: apiv1.ServiceSpec{
+			ClusterIP: apiv1.ClusterIPNone,
+			Ports: []apiv1.ServicePort{
+				{Name: "http", Port: 80},
+			},
+			Selector: map[string]string{
+				"app.kubernetes.io/name":      "ingress-nginx",
+				"app.kubernetes.io/instance":  "ingress-nginx",
+				"app.kubernetes.io/component": "controller",
+			},
+		},
+	}, metav1.CreateOptions{})
+
+	if k8serrors.IsAlreadyExists(err) {
+		return nil
+	}
+
+	return err
+}
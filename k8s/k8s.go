@@ -11,9 +11,11 @@ import (
 var config = &cfg.Config
 
 type Kubernetes struct {
-	Client      *kubernetes.Clientset
-	RestConfig  *restclient.Config
-	CurrentNode int
-	mu          sync.Mutex
-	Password    string
+	Client       *kubernetes.Clientset
+	RestConfig   *restclient.Config
+	CurrentNode  int
+	mu           sync.Mutex
+	Password     string
+	CoinbaseKeys map[string]string
+	es           *ESConnection
 }
@@ -0,0 +1,178 @@
+package k8s
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"mime/multipart"
+	"net/http"
+	"os"
+	"path/filepath"
+)
+
+// kibanaTypes are the exported saved object types
+var kibanaTypes = []string{"config", "index-pattern", "search", "visualization", "lens", "dashboard"}
+
+// KibanaURL returns the kibana of the external elasticsearch or the kibana
+// deployed in the network
+func (k8s *Kubernetes) KibanaURL() (string, error) {
+	es, err := k8s.GetESConnection()
+
+	if err != nil {
+		return "", err
+	}
+
+	if es.External {
+		if es.KibanaURL == "" {
+			return "", errors.New("kibana URL of the external elasticsearch is not configured")
+		}
+
+		return es.KibanaURL, nil
+	}
+
+	kibanaURL, err := k8s.GetKibanaURL()
+
+	if err != nil {
+		return "", err
+	}
+
+	return "http://" + kibanaURL, nil
+}
+
+func (k8s *Kubernetes) kibanaRequest(method string, path string, body io.Reader, contentType string) ([]byte, error) {
+	es, err := k8s.GetESConnection()
+
+	if err != nil {
+		return nil, err
+	}
+
+	kibanaURL, err := k8s.KibanaURL()
+
+	if err != nil {
+		return nil, err
+	}
+
+	httpClient, err := es.httpClient()
+
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequest(method, kibanaURL+path, body)
+
+	if err != nil {
+		return nil, err
+	}
+
+	req.Header.Add("kbn-xsrf", "true")
+	req.Header.Set("Content-Type", contentType)
+	req.Header.Add("Authorization", "Basic "+basicAuth(es.Username, es.Password))
+
+	resp, err := httpClient.Do(req)
+
+	if err != nil {
+		return nil, err
+	}
+
+	defer resp.Body.Close()
+
+	respBody, err := ioutil.ReadAll(resp.Body)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if !(resp.StatusCode >= 200 && resp.StatusCode <= 299) {
+		return nil, errors.New(string(respBody))
+	}
+
+	return respBody, nil
+}
+
+// ImportKibanaSavedObjects imports a file exported with the kibana export API
+// and overwrites the existing objects
+func (k8s *Kubernetes) ImportKibanaSavedObjects(path string) error {
+	payload := &bytes.Buffer{}
+	writer := multipart.NewWriter(payload)
+
+	file, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	part, err := writer.CreateFormFile("file", filepath.Base(path))
+	if err != nil {
+		return err
+	}
+	_, err = io.Copy(part, file)
+	if err != nil {
+		return err
+	}
+
+	err = writer.Close()
+	if err != nil {
+		return err
+	}
+
+	data, err := k8s.kibanaRequest(http.MethodPost, "/api/saved_objects/_import?overwrite=true", payload, writer.FormDataContentType())
+
+	if err != nil {
+		return err
+	}
+
+	response := struct {
+		Success      bool `json:"success"`
+		SuccessCount int  `json:"successCount"`
+		Errors       []struct {
+			Type  string `json:"type"`
+			ID    string `json:"id"`
+			Error struct {
+				Type string `json:"type"`
+			} `json:"error"`
+		} `json:"errors"`
+	}{}
+
+	if err = json.Unmarshal(data, &response); err != nil {
+		return err
+	}
+
+	if !response.Success {
+		message := fmt.Sprintf("imported %d saved objects, failed to import", response.SuccessCount)
+
+		for _, importErr := range response.Errors {
+			message += fmt.Sprintf(" %s %s (%s)", importErr.Type, importErr.ID, importErr.Error.Type)
+		}
+
+		return errors.New(message)
+	}
+
+	fmt.Printf("imported %d saved objects\n", response.SuccessCount)
+
+	return nil
+}
+
+// ExportKibanaSavedObjects exports the dashboards, visualizations, searches
+// and index patterns together with the objects they reference
+func (k8s *Kubernetes) ExportKibanaSavedObjects(path string) error {
+	body, err := json.Marshal(map[string]interface{}{
+		"type":                  kibanaTypes,
+		"includeReferencesDeep": true,
+		"excludeExportDetails":  true,
+	})
+
+	if err != nil {
+		return err
+	}
+
+	data, err := k8s.kibanaRequest(http.MethodPost, "/api/saved_objects/_export", bytes.NewBuffer(body), "application/json")
+
+	if err != nil {
+		return err
+	}
+
+	return ioutil.WriteFile(path, data, 0644)
+}
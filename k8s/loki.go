@@ -0,0 +1,149 @@
+package k8s
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"strconv"
+
+	helm "github.com/mittwald/go-helm-client"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// lokiDataSource is merged into the values of the metrics stack
+const lokiDataSource = `
+grafana:
+  additionalDataSources:
+    - name: Loki
+      type: loki
+      url: http://loki.default:3100
+`
+
+// GetLogBackend detects the log backend deployed in the network, elk is also
+// returned if the logs are sent to an external elasticsearch
+func (k8s *Kubernetes) GetLogBackend() (string, error) {
+	_, err := k8s.Client.CoreV1().Secrets("default").Get(context.Background(), esExternalSecret, metav1.GetOptions{})
+
+	if err == nil {
+		return "elk", nil
+	}
+
+	if !k8serrors.IsNotFound(err) {
+		return "", err
+	}
+
+	for _, backend := range []struct {
+		name    string
+		service string
+	}{{"elk", "elasticsearch-master"}, {"loki", "loki"}} {
+		_, err := k8s.Client.CoreV1().Services("default").Get(context.Background(), backend.service, metav1.GetOptions{})
+
+		if err == nil {
+			return backend.name, nil
+		}
+
+		if !k8serrors.IsNotFound(err) {
+			return "", err
+		}
+	}
+
+	return "none", nil
+}
+
+// DeployLoki deploys loki and promtail, and grafana unless the metrics stack
+// is deployed. Logs are deleted after LogsExpiry days.
+func (k8s *Kubernetes) DeployLoki() error {
+	opt := &helm.RestConfClientOptions{
+		Options: &helm.Options{
+			Debug:   true,
+			Linting: true,
+		},
+		RestConfig: k8s.RestConfig,
+	}
+
+	client, err := helm.NewClientFromRestConf(opt)
+	if err != nil {
+		return err
+	}
+
+	logsExpiry, err := strconv.Atoi(config.LogsExpiry)
+
+	if err != nil {
+		return errors.New("invalid logs expiry " + config.LogsExpiry)
+	}
+
+	if !config.Metrics {
+		if err = k8s.createGrafanaAdmin("default"); err != nil {
+			return err
+		}
+	}
+
+	lokiSpec := helm.ChartSpec{
+		ReleaseName: "loki",
+		Namespace:   "default",
+		Wait:        true,
+		Force:       true,
+		ValuesYaml: sanitizeYaml(fmt.Sprintf(`
+			loki:
+				persistence:
+					enabled: true
+					size: %sGi
+				config:
+					table_manager:
+						retention_deletes_enabled: true
+						retention_period: %dh
+			promtail:
+				config:
+					snippets:
+						pipelineStages:
+							- cri: {}
+						extraRelabelConfigs:
+							- source_labels: [__meta_kubernetes_pod_label_app_kubernetes_io_instance]
+								regex: (.+)
+								target_label: name
+							- source_labels: [__meta_kubernetes_pod_label_name]
+								regex: (.+)
+								target_label: name
+			grafana:
+				enabled: %t
+				admin:
+					existingSecret: %s
+				ingress:
+					enabled: true
+					annotations:
+						kubernetes.io/ingress.class: nginx
+					hosts:
+						- %s
+					tls: %s
+		`, config.LokiDiskSize, logsExpiry*24, !config.Metrics, grafanaAdminSecret, Domain("grafana"), ingressTLS("grafana-tls", "grafana"))),
+	}
+
+	if err = installChart(client, "loki-stack", &lokiSpec); err != nil {
+		return err
+	}
+
+	if config.Metrics {
+		return nil
+	}
+
+	if err = k8s.createCertificate("default", "grafana-tls", "grafana"); err != nil {
+		return err
+	}
+
+	return k8s.createDNSRecords("loki", "default", "loki-grafana")
+}
+
+// LokiRequest sends a GET request to the loki HTTP API and returns the response
+// body. Loki has no authentication so it's only reachable in the cluster, the
+// request goes through the service proxy of the API server.
+func (k8s *Kubernetes) LokiRequest(path string, query url.Values) ([]byte, error) {
+	params := map[string]string{}
+
+	for key := range query {
+		params[key] = query.Get(key)
+	}
+
+	return k8s.Client.CoreV1().Services("default").ProxyGet("http", "loki", "http-metrics", path, params).DoRaw(context.Background())
+}
@@ -0,0 +1,302 @@
+package k8s
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+
+	helm "github.com/mittwald/go-helm-client"
+	"github.com/spacemeshos/go-spacecraft/secrets"
+	"golang.org/x/crypto/bcrypt"
+	apiv1 "k8s.io/api/core/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const metricsNamespace = "metrics"
+const alertmanagerSecret = "alertmanager-spacecraft"
+const prometheusAuthSecret = "prometheus-basic-auth"
+const grafanaAdminSecret = "grafana-admin"
+
+// grafanaDefaultPassword is the admin password of the grafana of networks
+// deployed by earlier versions
+const grafanaDefaultPassword = "prom-operator"
+
+// DeployMetrics deploys prometheus and grafana using the kube-prometheus-stack
+// chart. Prometheus scrapes the metrics port of every miner directly.
+func (k8s *Kubernetes) DeployMetrics() error {
+	namespaceClient := k8s.Client.CoreV1().Namespaces()
+
+	namespace := &apiv1.Namespace{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: metricsNamespace,
+		},
+	}
+
+	if _, err := namespaceClient.Create(context.TODO(), namespace, metav1.CreateOptions{}); err != nil {
+		return err
+	}
+
+	if err := k8s.createGrafanaDashboards(); err != nil {
+		return err
+	}
+
+	if err := k8s.createAlertmanagerConfig(); err != nil {
+		return err
+	}
+
+	if err := k8s.createPrometheusAuth(); err != nil {
+		return err
+	}
+
+	if err := k8s.createGrafanaAdmin(metricsNamespace); err != nil {
+		return err
+	}
+
+	opt := &helm.RestConfClientOptions{
+		Options: &helm.Options{
+			Debug:     true,
+			Linting:   true,
+			Namespace: metricsNamespace,
+		},
+		RestConfig: k8s.RestConfig,
+	}
+
+	client, err := helm.NewClientFromRestConf(opt)
+	if err != nil {
+		return err
+	}
+
+	prometheusSpec := helm.ChartSpec{
+		ReleaseName: "prometheus",
+		Namespace:   metricsNamespace,
+		Wait:        true,
+		Force:       true,
+		ValuesYaml: sanitizeYaml(fmt.Sprintf(`
+			alertmanager:
+				alertmanagerSpec:
+					useExistingSecret: true
+					configSecret: %s
+			grafana:
+				admin:
+					existingSecret: %s
+				ingress:
+					enabled: true
+					annotations:
+						kubernetes.io/ingress.class: nginx
+					hosts:
+						- %s
+					tls: %s
+				sidecar:
+					dashboards:
+						enabled: true
+						label: grafana_dashboard
+			prometheus:
+				ingress:
+					enabled: true
+					annotations:
+						kubernetes.io/ingress.class: nginx
+						nginx.ingress.kubernetes.io/auth-type: basic
+						nginx.ingress.kubernetes.io/auth-secret: %s
+						nginx.ingress.kubernetes.io/auth-realm: Prometheus
+					hosts:
+						- %s
+					tls: %s
+				prometheusSpec:
+					retention: %s
+					storageSpec:
+						volumeClaimTemplate:
+							spec:
+								accessModes: [ "ReadWriteOnce" ]
+								resources:
+									requests:
+										storage: %sGi
+					additionalScrapeConfigs:
+						- job_name: spacemesh-miners
+							kubernetes_sd_configs:
+								- role: endpoints
+									namespaces:
+										names: [ "default" ]
+							relabel_configs:
+								- source_labels: [__meta_kubernetes_service_label_app, __meta_kubernetes_endpoint_port_name]
+									regex: miner;metrics
+									action: keep
+								- source_labels: [__meta_kubernetes_service_label_name]
+									target_label: miner
+								- source_labels: [__meta_kubernetes_pod_label_group]
+									target_label: group
+								- source_labels: [__meta_kubernetes_pod_label_cohort]
+									target_label: cohort
+								- source_labels: [__meta_kubernetes_pod_label_role]
+									target_label: role
+		`, alertmanagerSecret, grafanaAdminSecret, Domain("grafana"), ingressTLS("grafana-tls", "grafana"), prometheusAuthSecret, Domain("prometheus"), ingressTLS("prometheus-tls", "prometheus"), config.MetricsRetention, config.PrometheusDiskSize)),
+	}
+
+	if config.LogBackend == "loki" {
+		prometheusSpec.ValuesYaml, err = mergeValues(prometheusSpec.ValuesYaml, lokiDataSource)
+
+		if err != nil {
+			return err
+		}
+	}
+
+	if err = installChart(client, "kube-prometheus-stack", &prometheusSpec); err != nil {
+		return err
+	}
+
+	if err = k8s.createCertificate(metricsNamespace, "grafana-tls", "grafana"); err != nil {
+		return err
+	}
+
+	if err = k8s.createCertificate(metricsNamespace, "prometheus-tls", "prometheus"); err != nil {
+		return err
+	}
+
+	if err = k8s.CreateAlertRules(); err != nil {
+		return err
+	}
+
+	return k8s.createDNSRecords("metrics", metricsNamespace, "prometheus-grafana")
+}
+
+// randomPassword returns password, or a random password if it's empty. The
+// password is registered to be redacted from the output.
+func randomPassword(password string) (string, error) {
+	if password == "" {
+		data := make([]byte, 16)
+
+		if _, err := rand.Read(data); err != nil {
+			return "", err
+		}
+
+		password = hex.EncodeToString(data)
+	}
+
+	secrets.Add(password)
+
+	return password, nil
+}
+
+// createGrafanaAdmin creates the secret with the credentials of the grafana
+// admin, which grafana reads instead of the well known default password of
+// the chart. An existing secret is kept, as grafana only sets the password
+// of the admin when its database is created.
+func (k8s *Kubernetes) createGrafanaAdmin(namespace string) error {
+	password, err := randomPassword(config.GrafanaPassword)
+
+	if err != nil {
+		return err
+	}
+
+	secret := &apiv1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: grafanaAdminSecret,
+		},
+		Data: map[string][]byte{
+			"admin-user":     []byte("admin"),
+			"admin-password": []byte(password),
+		},
+	}
+
+	_, err = k8s.Client.CoreV1().Secrets(namespace).Create(context.Background(), secret, metav1.CreateOptions{})
+
+	if k8serrors.IsAlreadyExists(err) {
+		return nil
+	}
+
+	return err
+}
+
+// GrafanaPassword returns the password of the grafana admin of the network
+func (k8s *Kubernetes) GrafanaPassword() (string, error) {
+	for _, namespace := range []string{metricsNamespace, "default"} {
+		secret, err := k8s.Client.CoreV1().Secrets(namespace).Get(context.Background(), grafanaAdminSecret, metav1.GetOptions{})
+
+		if err == nil {
+			return string(secret.Data["admin-password"]), nil
+		}
+
+		if !k8serrors.IsNotFound(err) {
+			return "", err
+		}
+	}
+
+	return grafanaDefaultPassword, nil
+}
+
+// createPrometheusAuth creates the htpasswd secret of the basic auth of the
+// prometheus ingress, as prometheus has no authentication itself. The password
+// is also kept in the secret so it can be read back with kubectl.
+func (k8s *Kubernetes) createPrometheusAuth() error {
+	password, err := randomPassword(config.PrometheusPassword)
+
+	if err != nil {
+		return err
+	}
+
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+
+	if err != nil {
+		return err
+	}
+
+	secret := &apiv1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: prometheusAuthSecret,
+		},
+		Data: map[string][]byte{
+			"auth":     []byte("admin:" + string(hash)),
+			"password": []byte(password),
+		},
+	}
+
+	_, err = k8s.Client.CoreV1().Secrets(metricsNamespace).Create(context.Background(), secret, metav1.CreateOptions{})
+
+	return err
+}
+
+// createGrafanaDashboards creates a config map for every dashboard in the
+// GrafanaDashboards directory which is loaded by the grafana sidecar
+func (k8s *Kubernetes) createGrafanaDashboards() error {
+	files, err := filepath.Glob(filepath.Join(config.GrafanaDashboards, "*.json"))
+
+	if err != nil {
+		return err
+	}
+
+	configMapClient := k8s.Client.CoreV1().ConfigMaps(metricsNamespace)
+
+	for _, file := range files {
+		data, err := ioutil.ReadFile(file)
+
+		if err != nil {
+			return err
+		}
+
+		name := strings.TrimSuffix(filepath.Base(file), ".json")
+
+		fmt.Println("creating grafana dashboard " + name)
+
+		_, err = configMapClient.Create(context.Background(), &apiv1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: "dashboard-" + name,
+				Labels: map[string]string{
+					"grafana_dashboard": "1",
+				},
+			},
+			Data: map[string]string{
+				filepath.Base(file): string(data),
+			},
+		}, metav1.CreateOptions{})
+
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
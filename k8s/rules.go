@@ -0,0 +1,166 @@
+package k8s
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+
+	apiv1 "k8s.io/api/core/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic"
+	"sigs.k8s.io/yaml"
+)
+
+var prometheusRuleResource = schema.GroupVersionResource{Group: "monitoring.coreos.com", Version: "v1", Resource: "prometheusrules"}
+
+// alertmanagerConfig routes all alerts to the configured sinks. The always
+// firing Watchdog alert of kube-prometheus is dropped.
+func alertmanagerConfig() map[string]interface{} {
+	receiver := map[string]interface{}{"name": "spacecraft"}
+
+	if config.SlackToken != "" && config.SlackChannelId != "" {
+		receiver["slack_configs"] = []interface{}{
+			map[string]interface{}{
+				"api_url":       "https://slack.com/api/chat.postMessage",
+				"channel":       config.SlackChannelId,
+				"send_resolved": true,
+				"title":         "[" + config.NetworkName + "] {{ .CommonLabels.alertname }}",
+				"text":          "{{ range .Alerts }}{{ .Annotations.summary }}\n{{ end }}",
+				"http_config": map[string]interface{}{
+					"authorization": map[string]interface{}{
+						"credentials": config.SlackToken,
+					},
+				},
+			},
+		}
+	}
+
+	if config.AlertWebhookURL != "" {
+		receiver["webhook_configs"] = []interface{}{
+			map[string]interface{}{
+				"url":           config.AlertWebhookURL,
+				"send_resolved": true,
+			},
+		}
+	}
+
+	if config.PagerDutyRoutingKey != "" {
+		receiver["pagerduty_configs"] = []interface{}{
+			map[string]interface{}{
+				"routing_key": config.PagerDutyRoutingKey,
+				"description": "[" + config.NetworkName + "] {{ .CommonLabels.alertname }}",
+			},
+		}
+	}
+
+	return map[string]interface{}{
+		"route": map[string]interface{}{
+			"receiver":        "spacecraft",
+			"group_by":        []interface{}{"alertname", "miner"},
+			"group_wait":      "30s",
+			"group_interval":  "5m",
+			"repeat_interval": "4h",
+			"routes": []interface{}{
+				map[string]interface{}{
+					"receiver": "null",
+					"match":    map[string]interface{}{"alertname": "Watchdog"},
+				},
+			},
+		},
+		"receivers": []interface{}{
+			receiver,
+			map[string]interface{}{"name": "null"},
+		},
+	}
+}
+
+func (k8s *Kubernetes) createAlertmanagerConfig() error {
+	data, err := yaml.Marshal(alertmanagerConfig())
+
+	if err != nil {
+		return err
+	}
+
+	fmt.Println("creating alertmanager config")
+
+	_, err = k8s.Client.CoreV1().Secrets(metricsNamespace).Create(context.Background(), &apiv1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: alertmanagerSecret,
+		},
+		Data: map[string][]byte{
+			"alertmanager.yaml": data,
+		},
+	}, metav1.CreateOptions{})
+
+	return err
+}
+
+// CreateAlertRules creates or replaces a PrometheusRule for every rules file
+// in the AlertRules directory. The release label makes prometheus load them.
+func (k8s *Kubernetes) CreateAlertRules() error {
+	files, err := filepath.Glob(filepath.Join(config.AlertRules, "*.yaml"))
+
+	if err != nil {
+		return err
+	}
+
+	client, err := dynamic.NewForConfig(k8s.RestConfig)
+
+	if err != nil {
+		return err
+	}
+
+	rulesClient := client.Resource(prometheusRuleResource).Namespace(metricsNamespace)
+
+	for _, file := range files {
+		data, err := ioutil.ReadFile(file)
+
+		if err != nil {
+			return err
+		}
+
+		spec := map[string]interface{}{}
+
+		if err = yaml.Unmarshal(data, &spec); err != nil {
+			return fmt.Errorf("%s: %w", file, err)
+		}
+
+		name := "spacecraft-" + strings.TrimSuffix(filepath.Base(file), ".yaml")
+
+		object := &unstructured.Unstructured{Object: map[string]interface{}{
+			"apiVersion": "monitoring.coreos.com/v1",
+			"kind":       "PrometheusRule",
+			"metadata": map[string]interface{}{
+				"name":      name,
+				"namespace": metricsNamespace,
+				"labels": map[string]interface{}{
+					"release":                      "prometheus",
+					"app.kubernetes.io/managed-by": "spacecraft",
+				},
+			},
+			"spec": spec,
+		}}
+
+		fmt.Println("creating alert rules " + name)
+
+		existing, err := rulesClient.Get(context.Background(), name, metav1.GetOptions{})
+
+		if err == nil {
+			object.SetResourceVersion(existing.GetResourceVersion())
+			_, err = rulesClient.Update(context.Background(), object, metav1.UpdateOptions{})
+		} else if k8serrors.IsNotFound(err) {
+			_, err = rulesClient.Create(context.Background(), object, metav1.CreateOptions{})
+		}
+
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
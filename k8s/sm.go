@@ -1,11 +1,9 @@
 package k8s
 
 import (
-	"bytes"
 	"context"
 	"errors"
 	"fmt"
-	"io"
 	"strconv"
 	"strings"
 	"time"
@@ -19,15 +17,19 @@ import (
 	intstr "k8s.io/apimachinery/pkg/util/intstr"
 	v1beta1 "k8s.io/client-go/kubernetes/typed/policy/v1beta1"
 
-	"crypto/ecdsa"
+	cfg "github.com/spacemeshos/go-spacecraft/config"
+)
 
-	"github.com/ethereum/go-ethereum/common/hexutil"
-	"github.com/ethereum/go-ethereum/crypto"
+const (
+	RoleBootstrap = "bootstrap"
+	RoleBootnode  = "bootnode"
+	RoleMiner     = "miner"
 )
 
 type MinerDeploymentData struct {
-	TcpURL  string
-	GrpcURL string
+	MinerNumber string
+	TcpURL      string
+	GrpcURL     string
 }
 
 type PoetDeploymentData struct {
@@ -76,48 +78,14 @@ func (k8s *Kubernetes) GetExternalPort(serviceId string, portName string) (strin
 	return "", errors.New("port not found")
 }
 
-func (k8s *Kubernetes) getNodeId(podName string) (string, error) {
-	for range time.Tick(5 * time.Second) {
-		podLogOpts := corev1.PodLogOptions{}
-		req := k8s.Client.CoreV1().Pods("default").GetLogs(podName, &podLogOpts)
-		podLogs, err := req.Stream(context.Background())
-		defer podLogs.Close()
-
-		if err != nil {
-			return "", err
-		}
-
-		buf := new(bytes.Buffer)
-		_, err = io.Copy(buf, podLogs)
-		if err != nil {
-			return "", err
-		}
-
-		str := buf.String()
-
-		res := strings.SplitAfter(str, "Local node identity >> ")
-
-		if len(res) >= 2 {
-			res = strings.SplitAfter(res[1], "\"")
-			resFinal := strings.TrimSuffix(res[0], "\"")
-
-			return resFinal, nil
-		} else {
-
-			res = strings.SplitAfter(str, "\",\"identity\":\"")
-
-			if len(res) >= 2 {
-				res = strings.SplitAfter(res[1], "\"")
-				resFinal := strings.TrimSuffix(res[0], "\"")
+func minerBindPort(minerNumber string) int32 {
+	minerNumberInt, _ := strconv.Atoi(minerNumber)
 
-				return resFinal, nil
-			} else {
-				fmt.Println(podName + ": identity not found. Re-fetching logs. \n " + str)
-			}
-		}
-	}
+	return int32(minerNumberInt + 5000)
+}
 
-	return "", nil
+func minerAddress(externalIP string, minerNumber string, peerID string) string {
+	return fmt.Sprintf("/ip4/%s/tcp/%d/p2p/%s", externalIP, minerBindPort(minerNumber), peerID)
 }
 
 func (k8s *Kubernetes) createPVC(name string, size string) error {
@@ -265,10 +233,10 @@ func (k8s *Kubernetes) DisablePodRescheduling() error {
 	return nil
 }
 
-func (k8s *Kubernetes) DeployMiner(bootstrapNode bool, minerNumber string, configJSON string, selectedNode string, channel *MinerChannel) {
+func (k8s *Kubernetes) DeployMiner(role string, minerNumber string, group cfg.MinerGroup, configJSON string, selectedNode string, channel *MinerChannel) {
 	fmt.Println("creating miner-" + minerNumber + " pvc")
 
-	err := k8s.createPVC("miner-"+minerNumber, config.MinerDiskSize)
+	err := k8s.createPVC("miner-"+minerNumber, group.DiskSize)
 
 	if err != nil {
 		channel.Err <- err
@@ -293,18 +261,22 @@ func (k8s *Kubernetes) DeployMiner(bootstrapNode bool, minerNumber string, confi
 
 	deploymentClient := k8s.Client.AppsV1().Deployments(apiv1.NamespaceDefault)
 
-	minerNumberInt, _ := strconv.Atoi(minerNumber)
-	bindPort := int32(minerNumberInt + 5000)
+	bindPort := minerBindPort(minerNumber)
 	bindPortStr := strconv.Itoa(int(bindPort))
 
-	privateKey, _ := crypto.GenerateKey()
-	privateKeyBytes := crypto.FromECDSA(privateKey)
-	publicKey := privateKey.Public()
-	publicKeyECDSA, _ := publicKey.(*ecdsa.PublicKey)
-	compressedPubkey := crypto.CompressPubkey(publicKeyECDSA)
+	peerID, err := k8s.getOrCreateMinerIdentity(minerNumber)
+
+	if err != nil {
+		channel.Err <- err
+		return
+	}
+
+	privateKeyHex, publicKeyHex, err := k8s.coinbaseKey(minerNumber)
 
-	privateKeyHex := hexutil.Encode(privateKeyBytes)
-	publicKeyHex := hexutil.Encode(compressedPubkey)
+	if err != nil {
+		channel.Err <- err
+		return
+	}
 
 	fmt.Println("creating miner-" + minerNumber + " coinbase secret")
 
@@ -316,6 +288,7 @@ func (k8s *Kubernetes) DeployMiner(bootstrapNode bool, minerNumber string, confi
 		StringData: map[string]string{
 			"privateKey": privateKeyHex,
 			"publicKey":  publicKeyHex,
+			"curve":      CoinbaseKeyCurve,
 		},
 	}
 	_, err = secretsClient.Create(context.Background(), secret, metav1.CreateOptions{})
@@ -326,6 +299,8 @@ func (k8s *Kubernetes) DeployMiner(bootstrapNode bool, minerNumber string, confi
 	}
 
 	command := []string{
+		"mkdir -p " + p2pKeyDir + " &&",
+		"cp /etc/identity/p2p.key " + p2pKeyDir + "/p2p.key &&",
 		"/bin/go-spacemesh",
 		fmt.Sprintf("--listen=/ip4/0.0.0.0/tcp/%s", bindPortStr),
 		"--json-server=true",
@@ -353,7 +328,7 @@ func (k8s *Kubernetes) DeployMiner(bootstrapNode bool, minerNumber string, confi
 		command = append(command, "--pprof-server")
 
 		// when pyroscope scaling is done remove this if condition
-		if minerNumber == "10" || minerNumber == "20" {
+		if group.Profile || (len(config.MinerGroups) == 0 && (minerNumber == "10" || minerNumber == "20")) {
 			command = append(command, "--profiler-url=http://"+pyroscopeURL)
 			command = append(command, "--profiler-name=miner-"+minerNumber)
 		}
@@ -361,9 +336,13 @@ func (k8s *Kubernetes) DeployMiner(bootstrapNode bool, minerNumber string, confi
 
 	if config.Metrics {
 		command = append(command, "--metrics")
-		command = append(command, "--metrics-push="+config.PushGatewayURL)
+
+		if config.PushGatewayURL != "" {
+			command = append(command, "--metrics-push="+config.PushGatewayURL)
+		}
 	}
 
+	command = append(command, group.Flags...)
 	command = append(command, "; sleep 100000000")
 
 	envs := []apiv1.EnvVar{}
@@ -378,6 +357,11 @@ func (k8s *Kubernetes) DeployMiner(bootstrapNode bool, minerNumber string, confi
 	deployment := &appsv1.Deployment{
 		ObjectMeta: metav1.ObjectMeta{
 			Name: "miner-" + minerNumber,
+			Labels: map[string]string{
+				"group":  group.Name,
+				"cohort": group.Cohort(),
+				"role":   role,
+			},
 		},
 		Spec: appsv1.DeploymentSpec{
 			Replicas: int32Ptr(1),
@@ -395,13 +379,16 @@ func (k8s *Kubernetes) DeployMiner(bootstrapNode bool, minerNumber string, confi
 						"name":    "miner-" + minerNumber,
 						"restart": "false",
 						"app":     "miner",
+						"group":   group.Name,
+						"cohort":  group.Cohort(),
+						"role":    role,
 					},
 				},
 				Spec: apiv1.PodSpec{
 					Containers: []apiv1.Container{
 						{
 							Name:    "miner",
-							Image:   config.GoSmImage,
+							Image:   group.Image,
 							Command: []string{"/bin/sh", "-c"},
 							Args:    []string{strings.Join(command[:], " ")},
 							Env:     envs,
@@ -434,12 +421,12 @@ func (k8s *Kubernetes) DeployMiner(bootstrapNode bool, minerNumber string, confi
 							},
 							Resources: apiv1.ResourceRequirements{
 								Limits: apiv1.ResourceList{
-									"cpu":    resource.MustParse(config.MinerCPU),
-									"memory": resource.MustParse(config.MinerMemory + "Gi"),
+									"cpu":    resource.MustParse(group.CPU),
+									"memory": resource.MustParse(group.Memory + "Gi"),
 								},
 								Requests: apiv1.ResourceList{
-									"cpu":    resource.MustParse(config.MinerCPU),
-									"memory": resource.MustParse(config.MinerMemory + "Gi"),
+									"cpu":    resource.MustParse(group.CPU),
+									"memory": resource.MustParse(group.Memory + "Gi"),
 								},
 							},
 							VolumeMounts: []apiv1.VolumeMount{
@@ -451,6 +438,11 @@ func (k8s *Kubernetes) DeployMiner(bootstrapNode bool, minerNumber string, confi
 									Name:      "data",
 									MountPath: "/root/data",
 								},
+								{
+									Name:      "identity",
+									MountPath: "/etc/identity",
+									ReadOnly:  true,
+								},
 							},
 						},
 					},
@@ -465,6 +457,14 @@ func (k8s *Kubernetes) DeployMiner(bootstrapNode bool, minerNumber string, confi
 								},
 							},
 						},
+						{
+							Name: "identity",
+							VolumeSource: apiv1.VolumeSource{
+								Secret: &apiv1.SecretVolumeSource{
+									SecretName: "miner-" + minerNumber + "-identity",
+								},
+							},
+						},
 						{
 							Name: "data",
 							VolumeSource: apiv1.VolumeSource{
@@ -511,7 +511,7 @@ func (k8s *Kubernetes) DeployMiner(bootstrapNode bool, minerNumber string, confi
 
 	fmt.Println("finished miner-" + minerNumber + " deployment")
 
-	nodeName, podName, err := k8s.getDeploymentPodAndNode("miner-" + minerNumber)
+	nodeName, _, err := k8s.getDeploymentPodAndNode("miner-" + minerNumber)
 
 	if err != nil {
 		channel.Err <- err
@@ -604,14 +604,9 @@ func (k8s *Kubernetes) DeployMiner(bootstrapNode bool, minerNumber string, confi
 		return
 	}
 
-	nodeId, err := k8s.getNodeId(podName)
-
-	if err != nil {
-		channel.Err <- err
-		return
-	}
 	channel.Done <- &MinerDeploymentData{
-		fmt.Sprintf("/ip4/%s/tcp/%s/p2p/%s", externalIP, bindPortStr, nodeId),
+		minerNumber,
+		minerAddress(externalIP, minerNumber, peerID),
 		externalIP + ":" + apiPort,
 	}
 }
@@ -670,6 +665,7 @@ func (k8s *Kubernetes) DeployPoet(initialDuration string, poetNumber string, con
 					Labels: map[string]string{
 						"name":    "poet-" + poetNumber,
 						"restart": "false",
+						"app":     "poet",
 					},
 				},
 				Spec: apiv1.PodSpec{
@@ -858,7 +854,107 @@ func (k8s *Kubernetes) GetMiners() ([]string, error) {
 	return miners, nil
 }
 
-func (k8s *Kubernetes) UpdateImageOfMiners(name string) error {
+type MinerInfo struct {
+	Name   string
+	Group  string
+	Cohort string
+	Image  string
+	Ready  bool
+	// Command is the shell command the miner is started with
+	Command string
+}
+
+// GetMinersInfo returns the inventory of managed miners
+func (k8s *Kubernetes) GetMinersInfo() ([]MinerInfo, error) {
+	deploymentClient := k8s.Client.AppsV1().Deployments(apiv1.NamespaceDefault)
+	deployments, err := deploymentClient.List(context.TODO(), metav1.ListOptions{})
+	if err != nil {
+		return []MinerInfo{}, err
+	}
+
+	miners := []MinerInfo{}
+
+	for _, deployment := range deployments.Items {
+		if !strings.Contains(deployment.Name, "miner-") {
+			continue
+		}
+
+		labels := deployment.Spec.Template.Labels
+		info := MinerInfo{
+			Name:   deployment.Name,
+			Group:  labels["group"],
+			Cohort: labels["cohort"],
+			Image:  deployment.Spec.Template.Spec.Containers[0].Image,
+			Ready:  deployment.Status.ReadyReplicas == 1,
+		}
+
+		if args := deployment.Spec.Template.Spec.Containers[0].Args; len(args) > 0 {
+			info.Command = args[len(args)-1]
+		}
+
+		if info.Group == "" {
+			info.Group = cfg.DefaultMinerGroup
+		}
+
+		if info.Cohort == "" {
+			info.Cohort = cfg.HonestCohort
+		}
+
+		miners = append(miners, info)
+	}
+
+	return miners, nil
+}
+
+// GetMinerConfigs returns the go-spacemesh config of every miner by miner name
+func (k8s *Kubernetes) GetMinerConfigs() (map[string]string, error) {
+	configMaps, err := k8s.Client.CoreV1().ConfigMaps(apiv1.NamespaceDefault).List(context.TODO(), metav1.ListOptions{})
+
+	if err != nil {
+		return nil, err
+	}
+
+	configs := map[string]string{}
+
+	for _, configMap := range configMaps.Items {
+		if !strings.HasPrefix(configMap.Name, "miner-") {
+			continue
+		}
+
+		if configJSON, ok := configMap.Data["config.json"]; ok {
+			configs[configMap.Name] = configJSON
+		}
+	}
+
+	return configs, nil
+}
+
+// GetMinerGroups returns the group of every miner by miner name
+func (k8s *Kubernetes) GetMinerGroups() (map[string]string, error) {
+	deploymentClient := k8s.Client.AppsV1().Deployments(apiv1.NamespaceDefault)
+	deployments, err := deploymentClient.List(context.TODO(), metav1.ListOptions{})
+	if err != nil {
+		return map[string]string{}, err
+	}
+
+	groups := map[string]string{}
+
+	for _, deployment := range deployments.Items {
+		if strings.Contains(deployment.Name, "miner-") {
+			group, ok := deployment.Spec.Template.Labels["group"]
+
+			if !ok {
+				group = cfg.DefaultMinerGroup
+			}
+
+			groups[deployment.Name] = group
+		}
+	}
+
+	return groups, nil
+}
+
+func (k8s *Kubernetes) UpdateImageOfMiners(name string, image string) error {
 	fmt.Println("updating image of " + name)
 	deploymentClient := k8s.Client.AppsV1().Deployments(apiv1.NamespaceDefault)
 	deployment, err := deploymentClient.Get(context.TODO(), name, metav1.GetOptions{})
@@ -866,7 +962,7 @@ func (k8s *Kubernetes) UpdateImageOfMiners(name string) error {
 		return err
 	}
 
-	deployment.Spec.Template.Spec.Containers[0].Image = config.GoSmImage
+	deployment.Spec.Template.Spec.Containers[0].Image = image
 
 	_, err = deploymentClient.Update(context.TODO(), deployment, metav1.UpdateOptions{})
 	if err != nil {
@@ -908,20 +1004,21 @@ func (k8s *Kubernetes) GetExternalIP() (string, error) {
 	return "", errors.New("public ip of cluster not found")
 }
 
-func (k8s *Kubernetes) MinerAccounts() ([]string, error) {
+// MinerAccounts returns the name of the managed miner by coinbase address
+func (k8s *Kubernetes) MinerAccounts() (map[string]string, error) {
 	secretsClient := k8s.Client.CoreV1().Secrets("default")
 	secrets, err := secretsClient.List(context.Background(), metav1.ListOptions{})
 
 	if err != nil {
-		return []string{}, err
+		return map[string]string{}, err
 	}
 
-	addresses := []string{}
+	addresses := map[string]string{}
 
 	for _, secret := range secrets.Items {
 		if val, ok := secret.Data["publicKey"]; ok {
 			publicKey := string(val)
-			addresses = append(addresses, publicKey[len(publicKey)-40:])
+			addresses[publicKey[len(publicKey)-40:]] = strings.TrimSuffix(secret.Name, "-coinbase")
 		}
 	}
 
@@ -11,12 +11,13 @@ import (
 
 	"github.com/Jeffail/gabs/v2"
 	"github.com/spacemeshos/go-spacecraft/gcp"
+	"github.com/spacemeshos/go-spacecraft/secrets"
 	apiv1 "k8s.io/api/core/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 
-	cloudflare "github.com/cloudflare/cloudflare-go"
 	helm "github.com/mittwald/go-helm-client"
-	"helm.sh/helm/v3/pkg/repo"
+	"helm.sh/helm/v3/pkg/action"
 )
 
 type Network struct {
@@ -39,6 +40,11 @@ type Network struct {
 	LatestSmappRelease   string  `json:"latestSmappRelease"`
 	SmappBaseDownloadUrl string  `json:"smappBaseDownloadUrl"`
 	NodeBaseDownloadUrl  string  `json:"nodeBaseDownloadUrl"`
+	Faucet               string  `json:"faucet,omitempty"`
+
+	// Extra are the fields which spacecraft doesn't know, they're kept when
+	// the discovery file is written
+	Extra map[string]json.RawMessage `json:"-"`
 }
 
 func (k8s *Kubernetes) DeployWS() error {
@@ -54,36 +60,32 @@ func (k8s *Kubernetes) DeployWS() error {
 		return err
 	}
 
-	certData, err := ioutil.ReadFile(config.TLSCert)
+	if ManagedTLS() {
+		if err := k8s.createCertificate("ws", "tls", "api", "api-json", "explorer-api", "dash-api"); err != nil {
+			return err
+		}
+	} else if err := k8s.createTLSSecret(); err != nil {
+		return err
+	}
 
-	if err != nil {
+	if err := k8s.DeployIngressNginx(); err != nil {
 		return err
 	}
 
-	keyData, err := ioutil.ReadFile(config.TLSKey)
+	client, err := k8s.wsHelmClient()
 
 	if err != nil {
 		return err
 	}
 
-	tlsSecret := &apiv1.Secret{
-		ObjectMeta: metav1.ObjectMeta{
-			Name: "tls",
-		},
-		Data: map[string][]byte{
-			"tls.crt": certData,
-			"tls.key": keyData,
-		},
-		Type: "kubernetes.io/tls",
-	}
-
-	secretsClient := k8s.Client.CoreV1().Secrets("ws")
-	_, err = secretsClient.Create(context.Background(), tlsSecret, metav1.CreateOptions{})
-
-	if err != nil {
+	if err = installWSCharts(client); err != nil {
 		return err
 	}
 
+	return k8s.createDNSRecords("ws", "ws", "spacemesh-api")
+}
+
+func (k8s *Kubernetes) wsHelmClient() (helm.Client, error) {
 	opt := &helm.RestConfClientOptions{
 		Options: &helm.Options{
 			Debug:     true,
@@ -93,42 +95,11 @@ func (k8s *Kubernetes) DeployWS() error {
 		RestConfig: k8s.RestConfig,
 	}
 
-	client, err := helm.NewClientFromRestConf(opt)
-	if err != nil {
-		return err
-	}
-
-	chartRepo := repo.Entry{
-		Name: "ingress-nginx",
-		URL:  "https://kubernetes.github.io/ingress-nginx",
-	}
-
-	if err := client.AddOrUpdateChartRepo(chartRepo); err != nil {
-		return err
-	}
-
-	ingressSpec := helm.ChartSpec{
-		ReleaseName: "ingress-nginx",
-		ChartName:   "ingress-nginx/ingress-nginx",
-		Namespace:   "kube-system",
-		Wait:        true,
-		Force:       true,
-		Version:     "3.34.0",
-	}
-
-	if err = client.InstallOrUpgradeChart(context.Background(), &ingressSpec); err != nil {
-		return err
-	}
-
-	chartRepo = repo.Entry{
-		Name: "spacemesh",
-		URL:  "https://spacemeshos.github.io/ws-helm-charts",
-	}
-
-	if err := client.AddOrUpdateChartRepo(chartRepo); err != nil {
-		return err
-	}
+	return helm.NewClientFromRestConf(opt)
+}
 
+// installWSCharts installs or upgrades the api, explorer and dash charts
+func installWSCharts(client helm.Client) error {
 	imageSplit := strings.Split(config.GoSmImage, ":")
 
 	tag := ""
@@ -149,7 +120,6 @@ func (k8s *Kubernetes) DeployWS() error {
 
 	spacemeshAPISpec := helm.ChartSpec{
 		ReleaseName: "spacemesh-api",
-		ChartName:   "spacemesh/spacemesh-api",
 		Namespace:   "ws",
 		ValuesYaml: sanitizeYaml(fmt.Sprintf(`
 			resources:
@@ -159,22 +129,21 @@ func (k8s *Kubernetes) DeployWS() error {
 			image:
 				repository: %s
 				tag: %s
-			pagerdutyToken: "cfac67a53df9440ad0e5e0fcfe1933db"
+			pagerdutyToken: "%s"
 			ingress:
-				grpcDomain: api-%s.spacemesh.io
-				jsonRpcDomain: api-json-%s.spacemesh.io
+				grpcDomain: %s
+				jsonRpcDomain: %s
 			config: |
 				%s
-		`, config.MinerMemory, config.MinerCPU, respository, tag, config.NetworkName, config.NetworkName, strings.ReplaceAll(minerConfigStr, "\n", ""))),
+		`, config.MinerMemory, config.MinerCPU, respository, tag, config.PagerDutyToken, Domain("api"), Domain("api-json"), strings.ReplaceAll(minerConfigStr, "\n", ""))),
 	}
 
-	if err = client.InstallOrUpgradeChart(context.Background(), &spacemeshAPISpec); err != nil {
+	if err = installChart(client, "spacemesh-api", &spacemeshAPISpec); err != nil {
 		return err
 	}
 
 	spacemeshExplorerSpec := helm.ChartSpec{
 		ReleaseName: "spacemesh-explorer",
-		ChartName:   "spacemesh/spacemesh-explorer",
 		Namespace:   "ws",
 		ValuesYaml: sanitizeYaml(fmt.Sprintf(`
 			resources:
@@ -184,138 +153,75 @@ func (k8s *Kubernetes) DeployWS() error {
 			imageTag: %s
 			apiServer:
 				ingress:
-					domain: explorer-api-%s.spacemesh.io
+					domain: %s
 			node:
 				image:
 					repository: %s
 					tag: %s
 				config: |
 					%s
-		`, config.MinerMemory, config.MinerCPU, config.ExplorerVersion, config.NetworkName, respository, tag, strings.ReplaceAll(minerConfigStr, "\n", ""))),
+		`, config.MinerMemory, config.MinerCPU, config.ExplorerVersion, Domain("explorer-api"), respository, tag, strings.ReplaceAll(minerConfigStr, "\n", ""))),
 	}
 
-	if err = client.InstallOrUpgradeChart(context.Background(), &spacemeshExplorerSpec); err != nil {
+	if err = installChart(client, "spacemesh-explorer", &spacemeshExplorerSpec); err != nil {
 		return err
 	}
 
 	spacemeshDashSpec := helm.ChartSpec{
 		ReleaseName: "spacemesh-dash",
-		ChartName:   "spacemesh/spacemesh-dash",
 		Namespace:   "ws",
 		ValuesYaml: sanitizeYaml(fmt.Sprintf(`
 			mongo: mongodb://spacemesh-explorer-mongo
 			ingress:
-				domain: dash-api-%s.spacemesh.io
+				domain: %s
 			image:
 				tag: %s
-		`, config.NetworkName, config.DashboardVersion)),
+		`, Domain("dash-api"), config.DashboardVersion)),
 	}
 
-	if err = client.InstallOrUpgradeChart(context.Background(), &spacemeshDashSpec); err != nil {
+	if err = installChart(client, "spacemesh-dash", &spacemeshDashSpec); err != nil {
 		return err
 	}
 
-	if config.CloudflareAPIToken != "" {
-		ingressClient := k8s.Client.ExtensionsV1beta1().Ingresses("ws")
-
-		for range time.Tick(5 * time.Second) {
-			ingress, err := ingressClient.Get(context.Background(), "spacemesh-api", metav1.GetOptions{})
-
-			if err != nil {
-				return err
-			}
-
-			fmt.Println("waiting for ingress")
-
-			if len(ingress.Status.LoadBalancer.Ingress) == 1 {
-				ip := ingress.Status.LoadBalancer.Ingress[0].IP
-
-				api, err := cloudflare.NewWithAPIToken(config.CloudflareAPIToken)
-
-				if err != nil {
-					return err
-				}
-
-				id, err := api.ZoneIDByName("spacemesh.io")
-
-				if err != nil {
-					return err
-				}
-
-				_, err = api.CreateDNSRecord(context.Background(), id, cloudflare.DNSRecord{
-					Type:    "A",
-					Name:    "api-json-" + config.NetworkName + ".spacemesh.io",
-					Content: ip,
-				})
-
-				if err != nil {
-					return err
-				}
-
-				_, err = api.CreateDNSRecord(context.Background(), id, cloudflare.DNSRecord{
-					Type:    "A",
-					Name:    "api-" + config.NetworkName + ".spacemesh.io",
-					Content: ip,
-				})
-
-				if err != nil {
-					return err
-				}
-
-				proxied := true
-
-				_, err = api.CreateDNSRecord(context.Background(), id, cloudflare.DNSRecord{
-					Type:    "A",
-					Name:    "dash-api-" + config.NetworkName + ".spacemesh.io",
-					Content: ip,
-					Proxied: &proxied,
-				})
-
-				if err != nil {
-					return err
-				}
-
-				_, err = api.CreateDNSRecord(context.Background(), id, cloudflare.DNSRecord{
-					Type:    "A",
-					Name:    "explorer-api-" + config.NetworkName + ".spacemesh.io",
-					Content: ip,
-					Proxied: &proxied,
-				})
-
-				if err != nil {
-					return err
-				}
-
-				break
-			}
-		}
-	}
-
 	return nil
 }
 
-func (k8s *Kubernetes) AddToDiscovery() error {
-	networksConfig, err := gcp.ReadWSConfig()
+// createTLSSecret stores TLSCert and TLSKey in the tls secret used by the
+// ingresses of the web services
+func (k8s *Kubernetes) createTLSSecret() error {
+	certData, err := ioutil.ReadFile(config.TLSCert)
 
 	if err != nil {
 		return err
 	}
 
-	var networks []Network
+	keyData, err := ioutil.ReadFile(config.TLSKey)
 
-	json.Unmarshal([]byte(networksConfig), &networks)
+	if err != nil {
+		return err
+	}
 
-	imageSplit := strings.Split(config.GoSmImage, ":")
+	tlsSecret := &apiv1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "tls",
+		},
+		Data: map[string][]byte{
+			"tls.crt": certData,
+			"tls.key": keyData,
+		},
+		Type: "kubernetes.io/tls",
+	}
 
-	tag := ""
-	image := ""
+	secretsClient := k8s.Client.CoreV1().Secrets("ws")
+	_, err = secretsClient.Create(context.Background(), tlsSecret, metav1.CreateOptions{})
 
-	if len(imageSplit) == 2 {
-		image = imageSplit[0]
-		tag = imageSplit[1]
-	} else {
-		tag = "latest"
-	}
+	return err
+}
+
+// AddToDiscovery adds the network to the discovery service or replaces it if
+// it's already there
+func (k8s *Kubernetes) AddToDiscovery() error {
+	image, tag := imageRepoTag(config.GoSmImage)
 
 	minerConfigStr, err := gcp.ReadConfig(config.NetworkName)
 
@@ -339,14 +245,14 @@ func (k8s *Kubernetes) AddToDiscovery() error {
 		NetName:              config.NetworkName,
 		NetID:                netID,
 		Conf:                 "https://storage.googleapis.com/spacecraft-data/" + config.NetworkName + "-archive/config.json",
-		GrpcApi:              "https://api-" + config.NetworkName + ".spacemesh.io/",
-		JsonApi:              "https://api-json-" + config.NetworkName + ".spacemesh.io/",
+		GrpcApi:              "https://" + Domain("api") + "/",
+		JsonApi:              "https://" + Domain("api-json") + "/",
 		Explorer:             "https://explorer.spacemesh.io/",
-		ExplorerAPI:          "https://explorer-api-" + config.NetworkName + ".spacemesh.io/",
+		ExplorerAPI:          "https://" + Domain("explorer-api") + "/",
 		ExplorerVersion:      config.ExplorerVersion,
 		ExplorerConf:         "https://storage.googleapis.com/spacecraft-data/" + config.NetworkName + "-archive/config.json",
 		Dash:                 "https://dash.spacemesh.io/",
-		DashApi:              "wss://dash-api-" + config.NetworkName + ".spacemesh.io/ws",
+		DashApi:              "wss://" + Domain("dash-api") + "/ws",
 		DashVersion:          config.DashboardVersion,
 		Repository:           image,
 		MinNodeVersion:       tag,
@@ -357,141 +263,243 @@ func (k8s *Kubernetes) AddToDiscovery() error {
 		NodeBaseDownloadUrl:  "https://downloads.spacemesh.io",
 	}
 
-	networks = append([]Network{network}, networks...)
+	if config.DeployFaucet {
+		network.Faucet = FaucetURL()
+	}
 
-	json, err := json.MarshalIndent(networks, "", "  ")
+	return UpdateDiscovery(func(networks []Network) ([]Network, error) {
+		// an upgraded network keeps its position and faucet
+		for i := range networks {
+			if networks[i].NetName == network.NetName {
+				if network.Faucet == "" {
+					network.Faucet = networks[i].Faucet
+				}
+
+				networks[i] = network
+
+				return networks, nil
+			}
+		}
+
+		return append([]Network{network}, networks...), nil
+	})
+}
+
+// RemoveFromDiscovery removes the network from the discovery service
+func (k8s *Kubernetes) RemoveFromDiscovery() error {
+	return RemoveNetworkFromDiscovery(config.NetworkName)
+}
+
+var wsReleases = []string{"spacemesh-api", "spacemesh-explorer", "spacemesh-dash"}
+
+// WSRelease is a helm release of the web services
+type WSRelease struct {
+	Name       string
+	Chart      string
+	AppVersion string
+	Revision   int
+	Status     string
+	Updated    time.Time
+}
+
+// WSPod is a pod of the web services
+type WSPod struct {
+	Name     string
+	Ready    bool
+	Phase    string
+	Restarts int32
+}
+
+// WSStatus is the state of the web services of a network
+type WSStatus struct {
+	Releases  []WSRelease
+	Pods      []WSPod
+	IngressIP string
+}
+
+// UpgradeWS upgrades the web services charts. The versions which are empty
+// keep the values of the installed releases.
+func (k8s *Kubernetes) UpgradeWS() error {
+	client, err := k8s.wsHelmClient()
 
 	if err != nil {
 		return err
 	}
 
-	err = gcp.UploadWSConfig(string(json))
-
-	if err != nil {
+	if err = currentWSValues(client); err != nil {
 		return err
 	}
 
-	return nil
+	fmt.Println("upgrading web services to " + config.GoSmImage + ", explorer " + config.ExplorerVersion + " and dash " + config.DashboardVersion)
+
+	return installWSCharts(client)
 }
 
-func (k8s *Kubernetes) RemoveFromDiscovery() error {
-	networksConfig, err := gcp.ReadWSConfig()
+// currentWSValues sets the empty versions and resources to the values of
+// the installed releases
+func currentWSValues(client helm.Client) error {
+	getValues := action.NewGetValues(client.(*helm.HelmClient).ActionConfig)
 
-	if err != nil {
-		return err
-	}
+	values := map[string]*gabs.Container{}
 
-	var networks []Network
-	var newNetworks []Network
+	for _, release := range wsReleases {
+		releaseValues, err := getValues.Run(release)
 
-	json.Unmarshal([]byte(networksConfig), &networks)
+		if err != nil {
+			return fmt.Errorf("%s: %w", release, err)
+		}
+
+		values[release] = gabs.Wrap(releaseValues)
+	}
 
-	for _, network := range networks {
-		if network.NetName != config.NetworkName {
-			newNetworks = append(newNetworks, network)
+	value := func(release string, path string) string {
+		data := values[release].Path(path).Data()
+
+		if data == nil {
+			return ""
 		}
+
+		return fmt.Sprintf("%v", data)
 	}
 
-	json, err := json.MarshalIndent(newNetworks, "", "  ")
+	if config.GoSmImage == "" {
+		config.GoSmImage = value("spacemesh-api", "image.repository") + ":" + value("spacemesh-api", "image.tag")
+	}
 
-	if err != nil {
-		return err
+	if config.MinerMemory == "" {
+		config.MinerMemory = strings.TrimSuffix(value("spacemesh-api", "resources.requests.memory"), "Gi")
 	}
 
-	if string(json) == "null" {
-		err = gcp.UploadWSConfig(string("[]"))
+	if config.MinerCPU == "" {
+		config.MinerCPU = value("spacemesh-api", "resources.requests.cpu")
+	}
 
-		if err != nil {
-			return err
-		}
-	} else {
-		err = gcp.UploadWSConfig(string(json))
+	if config.PagerDutyToken == "" {
+		config.PagerDutyToken = value("spacemesh-api", "pagerdutyToken")
 
-		if err != nil {
-			return err
-		}
+		// the token is printed by the debug output of helm
+		secrets.Add(config.PagerDutyToken)
+	}
+
+	if config.ExplorerVersion == "" {
+		config.ExplorerVersion = value("spacemesh-explorer", "imageTag")
+	}
+
+	if config.DashboardVersion == "" {
+		config.DashboardVersion = value("spacemesh-dash", "image.tag")
 	}
 
 	return nil
 }
 
-func (k8s *Kubernetes) DeleteWSDNSRecords() error {
-	if config.CloudflareAPIToken != "" {
-		api, err := cloudflare.NewWithAPIToken(config.CloudflareAPIToken)
+// GetWSStatus returns the helm releases, pods and ingress IP of the web
+// services
+func (k8s *Kubernetes) GetWSStatus() (*WSStatus, error) {
+	client, err := k8s.wsHelmClient()
 
-		if err != nil {
-			return err
-		}
+	if err != nil {
+		return nil, err
+	}
 
-		id, err := api.ZoneIDByName("spacemesh.io")
+	status := &WSStatus{}
 
-		if err != nil {
-			return err
-		}
+	list := action.NewList(client.(*helm.HelmClient).ActionConfig)
+	list.All = true
 
-		records, err := api.DNSRecords(context.Background(), id, cloudflare.DNSRecord{
-			Name: "api-json-" + config.NetworkName + ".spacemesh.io",
+	releases, err := list.Run()
+
+	if err != nil {
+		return nil, err
+	}
+
+	for _, release := range releases {
+		status.Releases = append(status.Releases, WSRelease{
+			Name:       release.Name,
+			Chart:      release.Chart.Metadata.Name + "-" + release.Chart.Metadata.Version,
+			AppVersion: release.Chart.Metadata.AppVersion,
+			Revision:   release.Version,
+			Status:     release.Info.Status.String(),
+			Updated:    release.Info.LastDeployed.Time,
 		})
+	}
 
-		if err != nil {
-			return err
-		}
+	pods, err := k8s.Client.CoreV1().Pods("ws").List(context.Background(), metav1.ListOptions{})
 
-		if len(records) == 1 {
-			err = api.DeleteDNSRecord(context.Background(), id, records[0].ID)
+	if err != nil {
+		return nil, err
+	}
 
-			if err != nil {
-				return err
-			}
+	for _, pod := range pods.Items {
+		wsPod := WSPod{Name: pod.Name, Phase: string(pod.Status.Phase), Ready: len(pod.Status.ContainerStatuses) > 0}
+
+		for _, container := range pod.Status.ContainerStatuses {
+			wsPod.Ready = wsPod.Ready && container.Ready
+			wsPod.Restarts += container.RestartCount
 		}
 
-		records, err = api.DNSRecords(context.Background(), id, cloudflare.DNSRecord{
-			Name: "api-" + config.NetworkName + ".spacemesh.io",
-		})
+		status.Pods = append(status.Pods, wsPod)
+	}
 
-		if err != nil {
-			return err
-		}
+	ingress, err := k8s.Client.ExtensionsV1beta1().Ingresses("ws").Get(context.Background(), "spacemesh-api", metav1.GetOptions{})
 
-		if len(records) == 1 {
-			err = api.DeleteDNSRecord(context.Background(), id, records[0].ID)
+	if err != nil && !k8serrors.IsNotFound(err) {
+		return nil, err
+	}
 
-			if err != nil {
-				return err
-			}
-		}
+	if err == nil && len(ingress.Status.LoadBalancer.Ingress) > 0 {
+		status.IngressIP = ingress.Status.LoadBalancer.Ingress[0].IP
+	}
 
-		records, err = api.DNSRecords(context.Background(), id, cloudflare.DNSRecord{
-			Name: "dash-api-" + config.NetworkName + ".spacemesh.io",
+	return status, nil
+}
+
+// DeleteWS uninstalls the web services charts and deletes the ws namespace
+// with the faucet and the TLS secret, the miners keep running
+func (k8s *Kubernetes) DeleteWS() error {
+	client, err := k8s.wsHelmClient()
+
+	if err != nil {
+		return err
+	}
+
+	for _, release := range wsReleases {
+		fmt.Println("uninstalling " + release)
+
+		err = client.UninstallRelease(&helm.ChartSpec{
+			ReleaseName: release,
+			Namespace:   "ws",
 		})
 
-		if err != nil {
+		if err != nil && !strings.Contains(err.Error(), "not found") {
 			return err
 		}
+	}
 
-		if len(records) == 1 {
-			err = api.DeleteDNSRecord(context.Background(), id, records[0].ID)
+	namespaceClient := k8s.Client.CoreV1().Namespaces()
 
-			if err != nil {
-				return err
-			}
-		}
+	err = namespaceClient.Delete(context.Background(), "ws", metav1.DeleteOptions{})
 
-		records, err = api.DNSRecords(context.Background(), id, cloudflare.DNSRecord{
-			Name: "explorer-api-" + config.NetworkName + ".spacemesh.io",
-		})
+	if k8serrors.IsNotFound(err) {
+		return nil
+	}
+
+	if err != nil {
+		return err
+	}
+
+	// deployWS can't create the namespace until it's deleted
+	for range time.Tick(5 * time.Second) {
+		_, err = namespaceClient.Get(context.Background(), "ws", metav1.GetOptions{})
+
+		if k8serrors.IsNotFound(err) {
+			break
+		}
 
 		if err != nil {
 			return err
 		}
 
-		if len(records) == 1 {
-			err = api.DeleteDNSRecord(context.Background(), id, records[0].ID)
-
-			if err != nil {
-				return err
-			}
-		}
+		fmt.Println("waiting for ws namespace to be deleted")
 	}
 
 	return nil
@@ -1,7 +1,29 @@
 package log
 
-import "github.com/fatih/color"
+import (
+	"fmt"
 
-var Error = color.New(color.FgRed)
-var Success = color.New(color.FgGreen)
-var Info = color.New(color.FgBlue)
+	"github.com/fatih/color"
+	"github.com/spacemeshos/go-spacecraft/secrets"
+)
+
+// Logger prints colored output with the secrets redacted
+type Logger struct {
+	*color.Color
+}
+
+func (l *Logger) Print(a ...interface{}) (int, error) {
+	return l.Color.Print(secrets.Redact(fmt.Sprint(a...)))
+}
+
+func (l *Logger) Printf(format string, a ...interface{}) (int, error) {
+	return l.Color.Print(secrets.Redact(fmt.Sprintf(format, a...)))
+}
+
+func (l *Logger) Println(a ...interface{}) (int, error) {
+	return l.Color.Print(secrets.Redact(fmt.Sprintln(a...)))
+}
+
+var Error = &Logger{color.New(color.FgRed)}
+var Success = &Logger{color.New(color.FgGreen)}
+var Info = &Logger{color.New(color.FgBlue)}
@@ -1,7 +1,11 @@
 package network
 
 import (
+	"errors"
 	"io/ioutil"
+	"strconv"
+
+	gabs "github.com/Jeffail/gabs/v2"
 
 	"github.com/spacemeshos/go-spacecraft/gcp"
 	k8s "github.com/spacemeshos/go-spacecraft/k8s"
@@ -15,6 +19,14 @@ func AddMiner() error {
 	}
 
 	kubernetes := k8s.Kubernetes{Client: k8sClient, RestConfig: k8sRestConfig}
+
+	if config.CoinbaseKeystore != "" {
+		kubernetes.CoinbaseKeys, err = k8s.ReadCoinbaseKeystore(config.CoinbaseKeystore, config.KeystorePassword)
+
+		if err != nil {
+			return err
+		}
+	}
 	minerNumber := ""
 
 	if config.MinerNumber != "" {
@@ -27,6 +39,25 @@ func AddMiner() error {
 		}
 	}
 
+	group := config.DefaultGroup()
+
+	if config.MinerGroup != "" {
+		var ok bool
+		group, ok = config.GetMinerGroup(config.MinerGroup)
+
+		if !ok {
+			return errors.New("miner group " + config.MinerGroup + " not found")
+		}
+	} else {
+		minerNumberInt, err := strconv.Atoi(minerNumber)
+
+		if err != nil {
+			return err
+		}
+
+		group = config.MinerGroupOf(minerNumberInt)
+	}
+
 	configStr := ""
 
 	if config.MinerGoSmConfig == "" {
@@ -45,16 +76,34 @@ func AddMiner() error {
 		configStr = string(buf)
 	}
 
+	groupConfigs, err := readGroupConfigs()
+
+	if err != nil {
+		return err
+	}
+
+	minerConfigJson, err := gabs.ParseJSON([]byte(configStr))
+
+	if err != nil {
+		return err
+	}
+
+	configStr, err = groupConfig(minerConfigJson, group, groupConfigs)
+
+	if err != nil {
+		return err
+	}
+
 	minerChan := &k8s.MinerChannel{
 		Err:  make(chan error),
 		Done: make(chan *k8s.MinerDeploymentData),
 	}
 
-	go kubernetes.DeployMiner(false, minerNumber, configStr, "", minerChan)
+	go kubernetes.DeployMiner(k8s.RoleMiner, minerNumber, group, configStr, "", minerChan)
 	select {
 	case err := <-minerChan.Err:
 		return err
 	case _ = <-minerChan.Done:
-		return nil
+		return kubernetes.SyncSpacemeshWatch()
 	}
 }
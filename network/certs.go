@@ -0,0 +1,52 @@
+package network
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/spacemeshos/go-spacecraft/gcp"
+	k8s "github.com/spacemeshos/go-spacecraft/k8s"
+	"github.com/spacemeshos/go-spacecraft/log"
+)
+
+// certRenewWarning is how long before expiry a certificate is reported
+const certRenewWarning = 14 * 24 * time.Hour
+
+// CertsStatus prints the expiry dates of the TLS certificates of the network
+func CertsStatus() error {
+	k8sRestConfig, k8sClient, err := gcp.GetKubernetesClient(config.NetworkName)
+
+	if err != nil {
+		return err
+	}
+
+	kubernetes := k8s.Kubernetes{Client: k8sClient, RestConfig: k8sRestConfig}
+
+	certificates, err := kubernetes.ListCertificates()
+
+	if err != nil {
+		return err
+	}
+
+	if len(certificates) == 0 {
+		log.Error.Println("No TLS certificates found")
+		return nil
+	}
+
+	log.Info.Println("TLS certificates:")
+
+	for _, certificate := range certificates {
+		left := time.Until(certificate.NotAfter)
+
+		line := fmt.Sprintf("%s/%s issuer=%s expires=%s days=%d hosts=%s", certificate.Namespace, certificate.Name, certificate.Issuer, certificate.NotAfter.UTC().Format(time.RFC3339), int(left.Hours()/24), strings.Join(certificate.DNSNames, ","))
+
+		if left < certRenewWarning {
+			log.Error.Println(line)
+		} else {
+			fmt.Println(line)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,226 @@
+package network
+
+import (
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"time"
+
+	gabs "github.com/Jeffail/gabs/v2"
+	"github.com/spacemeshos/go-spacecraft/gcp"
+	k8s "github.com/spacemeshos/go-spacecraft/k8s"
+	"github.com/spacemeshos/go-spacecraft/log"
+	"sigs.k8s.io/yaml"
+)
+
+type chaosScenario struct {
+	Experiments []k8s.ChaosExperiment `json:"experiments"`
+}
+
+type networkTiming struct {
+	GenesisTime    time.Time
+	LayerDuration  time.Duration
+	LayersPerEpoch int
+}
+
+func readNetworkTiming() (*networkTiming, error) {
+	minerConfigStr, err := gcp.ReadConfig(config.NetworkName)
+
+	if err != nil {
+		return nil, err
+	}
+
+	minerConfigJson, err := gabs.ParseJSON([]byte(minerConfigStr))
+
+	if err != nil {
+		return nil, err
+	}
+
+	genesisTimeStr, ok := minerConfigJson.Path("main.genesis-time").Data().(string)
+
+	if !ok {
+		return nil, errors.New("cannot read genesis-time from config file")
+	}
+
+	genesisTime, err := time.Parse(time.RFC3339, genesisTimeStr)
+
+	if err != nil {
+		return nil, err
+	}
+
+	layerDurationSec, ok := minerConfigJson.Path("main.layer-duration-sec").Data().(float64)
+
+	if !ok {
+		return nil, errors.New("cannot read layer-duration-sec from config file")
+	}
+
+	layersPerEpoch, ok := minerConfigJson.Path("main.layers-per-epoch").Data().(float64)
+
+	if !ok {
+		return nil, errors.New("cannot read layers-per-epoch from config file")
+	}
+
+	return &networkTiming{
+		GenesisTime:    genesisTime,
+		LayerDuration:  time.Duration(layerDurationSec) * time.Second,
+		LayersPerEpoch: int(layersPerEpoch),
+	}, nil
+}
+
+// LayerStart returns the time at which the layer starts
+func (t *networkTiming) LayerStart(layer int) time.Time {
+	return t.GenesisTime.Add(time.Duration(layer) * t.LayerDuration)
+}
+
+func (t *networkTiming) chaosStart(start *k8s.ChaosStart) (time.Time, error) {
+	startAt := t.GenesisTime
+
+	if start.Epoch != nil {
+		startAt = t.LayerStart(*start.Epoch * t.LayersPerEpoch)
+	} else if start.Layer != nil {
+		startAt = t.LayerStart(*start.Layer)
+	}
+
+	if start.After != "" {
+		after, err := time.ParseDuration(start.After)
+
+		if err != nil {
+			return time.Time{}, err
+		}
+
+		startAt = startAt.Add(after)
+	}
+
+	return startAt, nil
+}
+
+func readChaosScenario(path string) (*chaosScenario, error) {
+	buf, err := ioutil.ReadFile(path)
+
+	if err != nil {
+		return nil, err
+	}
+
+	scenario := &chaosScenario{}
+
+	if err = yaml.Unmarshal(buf, scenario); err != nil {
+		return nil, err
+	}
+
+	return scenario, nil
+}
+
+// createChaosExperiments creates the experiments right away. Chaos mesh
+// delays the experiments with a start in the future until their start, so
+// the network doesn't depend on spacecraft running until then.
+func createChaosExperiments(kubernetes *k8s.Kubernetes, experiments []k8s.ChaosExperiment) error {
+	var timing *networkTiming
+	var err error
+
+	for _, experiment := range experiments {
+		startAt := time.Time{}
+
+		if experiment.Start != nil && timing == nil {
+			timing, err = readNetworkTiming()
+
+			if err != nil {
+				return err
+			}
+		}
+
+		if experiment.Start != nil {
+			startAt, err = timing.chaosStart(experiment.Start)
+
+			if err != nil {
+				return err
+			}
+		}
+
+		if err = kubernetes.CreateChaosExperiment(experiment, startAt); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func CreateChaos() error {
+	if config.ChaosFile == "" {
+		return errors.New("please provide the chaos scenario file")
+	}
+
+	scenario, err := readChaosScenario(config.ChaosFile)
+
+	if err != nil {
+		return err
+	}
+
+	k8sRestConfig, k8sClient, err := gcp.GetKubernetesClient(config.NetworkName)
+
+	if err != nil {
+		return err
+	}
+
+	kubernetes := k8s.Kubernetes{Client: k8sClient, RestConfig: k8sRestConfig}
+
+	return createChaosExperiments(&kubernetes, scenario.Experiments)
+}
+
+func ListChaos() error {
+	k8sRestConfig, k8sClient, err := gcp.GetKubernetesClient(config.NetworkName)
+
+	if err != nil {
+		return err
+	}
+
+	kubernetes := k8s.Kubernetes{Client: k8sClient, RestConfig: k8sRestConfig}
+
+	experiments, err := kubernetes.ListChaosExperiments()
+
+	if err != nil {
+		return err
+	}
+
+	if len(experiments) == 0 {
+		log.Error.Println("No chaos experiments found")
+		return nil
+	}
+
+	log.Info.Println("Chaos experiments:")
+
+	for _, experiment := range experiments {
+		fmt.Printf("%s kind=%s paused=%t", experiment.Name, experiment.Kind, experiment.Paused)
+
+		if experiment.Start != "" {
+			fmt.Printf(" start=%s", experiment.Start)
+		}
+
+		fmt.Println()
+	}
+
+	return nil
+}
+
+func PauseChaos(name string, pause bool) error {
+	k8sRestConfig, k8sClient, err := gcp.GetKubernetesClient(config.NetworkName)
+
+	if err != nil {
+		return err
+	}
+
+	kubernetes := k8s.Kubernetes{Client: k8sClient, RestConfig: k8sRestConfig}
+
+	return kubernetes.PauseChaosExperiment(name, pause)
+}
+
+func DeleteChaos(name string) error {
+	k8sRestConfig, k8sClient, err := gcp.GetKubernetesClient(config.NetworkName)
+
+	if err != nil {
+		return err
+	}
+
+	kubernetes := k8s.Kubernetes{Client: k8sClient, RestConfig: k8sRestConfig}
+
+	return kubernetes.DeleteChaosExperiment(name)
+}
@@ -0,0 +1,46 @@
+package network
+
+import (
+	"fmt"
+
+	"github.com/spacemeshos/go-spacecraft/gcp"
+	k8s "github.com/spacemeshos/go-spacecraft/k8s"
+	"github.com/spacemeshos/go-spacecraft/log"
+)
+
+// ListCharts prints the charts installed in the network. Charts whose version
+// differs from the chart catalog are printed as errors.
+func ListCharts() error {
+	k8sRestConfig, k8sClient, err := gcp.GetKubernetesClient(config.NetworkName)
+
+	if err != nil {
+		return err
+	}
+
+	kubernetes := k8s.Kubernetes{Client: k8sClient, RestConfig: k8sRestConfig}
+
+	charts, err := kubernetes.ListInstalledCharts()
+
+	if err != nil {
+		return err
+	}
+
+	if len(charts) == 0 {
+		log.Error.Println("No charts found")
+		return nil
+	}
+
+	log.Info.Println("Charts:")
+
+	for _, chart := range charts {
+		line := fmt.Sprintf("%s/%s chart=%s version=%s app=%s status=%s catalog=%s", chart.Namespace, chart.Release, chart.Chart, chart.Version, chart.AppVersion, chart.Status, chart.CatalogVersion)
+
+		if chart.Pinned() && chart.CatalogVersion != chart.Version {
+			log.Error.Println(line)
+		} else {
+			fmt.Println(line)
+		}
+	}
+
+	return nil
+}
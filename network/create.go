@@ -19,7 +19,29 @@ import (
 
 func Create() error {
 
-	err := gcp.CreateKubernetesCluster()
+	if err := config.AddAdversarialCohort(); err != nil {
+		return err
+	}
+
+	if config.GroupedMiners() > config.NumberOfMiners {
+		config.NumberOfMiners = config.GroupedMiners()
+	}
+
+	groupConfigs, err := readGroupConfigs()
+
+	if err != nil {
+		return err
+	}
+
+	if config.LogBackend != "elk" && config.LogBackend != "loki" && config.LogBackend != "none" {
+		return errors.New("unknown log backend " + config.LogBackend + ", use elk, loki or none")
+	}
+
+	if config.TLSIssuer != "files" && config.TLSIssuer != "acme" && config.TLSIssuer != "selfsigned" {
+		return errors.New("unknown TLS issuer " + config.TLSIssuer + ", use files, acme or selfsigned")
+	}
+
+	err = gcp.CreateKubernetesCluster()
 
 	if err != nil {
 		return err
@@ -33,16 +55,51 @@ func Create() error {
 
 	kubernetes := k8s.Kubernetes{Client: k8sClient, RestConfig: k8sRestConfig}
 
+	if err = kubernetes.SaveDNSSettings(); err != nil {
+		return err
+	}
+
+	if config.CoinbaseKeystore != "" {
+		kubernetes.CoinbaseKeys, err = k8s.ReadCoinbaseKeystore(config.CoinbaseKeystore, config.KeystorePassword)
+
+		if err != nil {
+			return err
+		}
+	}
+
 	if config.DeployPyroscope {
 		if err = kubernetes.DeployPyroscope(); err != nil {
 			return err
 		}
 	}
 
-	if err = kubernetes.DeployELK(); err != nil {
+	if err = kubernetes.DeployIngressNginx(); err != nil {
 		return err
 	}
 
+	if k8s.ManagedTLS() {
+		if err = kubernetes.DeployCertManager(); err != nil {
+			return err
+		}
+	}
+
+	switch config.LogBackend {
+	case "elk":
+		if err = kubernetes.DeployELK(); err != nil {
+			return err
+		}
+	case "loki":
+		if err = kubernetes.DeployLoki(); err != nil {
+			return err
+		}
+	}
+
+	if config.Metrics {
+		if err = kubernetes.DeployMetrics(); err != nil {
+			return err
+		}
+	}
+
 	if err = kubernetes.DisablePodRescheduling(); err != nil {
 		return err
 	}
@@ -129,101 +186,111 @@ func Create() error {
 		return poetRESTUrls[currentPoet-1]
 	}
 
-	var miners []string
-	var minerGRPCURls []string
-
 	minerChan := &k8s.MinerChannel{
 		Err:  make(chan error),
 		Done: make(chan *k8s.MinerDeploymentData),
 	}
 
-	//Deploy Bootstrap
+	//Generate identities of bootstrap and bootnodes so that their addresses are known before deployment
+	bootnodeStart := 1
+
 	if config.Bootstrap {
-		nextNode, err := kubernetes.NextNode()
-		if err != nil {
-			return err
-		}
-		minerConfigJson.SetP(nextPoet(), "main.poet-server")
-		go kubernetes.DeployMiner(true, strconv.Itoa(1), minerConfigJson.String(), nextNode, minerChan)
-		select {
-		case err := <-minerChan.Err:
-			return err
-		case miner := <-minerChan.Done:
-			miners = append(miners, miner.TcpURL)
-			minerGRPCURls = append(minerGRPCURls, miner.GrpcURL)
-		}
+		bootnodeStart = 2
 	}
 
-	start, end := 0, 0
+	bootnodeEnd := bootnodeStart + config.BootnodeAmount - 1
 
-	//Deploy bootnodes
-	if config.Bootstrap {
-		minerConfigJson.SetP(miners[0:1], "p2p.bootnodes")
-		start = 1
-		end = config.BootnodeAmount
-	} else {
-		start = 0
-		end = config.BootnodeAmount - 1
+	totalMiners := config.NumberOfMiners
+
+	if bootnodeEnd > totalMiners {
+		totalMiners = bootnodeEnd
+	}
+
+	configBootnodes := minerConfigJson.Path("p2p.bootnodes").Data()
+
+	if configBootnodes == nil {
+		configBootnodes = []string{}
 	}
 
-	for i := start; i <= end; i++ {
+	pinnedNodes := map[int]string{}
+	bootstrapAddresses := []string{}
+	bootnodeAddresses := []string{}
+
+	for i := 1; i <= bootnodeEnd; i++ {
 		nextNode, err := kubernetes.NextNode()
 		if err != nil {
 			return err
 		}
-		minerConfigJson.SetP(nextPoet(), "main.poet-server")
-		go kubernetes.DeployMiner(true, strconv.Itoa(i+1), minerConfigJson.String(), nextNode, minerChan)
-	}
 
-	for i := start; i <= end; i++ {
-		select {
-		case err := <-minerChan.Err:
+		address, err := kubernetes.BootnodeAddress(strconv.Itoa(i), nextNode)
+		if err != nil {
 			return err
-		case miner := <-minerChan.Done:
-			miners = append(miners, miner.TcpURL)
-			minerGRPCURls = append(minerGRPCURls, miner.GrpcURL)
 		}
-	}
 
-	//Deploy remaining miners
-	if config.Bootstrap {
-		minerConfigJson.SetP(miners[1:config.BootnodeAmount+1], "p2p.bootnodes")
-		start = config.BootnodeAmount + 1
-		end = config.NumberOfMiners
-	} else {
-		minerConfigJson.SetP(miners[0:config.BootnodeAmount], "p2p.bootnodes")
-		start = config.BootnodeAmount
-		end = config.NumberOfMiners
+		pinnedNodes[i] = nextNode
+
+		if i < bootnodeStart {
+			bootstrapAddresses = append(bootstrapAddresses, address)
+		} else {
+			bootnodeAddresses = append(bootnodeAddresses, address)
+		}
 	}
 
-	remainingMinerNumbers := []int{}
+	//Deploy bootstrap, bootnodes and remaining miners in parallel
+	minerNumbers := []int{}
 
-	for i := start; i < end; i++ {
-		remainingMinerNumbers = append(remainingMinerNumbers, i)
+	for i := 1; i <= totalMiners; i++ {
+		minerNumbers = append(minerNumbers, i)
 	}
 
-	minersChunks := chunkSlice(remainingMinerNumbers, config.MaxConcurrentDeployments)
+	minerGRPCURls := make([]string, totalMiners)
 
-	for i := 0; i < len(minersChunks); i++ {
-		start := minersChunks[i][0]
-		end := minersChunks[i][len(minersChunks[i])-1]
+	minersChunks := chunkSlice(minerNumbers, config.MaxConcurrentDeployments)
+
+	for _, chunk := range minersChunks {
+		for _, i := range chunk {
+			role := k8s.RoleMiner
+
+			if i < bootnodeStart {
+				role = k8s.RoleBootstrap
+				minerConfigJson.SetP(configBootnodes, "p2p.bootnodes")
+			} else if i <= bootnodeEnd {
+				role = k8s.RoleBootnode
+
+				if config.Bootstrap {
+					minerConfigJson.SetP(bootstrapAddresses, "p2p.bootnodes")
+				} else {
+					minerConfigJson.SetP(configBootnodes, "p2p.bootnodes")
+				}
+			} else {
+				minerConfigJson.SetP(bootnodeAddresses, "p2p.bootnodes")
+			}
 
-		for i := start; i <= end; i++ {
 			minerConfigJson.SetP(nextPoet(), "main.poet-server")
-			go kubernetes.DeployMiner(true, strconv.Itoa(i+1), minerConfigJson.String(), "", minerChan)
+
+			group := config.MinerGroupOf(i)
+			minerConfigStr, err := groupConfig(minerConfigJson, group, groupConfigs)
+
+			if err != nil {
+				return err
+			}
+
+			go kubernetes.DeployMiner(role, strconv.Itoa(i), group, minerConfigStr, pinnedNodes[i], minerChan)
 		}
 
-		for i := start; i <= end; i++ {
+		for range chunk {
 			select {
 			case err := <-minerChan.Err:
 				return err
 			case miner := <-minerChan.Done:
-				miners = append(miners, miner.TcpURL)
-				minerGRPCURls = append(minerGRPCURls, miner.GrpcURL)
+				minerNumber, _ := strconv.Atoi(miner.MinerNumber)
+				minerGRPCURls[minerNumber-1] = miner.GrpcURL
 			}
 		}
 	}
 
+	minerConfigJson.SetP(bootnodeAddresses, "p2p.bootnodes")
+
 	//Activate poet(s)
 	gateways := minerGRPCURls[0:config.PoetGatewayAmount]
 
@@ -255,9 +322,20 @@ func Create() error {
 	if err != nil {
 		return err
 	}
-	err = kubernetes.SetupLogDeletionPolicy()
-	if err != nil {
-		return err
+
+	if config.LogBackend == "elk" {
+		// a shared elasticsearch is not archived
+		if config.ArchiveLogs && config.ESURL == "" {
+			err = kubernetes.SetupLogArchive()
+			if err != nil {
+				return err
+			}
+		}
+
+		err = kubernetes.SetupLogDeletionPolicy()
+		if err != nil {
+			return err
+		}
 	}
 
 	if config.EnableSlackAlerts {
@@ -276,9 +354,27 @@ func Create() error {
 		}
 	}
 
-	log.Info.Println("Kibana URL: https://kibana-" + config.NetworkName + ".spacemesh.io")
-	log.Info.Println("Kibana Username: elastic")
-	log.Info.Println("Kibana Password: " + kubernetes.Password)
+	if config.LogBackend == "elk" && config.ESURL == "" {
+		log.Info.Println("Kibana URL: https://" + k8s.Domain("kibana"))
+		log.Info.Println("Kibana Username: elastic")
+		log.Info.Println("Kibana Password: " + kubernetes.Password)
+	}
+
+	if config.LogBackend == "elk" && config.ESURL != "" {
+		if config.KibanaURL != "" {
+			log.Info.Println("Kibana URL: " + config.KibanaURL)
+		}
+
+		log.Info.Println("Kibana Index Pattern: " + config.NetworkName + "-sm-*")
+	}
+
+	if config.Metrics || config.LogBackend == "loki" {
+		log.Info.Println("Grafana URL: https://" + k8s.Domain("grafana"))
+	}
+
+	if config.Metrics {
+		log.Info.Println("Prometheus URL: https://" + k8s.Domain("prometheus") + " (user admin, the password is in the prometheus-basic-auth secret of the metrics namespace)")
+	}
 
 	if config.DeployPyroscope {
 		pyroscopeURL, err := kubernetes.GetPyroscopeURL()
@@ -18,7 +18,27 @@ func Delete() error {
 
 	kubernetes := k8s.Kubernetes{Client: k8sClient, RestConfig: k8sRestConfig}
 
+	// the records are deleted after the cluster
+	if err = kubernetes.LoadDNSSettings(); err != nil {
+		return err
+	}
+
 	if !config.KeepLogsMetrics {
+		logBackend, err := kubernetes.GetLogBackend()
+
+		if err != nil {
+			return err
+		}
+
+		// networks created with the logs archive have the snapshot repository
+		if logBackend == "elk" {
+			err = kubernetes.ArchiveLogs()
+
+			if err != nil {
+				return err
+			}
+		}
+
 		volumes, err := kubernetes.GetPVCs()
 
 		if err != nil {
@@ -31,7 +51,7 @@ func Delete() error {
 			return err
 		}
 
-		err = kubernetes.DeleteELKDNSRecords()
+		err = kubernetes.DeleteDNSRecords("kibana", "metrics", "loki")
 
 		if err != nil {
 			return err
@@ -74,7 +94,7 @@ func Delete() error {
 		}
 	}
 
-	err = kubernetes.DeleteWSDNSRecords()
+	err = kubernetes.DeleteDNSRecords("ws", "faucet")
 
 	if err != nil {
 		return err
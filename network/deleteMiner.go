@@ -27,18 +27,10 @@ func DeleteMiner() error {
 		return err
 	}
 
-	if config.EnableSlackAlerts == true {
-		err = kubernetes.DeleteSpacemeshWatch()
+	err = kubernetes.SyncSpacemeshWatch()
 
-		if err != nil {
-			return err
-		}
-
-		err = kubernetes.DeploySpacemeshWatch()
-
-		if err != nil {
-			return err
-		}
+	if err != nil {
+		return err
 	}
 
 	return nil
@@ -0,0 +1,143 @@
+package network
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+
+	k8s "github.com/spacemeshos/go-spacecraft/k8s"
+	"github.com/spacemeshos/go-spacecraft/log"
+)
+
+func ListDiscovery() error {
+	networks, err := k8s.ListDiscovery()
+
+	if err != nil {
+		return err
+	}
+
+	if len(networks) == 0 {
+		log.Error.Println("No networks in the discovery service")
+		return nil
+	}
+
+	log.Info.Println("Networks:")
+
+	for _, network := range networks {
+		fmt.Printf("%s netID=%v minNodeVersion=%s maxNodeVersion=%s explorerVersion=%s dashVersion=%s faucet=%s\n", network.NetName, network.NetID, network.MinNodeVersion, network.MaxNodeVersion, network.ExplorerVersion, network.DashVersion, network.Faucet)
+	}
+
+	return nil
+}
+
+func ShowDiscovery(name string) error {
+	networks, err := k8s.ListDiscovery()
+
+	if err != nil {
+		return err
+	}
+
+	for _, network := range networks {
+		if network.NetName != name {
+			continue
+		}
+
+		data, err := json.MarshalIndent(network, "", "  ")
+
+		if err != nil {
+			return err
+		}
+
+		fmt.Println(string(data))
+
+		return nil
+	}
+
+	return errors.New("network " + name + " is not in the discovery service")
+}
+
+// readDiscoveryFile reads the network of --discovery-file
+func readDiscoveryFile() (k8s.Network, error) {
+	if config.DiscoveryFile == "" {
+		return k8s.Network{}, errors.New("--discovery-file is not set")
+	}
+
+	content, err := ioutil.ReadFile(config.DiscoveryFile)
+
+	if err != nil {
+		return k8s.Network{}, err
+	}
+
+	return k8s.ParseNetwork(string(content))
+}
+
+func AddDiscovery() error {
+	network, err := readDiscoveryFile()
+
+	if err != nil {
+		return err
+	}
+
+	return k8s.UpdateDiscovery(func(networks []k8s.Network) ([]k8s.Network, error) {
+		for _, n := range networks {
+			if n.NetName == network.NetName {
+				return nil, errors.New("network " + network.NetName + " is already in the discovery service")
+			}
+		}
+
+		return append([]k8s.Network{network}, networks...), nil
+	})
+}
+
+// UpdateDiscovery replaces a network of the discovery service with the
+// network of --discovery-file, or updates the fields which are set
+func UpdateDiscovery(name string) error {
+	if config.DiscoveryFile != "" {
+		network, err := readDiscoveryFile()
+
+		if err != nil {
+			return err
+		}
+
+		if network.NetName != name {
+			return errors.New("netName of " + config.DiscoveryFile + " is not " + name)
+		}
+
+		return k8s.UpdateNetworkInDiscovery(name, func(n *k8s.Network) {
+			*n = network
+		})
+	}
+
+	return k8s.UpdateNetworkInDiscovery(name, func(n *k8s.Network) {
+		fields := map[*string]string{
+			&n.MinNodeVersion:  config.MinNodeVersion,
+			&n.MaxNodeVersion:  config.MaxNodeVersion,
+			&n.ExplorerVersion: config.ExplorerVersion,
+			&n.DashVersion:     config.DashboardVersion,
+			&n.Faucet:          config.DiscoveryFaucet,
+		}
+
+		for field, value := range fields {
+			if value != "" {
+				*field = value
+			}
+		}
+	})
+}
+
+func RemoveDiscovery(name string) error {
+	networks, err := k8s.ListDiscovery()
+
+	if err != nil {
+		return err
+	}
+
+	for _, network := range networks {
+		if network.NetName == name {
+			return k8s.RemoveNetworkFromDiscovery(name)
+		}
+	}
+
+	return errors.New("network " + name + " is not in the discovery service")
+}
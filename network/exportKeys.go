@@ -0,0 +1,59 @@
+package network
+
+import (
+	"errors"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+
+	"github.com/spacemeshos/go-spacecraft/gcp"
+	k8s "github.com/spacemeshos/go-spacecraft/k8s"
+	"github.com/spacemeshos/go-spacecraft/log"
+)
+
+func ExportKeys() error {
+	k8sRestConfig, k8sClient, err := gcp.GetKubernetesClient(config.NetworkName)
+
+	if err != nil {
+		return err
+	}
+
+	kubernetes := k8s.Kubernetes{Client: k8sClient, RestConfig: k8sRestConfig}
+
+	keys, err := kubernetes.GetCoinbaseKeys()
+
+	if err != nil {
+		return err
+	}
+
+	if len(keys) == 0 {
+		return errors.New("no coinbase keys found")
+	}
+
+	for curve, curveKeys := range keys {
+		path := config.KeysFile
+
+		// the secp256k1 keys of miners created by earlier versions are exported
+		// to their own keystore if the network also has ed25519 keys
+		if curve != k8s.CoinbaseKeyCurve && len(keys) > 1 {
+			extension := filepath.Ext(path)
+			path = strings.TrimSuffix(path, extension) + "-" + curve + extension
+		}
+
+		data, err := k8s.EncryptCoinbaseKeys(config.NetworkName, curve, curveKeys, config.KeystorePassword)
+
+		if err != nil {
+			return err
+		}
+
+		err = ioutil.WriteFile(path, data, 0600)
+
+		if err != nil {
+			return err
+		}
+
+		log.Info.Printf("exported %d %s coinbase keys to %s\n", len(curveKeys), curve, path)
+	}
+
+	return nil
+}
@@ -0,0 +1,365 @@
+package network
+
+import (
+	"context"
+	"crypto/ed25519"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	pb "github.com/spacemeshos/api/release/go/spacemesh/v1"
+	k8s "github.com/spacemeshos/go-spacecraft/k8s"
+	"google.golang.org/grpc"
+)
+
+const faucetFee = 1
+
+type faucet struct {
+	privateKey     ed25519.PrivateKey
+	address        []byte
+	interval       time.Duration
+	trustedProxies []*net.IPNet
+	trustedHosts   []string
+	txClient       pb.TransactionServiceClient
+	gsClient       pb.GlobalStateServiceClient
+
+	// last drips by recipient address and by client IP, they're saved to
+	// statePath so that a restart doesn't reset the limits
+	mutex     sync.Mutex
+	lastDrips map[string]time.Time
+	statePath string
+}
+
+type dripRequest struct {
+	Address string `json:"address"`
+}
+
+type faucetResponse struct {
+	Address  string `json:"address,omitempty"`
+	Balance  uint64 `json:"balance,omitempty"`
+	Amount   uint64 `json:"amount,omitempty"`
+	Interval string `json:"interval,omitempty"`
+	TxID     string `json:"txId,omitempty"`
+	Error    string `json:"error,omitempty"`
+}
+
+func writeFaucetResponse(w http.ResponseWriter, status int, response faucetResponse) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Access-Control-Allow-Origin", "*")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(response)
+}
+
+// clientIP returns the address of the client. X-Real-IP is only used if the
+// request comes from a trusted proxy, i.e., the ingress controller, which
+// sets it to the client address and only trusts CF-Connecting-IP from the
+// cloudflare ranges. Otherwise anyone could get unlimited drips by sending
+// a different header with every request.
+func (f *faucet) clientIP(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+
+	if err != nil {
+		host = r.RemoteAddr
+	}
+
+	realIP := net.ParseIP(strings.TrimSpace(r.Header.Get("X-Real-IP")))
+	remoteIP := net.ParseIP(host)
+
+	if realIP == nil || remoteIP == nil {
+		return host
+	}
+
+	for _, proxy := range f.trustedProxies {
+		if proxy.Contains(remoteIP) {
+			return realIP.String()
+		}
+	}
+
+	// the addresses of the ingress controller pods change when they're
+	// replaced, so they're resolved for every request
+	for _, trustedHost := range f.trustedHosts {
+		addrs, err := net.LookupIP(trustedHost)
+
+		if err != nil {
+			continue
+		}
+
+		for _, addr := range addrs {
+			if addr.Equal(remoteIP) {
+				return realIP.String()
+			}
+		}
+	}
+
+	return host
+}
+
+// parseTrustedProxies splits the trusted proxies into networks, which are
+// CIDRs or single IPs, and hostnames
+func parseTrustedProxies(proxies []string) ([]*net.IPNet, []string, error) {
+	networks := []*net.IPNet{}
+	hosts := []string{}
+
+	for _, proxy := range proxies {
+		if strings.Contains(proxy, "/") {
+			_, network, err := net.ParseCIDR(proxy)
+
+			if err != nil {
+				return nil, nil, err
+			}
+
+			networks = append(networks, network)
+		} else if ip := net.ParseIP(proxy); ip != nil {
+			networks = append(networks, &net.IPNet{IP: ip, Mask: net.CIDRMask(len(ip)*8, len(ip)*8)})
+		} else {
+			hosts = append(hosts, proxy)
+		}
+	}
+
+	return networks, hosts, nil
+}
+
+// loadState reads the last drips saved by saveState
+func (f *faucet) loadState() error {
+	if f.statePath == "" {
+		return nil
+	}
+
+	data, err := ioutil.ReadFile(f.statePath)
+
+	if os.IsNotExist(err) {
+		return nil
+	}
+
+	if err != nil {
+		return err
+	}
+
+	return json.Unmarshal(data, &f.lastDrips)
+}
+
+// saveState writes the last drips which still limit requests. The file is
+// replaced atomically so that a crash doesn't leave a partial file.
+func (f *faucet) saveState() error {
+	if f.statePath == "" {
+		return nil
+	}
+
+	for key, last := range f.lastDrips {
+		if time.Since(last) >= f.interval {
+			delete(f.lastDrips, key)
+		}
+	}
+
+	data, err := json.Marshal(f.lastDrips)
+
+	if err != nil {
+		return err
+	}
+
+	tmpPath := f.statePath + ".tmp"
+
+	if err = ioutil.WriteFile(tmpPath, data, 0600); err != nil {
+		return err
+	}
+
+	return os.Rename(tmpPath, f.statePath)
+}
+
+func (f *faucet) account() (uint64, uint64, error) {
+	data, err := accountData(f.gsClient, f.address, pb.AccountDataFlag_ACCOUNT_DATA_FLAG_ACCOUNT)
+
+	if err != nil {
+		return 0, 0, err
+	}
+
+	for _, item := range data {
+		if wrapper := item.GetAccountWrapper(); wrapper != nil {
+			return wrapper.StateProjected.Counter, wrapper.StateProjected.Balance.Value, nil
+		}
+	}
+
+	return 0, 0, errors.New("faucet account not found")
+}
+
+// limited returns how long the keys have to wait for the next drip
+func (f *faucet) limited(keys ...string) time.Duration {
+	for _, key := range keys {
+		if last, ok := f.lastDrips[key]; ok && time.Since(last) < f.interval {
+			return f.interval - time.Since(last)
+		}
+	}
+
+	return 0
+}
+
+func (f *faucet) drip(recipient []byte) (string, error) {
+	nonce, balance, err := f.account()
+
+	if err != nil {
+		return "", err
+	}
+
+	if balance < config.FaucetAmount+faucetFee {
+		return "", errors.New("faucet is empty")
+	}
+
+	tx := signedTransfer(f.privateKey, nonce, recipient, transferGasLimit, faucetFee, config.FaucetAmount)
+	r, err := f.txClient.SubmitTransaction(context.Background(), &pb.SubmitTransactionRequest{Transaction: tx})
+
+	if err != nil {
+		return "", err
+	}
+
+	if r.Status != nil && r.Status.Code != 0 {
+		return "", errors.New(r.Status.Message)
+	}
+
+	if r.Txstate == nil || r.Txstate.State != pb.TransactionState_TRANSACTION_STATE_MEMPOOL {
+		return "", errors.New("transaction was rejected")
+	}
+
+	return hex.EncodeToString(r.Txstate.Id.Id), nil
+}
+
+func (f *faucet) handleInfo(w http.ResponseWriter, r *http.Request) {
+	_, balance, err := f.account()
+
+	if err != nil {
+		writeFaucetResponse(w, http.StatusBadGateway, faucetResponse{Error: err.Error()})
+		return
+	}
+
+	writeFaucetResponse(w, http.StatusOK, faucetResponse{
+		Address:  "0x" + hex.EncodeToString(f.address),
+		Balance:  balance,
+		Amount:   config.FaucetAmount,
+		Interval: f.interval.String(),
+	})
+}
+
+func (f *faucet) handleDrip(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		writeFaucetResponse(w, http.StatusMethodNotAllowed, faucetResponse{Error: "use POST"})
+		return
+	}
+
+	request := dripRequest{}
+
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		writeFaucetResponse(w, http.StatusBadRequest, faucetResponse{Error: "invalid request"})
+		return
+	}
+
+	recipient, err := hex.DecodeString(strings.TrimPrefix(request.Address, "0x"))
+
+	if err != nil || len(recipient) != addressLength {
+		writeFaucetResponse(w, http.StatusBadRequest, faucetResponse{Error: "invalid address"})
+		return
+	}
+
+	addressKey := "address/" + hex.EncodeToString(recipient)
+	ipKey := "ip/" + f.clientIP(r)
+
+	// drips are serialized so that every transaction gets the next nonce
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	if wait := f.limited(addressKey, ipKey); wait > 0 {
+		w.Header().Set("Retry-After", strconv.Itoa(int(wait.Seconds())))
+		writeFaucetResponse(w, http.StatusTooManyRequests, faucetResponse{Error: "try again in " + wait.Round(time.Minute).String()})
+		return
+	}
+
+	txID, err := f.drip(recipient)
+
+	if err != nil {
+		writeFaucetResponse(w, http.StatusBadGateway, faucetResponse{Error: err.Error()})
+		return
+	}
+
+	f.lastDrips[addressKey] = time.Now()
+	f.lastDrips[ipKey] = time.Now()
+
+	if err = f.saveState(); err != nil {
+		fmt.Println("cannot save faucet state:", err)
+	}
+
+	fmt.Println("sent " + strconv.FormatUint(config.FaucetAmount, 10) + " to 0x" + hex.EncodeToString(recipient) + " tx " + txID)
+
+	writeFaucetResponse(w, http.StatusOK, faucetResponse{TxID: txID, Amount: config.FaucetAmount})
+}
+
+// ServeFaucet runs the faucet HTTP API which sends FaucetAmount to an address
+// at most once per FaucetInterval per address and per client IP
+func ServeFaucet() error {
+	if config.FaucetKey == "" {
+		return errors.New("please provide the faucet key")
+	}
+
+	if config.Host == "" {
+		return errors.New("You need to specify the host")
+	}
+
+	privateKey, err := k8s.ParseCoinbaseKey(config.FaucetKey)
+
+	if err != nil {
+		return err
+	}
+
+	interval, err := time.ParseDuration(config.FaucetInterval)
+
+	if err != nil {
+		return err
+	}
+
+	conn, err := grpc.Dial(config.Host, grpc.WithInsecure())
+
+	if err != nil {
+		return err
+	}
+
+	defer conn.Close()
+
+	trustedProxies, trustedHosts, err := parseTrustedProxies(config.FaucetProxies)
+
+	if err != nil {
+		return err
+	}
+
+	f := &faucet{
+		privateKey:     privateKey,
+		address:        addressOf(privateKey.Public().(ed25519.PublicKey)),
+		interval:       interval,
+		trustedProxies: trustedProxies,
+		trustedHosts:   trustedHosts,
+		txClient:       pb.NewTransactionServiceClient(conn),
+		gsClient:       pb.NewGlobalStateServiceClient(conn),
+		lastDrips:      map[string]time.Time{},
+		statePath:      config.FaucetState,
+	}
+
+	if err = f.loadState(); err != nil {
+		return err
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/health", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+	mux.HandleFunc("/api/v1/info", f.handleInfo)
+	mux.HandleFunc("/api/v1/drip", f.handleDrip)
+
+	fmt.Println("faucet 0x" + hex.EncodeToString(f.address) + " listening on port " + strconv.Itoa(config.FaucetPort))
+
+	return http.ListenAndServe(":"+strconv.Itoa(config.FaucetPort), mux)
+}
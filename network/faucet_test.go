@@ -0,0 +1,47 @@
+package network
+
+import (
+	"net/http"
+	"testing"
+)
+
+func TestClientIP(t *testing.T) {
+	trustedProxies, trustedHosts, err := parseTrustedProxies([]string{"10.0.0.0/24", "192.168.1.1", "localhost"})
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	f := &faucet{trustedProxies: trustedProxies, trustedHosts: trustedHosts}
+
+	tests := []struct {
+		name       string
+		remoteAddr string
+		realIP     string
+		expected   string
+	}{
+		{"no header", "10.0.0.5:1234", "", "10.0.0.5"},
+		{"trusted cidr", "10.0.0.5:1234", "1.2.3.4", "1.2.3.4"},
+		{"trusted ip", "192.168.1.1:1234", "1.2.3.4", "1.2.3.4"},
+		{"trusted host", "127.0.0.1:1234", "1.2.3.4", "1.2.3.4"},
+		{"other pod of the cluster", "10.0.1.5:1234", "1.2.3.4", "10.0.1.5"},
+		{"client", "5.6.7.8:1234", "1.2.3.4", "5.6.7.8"},
+		{"invalid header", "10.0.0.5:1234", "unknown", "10.0.0.5"},
+	}
+
+	for _, test := range tests {
+		r := &http.Request{RemoteAddr: test.remoteAddr, Header: http.Header{}}
+
+		if test.realIP != "" {
+			r.Header.Set("X-Real-IP", test.realIP)
+		}
+
+		if ip := f.clientIP(r); ip != test.expected {
+			t.Errorf("%s: clientIP() = %s, expected %s", test.name, ip, test.expected)
+		}
+	}
+
+	if _, _, err = parseTrustedProxies([]string{"10.0.0.0/33"}); err == nil {
+		t.Error("invalid CIDR was accepted")
+	}
+}
@@ -0,0 +1,78 @@
+package network
+
+import (
+	"io/ioutil"
+
+	gabs "github.com/Jeffail/gabs/v2"
+	cfg "github.com/spacemeshos/go-spacecraft/config"
+)
+
+// readGroupConfigs parses the go-spacemesh config overrides of all miner groups
+func readGroupConfigs() (map[string]*gabs.Container, error) {
+	overrides := map[string]*gabs.Container{}
+
+	for _, group := range config.MinerGroups {
+		if group.Config == "" {
+			continue
+		}
+
+		buf, err := ioutil.ReadFile(group.Config)
+
+		if err != nil {
+			return nil, err
+		}
+
+		override, err := gabs.ParseJSON(buf)
+
+		if err != nil {
+			return nil, err
+		}
+
+		overrides[group.Name] = override
+	}
+
+	return overrides, nil
+}
+
+// mergeConfig recursively overwrites the values of base with the values of override
+func mergeConfig(base *gabs.Container, override *gabs.Container) error {
+	for key, value := range override.ChildrenMap() {
+		_, isObject := value.Data().(map[string]interface{})
+		_, baseIsObject := base.Search(key).Data().(map[string]interface{})
+
+		if isObject && baseIsObject {
+			if err := mergeConfig(base.Search(key), value); err != nil {
+				return err
+			}
+
+			continue
+		}
+
+		if _, err := base.Set(value.Data(), key); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// groupConfig returns the go-spacemesh config of a miner in the group
+func groupConfig(base *gabs.Container, group cfg.MinerGroup, overrides map[string]*gabs.Container) (string, error) {
+	override, ok := overrides[group.Name]
+
+	if !ok {
+		return base.String(), nil
+	}
+
+	minerConfigJson, err := gabs.ParseJSON(base.Bytes())
+
+	if err != nil {
+		return "", err
+	}
+
+	if err = mergeConfig(minerConfigJson, override); err != nil {
+		return "", err
+	}
+
+	return minerConfigJson.String(), nil
+}
@@ -18,7 +18,7 @@ func ListHosts() error {
 
 	kubernetes := k8s.Kubernetes{Client: k8sClient, RestConfig: k8sRestConfig}
 
-	miners, err := kubernetes.GetMiners()
+	hosts, miners, err := getMinerHosts(&kubernetes)
 
 	if err != nil {
 		return err
@@ -26,23 +26,12 @@ func ListHosts() error {
 
 	apiURLs := []string{}
 
-	ip, err := kubernetes.GetExternalIP()
-
-	if err != nil {
-		return err
-	}
-
 	log.Info.Println("API URLs: ")
 
 	for _, miner := range miners {
-		port, err := kubernetes.GetExternalPort(miner, "grpcport")
-		if err != nil {
-			return err
-		}
-
-		fmt.Println(miner + ":" + ip + ":" + port)
+		fmt.Println(miner + ":" + hosts[miner])
 
-		apiURLs = append(apiURLs, ip+":"+port+"/"+miner)
+		apiURLs = append(apiURLs, hosts[miner]+"/"+miner)
 	}
 
 	log.Info.Println("Spacemesh Watch: ")
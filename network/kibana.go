@@ -0,0 +1,97 @@
+package network
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/spacemeshos/go-spacecraft/gcp"
+	"github.com/spacemeshos/go-spacecraft/k8s"
+	"github.com/spacemeshos/go-spacecraft/log"
+)
+
+func ExportKibana() error {
+	k8sRestConfig, k8sClient, err := gcp.GetKubernetesClient(config.NetworkName)
+
+	if err != nil {
+		return err
+	}
+
+	kubernetes := k8s.Kubernetes{Client: k8sClient, RestConfig: k8sRestConfig}
+
+	return kubernetes.ExportKibanaSavedObjects(config.KibanaSavedObjects)
+}
+
+// ImportKibana imports the saved objects into the network, or into every
+// network with elasticsearch. A shared kibana is only imported into once and
+// the networks which fail are reported after the others were imported.
+func ImportKibana() error {
+	networks := []string{config.NetworkName}
+
+	if config.AllNetworks {
+		clusters, err := gcp.GetClusters()
+
+		if err != nil {
+			return err
+		}
+
+		networks = clusters
+	}
+
+	imported := map[string]bool{}
+	failed := []string{}
+
+	for _, name := range networks {
+		k8sRestConfig, k8sClient, err := gcp.GetKubernetesClient(name)
+
+		if err != nil {
+			log.Error.Println(name + ": " + err.Error())
+			failed = append(failed, name)
+			continue
+		}
+
+		kubernetes := k8s.Kubernetes{Client: k8sClient, RestConfig: k8sRestConfig}
+
+		logBackend, err := kubernetes.GetLogBackend()
+
+		if err != nil {
+			log.Error.Println(name + ": " + err.Error())
+			failed = append(failed, name)
+			continue
+		}
+
+		if logBackend != "elk" {
+			fmt.Println("skipping " + name + ", it uses log backend " + logBackend)
+			continue
+		}
+
+		kibanaURL, err := kubernetes.KibanaURL()
+
+		if err != nil {
+			log.Error.Println(name + ": " + err.Error())
+			failed = append(failed, name)
+			continue
+		}
+
+		if imported[kibanaURL] {
+			fmt.Println("skipping " + name + ", its kibana is already updated")
+			continue
+		}
+
+		fmt.Println("importing saved objects to " + name)
+
+		if err = kubernetes.ImportKibanaSavedObjects(config.KibanaSavedObjects); err != nil {
+			log.Error.Println(name + ": " + err.Error())
+			failed = append(failed, name)
+			continue
+		}
+
+		imported[kibanaURL] = true
+	}
+
+	if len(failed) > 0 {
+		return errors.New("failed to import saved objects to " + strings.Join(failed, ", "))
+	}
+
+	return nil
+}
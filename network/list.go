@@ -5,6 +5,7 @@ import (
 	"fmt"
 
 	gabs "github.com/Jeffail/gabs/v2"
+	"github.com/spacemeshos/go-spacecraft/dns"
 	"github.com/spacemeshos/go-spacecraft/gcp"
 	k8s "github.com/spacemeshos/go-spacecraft/k8s"
 	"github.com/spacemeshos/go-spacecraft/log"
@@ -21,6 +22,14 @@ func ListNetworks() error {
 		log.Error.Println("No networks found")
 	} else {
 		log.Info.Println("Here is the list of deployed networks:")
+
+		// networks without saved DNS settings use the options
+		dnsOptions := map[string]string{}
+
+		for option, value := range dns.Settings() {
+			dnsOptions[option] = *value
+		}
+
 		for _, name := range networks {
 			k8sRestConfig, k8sClient, err := gcp.GetKubernetesClient(name)
 
@@ -30,13 +39,43 @@ func ListNetworks() error {
 
 			kubernetes := k8s.Kubernetes{Client: k8sClient, RestConfig: k8sRestConfig}
 
+			for option, value := range dns.Settings() {
+				*value = dnsOptions[option]
+			}
+
+			if err = kubernetes.LoadDNSSettings(); err != nil {
+				return err
+			}
+
 			pyroscopeURL, err := kubernetes.GetPyroscopeURL()
 
 			if err != nil {
 				return err
 			}
 
-			kibanaPassword, err := kubernetes.GetKibanaPassword()
+			logBackend, err := kubernetes.GetLogBackend()
+
+			if err != nil {
+				return err
+			}
+
+			kibana := ""
+
+			if logBackend == "elk" {
+				es, err := kubernetes.GetESConnection()
+
+				if err != nil {
+					return err
+				}
+
+				if es.External {
+					kibana = fmt.Sprintf("Kibana URL: %s\nKibana Index Pattern: %s\n", es.KibanaURL, es.Index("sm-*"))
+				} else {
+					kibana = fmt.Sprintf("Kibana URL: https://%s\nKibana Password: %s\n", dns.Domain("kibana", name), es.Password)
+				}
+			}
+
+			grafanaPassword, err := kubernetes.GrafanaPassword()
 
 			if err != nil {
 				return err
@@ -66,21 +105,21 @@ func ListNetworks() error {
 			log.Success.Print("\nNetwork Name: " + name)
 			fmt.Printf(`
 NETID: %s
-Kibana URL: https://kibana-%s.spacemesh.io
-Kibana Password: %s
-Grafana URL: https://grafana-%s.spacemesh.io
+Log Backend: %s
+%sGrafana URL: https://%s
 Grafana Username: admin
-Grafana Password: prom-operator
-Prometheus URL: https://prometheus-%s.spacemesh.io
+Grafana Password: %s
+Prometheus URL: https://%s
 Pyroscope URL: http://%s
 Config: https://storage.googleapis.com/spacecraft-data/%s-archive/config.json
 Docker URL: %s
 `,
 				fmt.Sprintf("%v", netID),
-				name,
-				kibanaPassword,
-				name,
-				name,
+				logBackend,
+				kibana,
+				dns.Domain("grafana", name),
+				grafanaPassword,
+				dns.Domain("prometheus", name),
 				pyroscopeURL,
 				name,
 				image,
@@ -0,0 +1,347 @@
+package network
+
+import (
+	"context"
+	"crypto/ed25519"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"math"
+	"math/rand"
+	"sort"
+	"strconv"
+	"time"
+
+	pb "github.com/spacemeshos/api/release/go/spacemesh/v1"
+	"github.com/spacemeshos/go-spacecraft/gcp"
+	k8s "github.com/spacemeshos/go-spacecraft/k8s"
+	"github.com/spacemeshos/go-spacecraft/log"
+)
+
+const transferGasLimit = 100
+
+// transactions not included after the load has stopped are reported as pending
+const inclusionTimeout = 5 * time.Minute
+
+type loadgenAccount struct {
+	Miner      string
+	PrivateKey ed25519.PrivateKey
+	Address    []byte
+	Nonce      uint64
+	Balance    uint64
+}
+
+type loadgenStats struct {
+	Submitted int
+	Accepted  int
+	Included  int
+	Failures  map[string]int
+	Latencies []time.Duration
+	pending   map[string]time.Time
+}
+
+// loadTarget returns the number of transactions that should have been
+// submitted after elapsed according to the load pattern
+func loadTarget(pattern string, rate float64, elapsed time.Duration, duration time.Duration) int {
+	seconds := elapsed.Seconds()
+
+	switch pattern {
+	case "ramp":
+		// the rate grows linearly from zero to rate over the duration
+		return int(rate * seconds * seconds / (2 * duration.Seconds()))
+	case "burst":
+		// the load of every 10 seconds is submitted at once
+		return int(rate * 10 * (math.Floor(seconds/10) + 1))
+	}
+
+	return int(rate * seconds)
+}
+
+func readLoadgenAccounts(kubernetes *k8s.Kubernetes, client pb.GlobalStateServiceClient) ([]*loadgenAccount, error) {
+	curveKeys, err := kubernetes.GetCoinbaseKeys()
+
+	if err != nil {
+		return nil, err
+	}
+
+	if legacyKeys := curveKeys[k8s.LegacyCoinbaseKeyCurve]; len(legacyKeys) > 0 {
+		fmt.Printf("skipping %d miners with %s coinbase keys\n", len(legacyKeys), k8s.LegacyCoinbaseKeyCurve)
+	}
+
+	keys := curveKeys[k8s.CoinbaseKeyCurve]
+
+	accounts := []*loadgenAccount{}
+
+	for miner, key := range keys {
+		privateKey, err := k8s.ParseCoinbaseKey(key)
+
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", miner, err)
+		}
+
+		account := &loadgenAccount{
+			Miner:      miner,
+			PrivateKey: privateKey,
+			Address:    addressOf(privateKey.Public().(ed25519.PublicKey)),
+		}
+
+		data, err := accountData(client, account.Address, pb.AccountDataFlag_ACCOUNT_DATA_FLAG_ACCOUNT)
+
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", miner, err)
+		}
+
+		for _, item := range data {
+			if wrapper := item.GetAccountWrapper(); wrapper != nil {
+				account.Nonce = wrapper.StateProjected.Counter
+				account.Balance = wrapper.StateProjected.Balance.Value
+			}
+		}
+
+		accounts = append(accounts, account)
+	}
+
+	sort.Slice(accounts, func(i, j int) bool {
+		return minerIndex(accounts[i].Miner) < minerIndex(accounts[j].Miner)
+	})
+
+	return accounts, nil
+}
+
+func (stats *loadgenStats) submit(client pb.TransactionServiceClient, sender *loadgenAccount, recipient *loadgenAccount) {
+	stats.Submitted++
+
+	tx := signedTransfer(sender.PrivateKey, sender.Nonce, recipient.Address, transferGasLimit, config.LoadgenFee, config.LoadgenAmount)
+	r, err := client.SubmitTransaction(context.Background(), &pb.SubmitTransactionRequest{Transaction: tx})
+
+	if err != nil {
+		stats.Failures["submit error"]++
+		return
+	}
+
+	if r.Status != nil && r.Status.Code != 0 {
+		stats.Failures[r.Status.Message]++
+		return
+	}
+
+	if r.Txstate == nil {
+		stats.Failures["no transaction state"]++
+		return
+	}
+
+	switch r.Txstate.State {
+	case pb.TransactionState_TRANSACTION_STATE_REJECTED,
+		pb.TransactionState_TRANSACTION_STATE_INSUFFICIENT_FUNDS,
+		pb.TransactionState_TRANSACTION_STATE_CONFLICTING:
+		stats.Failures[r.Txstate.State.String()]++
+		return
+	}
+
+	stats.Accepted++
+	sender.Nonce++
+	sender.Balance -= config.LoadgenAmount + config.LoadgenFee
+	stats.pending[hex.EncodeToString(r.Txstate.Id.Id)] = time.Now()
+}
+
+// poll checks which of the pending transactions made it to the mesh
+func (stats *loadgenStats) poll(client pb.TransactionServiceClient) error {
+	if len(stats.pending) == 0 {
+		return nil
+	}
+
+	ids := []*pb.TransactionId{}
+
+	for id := range stats.pending {
+		data, _ := hex.DecodeString(id)
+		ids = append(ids, &pb.TransactionId{Id: data})
+	}
+
+	r, err := client.TransactionsState(context.Background(), &pb.TransactionsStateRequest{TransactionId: ids})
+
+	if err != nil {
+		return err
+	}
+
+	for _, state := range r.TransactionsState {
+		id := hex.EncodeToString(state.Id.Id)
+		submitted, ok := stats.pending[id]
+
+		if !ok {
+			continue
+		}
+
+		switch state.State {
+		case pb.TransactionState_TRANSACTION_STATE_MESH, pb.TransactionState_TRANSACTION_STATE_PROCESSED:
+			stats.Included++
+			stats.Latencies = append(stats.Latencies, time.Since(submitted))
+			delete(stats.pending, id)
+		case pb.TransactionState_TRANSACTION_STATE_REJECTED,
+			pb.TransactionState_TRANSACTION_STATE_INSUFFICIENT_FUNDS,
+			pb.TransactionState_TRANSACTION_STATE_CONFLICTING:
+			stats.Failures[state.State.String()]++
+			delete(stats.pending, id)
+		}
+	}
+
+	return nil
+}
+
+func (stats *loadgenStats) print(elapsed time.Duration) {
+	log.Info.Println("Load generator report:")
+	fmt.Println("Duration: " + elapsed.Round(time.Second).String())
+	fmt.Println("Submitted: " + strconv.Itoa(stats.Submitted))
+	fmt.Printf("Submission Rate: %.2f tx/s\n", float64(stats.Submitted)/elapsed.Seconds())
+	fmt.Println("Accepted: " + strconv.Itoa(stats.Accepted))
+	fmt.Println("Included: " + strconv.Itoa(stats.Included))
+	fmt.Println("Pending: " + strconv.Itoa(len(stats.pending)))
+
+	if len(stats.Latencies) > 0 {
+		sort.Slice(stats.Latencies, func(i, j int) bool {
+			return stats.Latencies[i] < stats.Latencies[j]
+		})
+
+		total := time.Duration(0)
+
+		for _, latency := range stats.Latencies {
+			total += latency
+		}
+
+		percentile := func(p float64) time.Duration {
+			return stats.Latencies[int(p*float64(len(stats.Latencies)-1))].Round(time.Second)
+		}
+
+		fmt.Println("Inclusion Latency (avg/p50/p95/max): " +
+			(total / time.Duration(len(stats.Latencies))).Round(time.Second).String() + "/" +
+			percentile(0.5).String() + "/" + percentile(0.95).String() + "/" + percentile(1).String())
+	}
+
+	if len(stats.Failures) > 0 {
+		log.Error.Println("Failures:")
+
+		for reason, count := range stats.Failures {
+			fmt.Println(reason + ": " + strconv.Itoa(count))
+		}
+	}
+}
+
+// Loadgen submits transfers between the coinbase accounts of the managed
+// miners at LoadgenRate transactions per second for LoadgenDuration and
+// reports how many of them were included and how long it took.
+func Loadgen() error {
+	if config.LoadgenRate <= 0 {
+		return errors.New("rate must be positive")
+	}
+
+	duration, err := time.ParseDuration(config.LoadgenDuration)
+
+	if err != nil {
+		return err
+	}
+
+	if config.LoadgenPattern != "constant" && config.LoadgenPattern != "ramp" && config.LoadgenPattern != "burst" {
+		return errors.New("unknown load pattern: " + config.LoadgenPattern)
+	}
+
+	k8sRestConfig, k8sClient, err := gcp.GetKubernetesClient(config.NetworkName)
+
+	if err != nil {
+		return err
+	}
+
+	kubernetes := k8s.Kubernetes{Client: k8sClient, RestConfig: k8sRestConfig}
+
+	host := config.Host
+
+	if host == "" {
+		host, err = healthyMinerHost(&kubernetes)
+
+		if err != nil {
+			return err
+		}
+	}
+
+	conn, err := dialMiner(host)
+
+	if err != nil {
+		return err
+	}
+
+	defer conn.Close()
+
+	txClient := pb.NewTransactionServiceClient(conn)
+
+	accounts, err := readLoadgenAccounts(&kubernetes, pb.NewGlobalStateServiceClient(conn))
+
+	if err != nil {
+		return err
+	}
+
+	if len(accounts) < 2 {
+		return errors.New("at least two coinbase accounts are needed")
+	}
+
+	senders := []*loadgenAccount{}
+
+	for _, account := range accounts {
+		if account.Balance >= config.LoadgenAmount+config.LoadgenFee {
+			senders = append(senders, account)
+		}
+	}
+
+	if len(senders) == 0 {
+		return errors.New("no coinbase account has enough balance, wait for the miners to earn rewards")
+	}
+
+	fmt.Printf("submitting %s load of %.2f tx/s for %s using %d funded accounts\n", config.LoadgenPattern, config.LoadgenRate, duration, len(senders))
+
+	stats := &loadgenStats{Failures: map[string]int{}, pending: map[string]time.Time{}}
+	start := time.Now()
+	lastPoll := start
+
+	for range time.Tick(100 * time.Millisecond) {
+		elapsed := time.Since(start)
+
+		if elapsed >= duration {
+			break
+		}
+
+		for stats.Submitted < loadTarget(config.LoadgenPattern, config.LoadgenRate, elapsed, duration) {
+			sender := senders[rand.Intn(len(senders))]
+			recipient := accounts[rand.Intn(len(accounts))]
+
+			if sender.Balance < config.LoadgenAmount+config.LoadgenFee {
+				stats.Submitted++
+				stats.Failures["sender out of funds"]++
+				continue
+			}
+
+			stats.submit(txClient, sender, recipient)
+		}
+
+		if time.Since(lastPoll) >= 5*time.Second {
+			if err = stats.poll(txClient); err != nil {
+				log.Error.Println(err)
+			}
+
+			lastPoll = time.Now()
+			fmt.Printf("submitted=%d accepted=%d included=%d pending=%d\n", stats.Submitted, stats.Accepted, stats.Included, len(stats.pending))
+		}
+	}
+
+	elapsed := time.Since(start)
+	deadline := time.Now().Add(inclusionTimeout)
+
+	for len(stats.pending) > 0 && time.Now().Before(deadline) {
+		fmt.Println("waiting for " + strconv.Itoa(len(stats.pending)) + " transactions to be included")
+		time.Sleep(10 * time.Second)
+
+		if err = stats.poll(txClient); err != nil {
+			log.Error.Println(err)
+		}
+	}
+
+	fmt.Println()
+	stats.print(elapsed)
+
+	return nil
+}
@@ -0,0 +1,477 @@
+package network
+
+import (
+	"compress/gzip"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/spacemeshos/go-spacecraft/gcp"
+	k8s "github.com/spacemeshos/go-spacecraft/k8s"
+)
+
+type logHit struct {
+	ID     string                 `json:"_id"`
+	Source map[string]interface{} `json:"_source"`
+	Sort   []interface{}          `json:"sort"`
+}
+
+type logSearchResponse struct {
+	ScrollID string `json:"_scroll_id"`
+	Hits     struct {
+		Hits []logHit `json:"hits"`
+	} `json:"hits"`
+}
+
+// parseLogTime accepts a time in RFC3339 or a duration before now, e.g., 1h
+func parseLogTime(value string) (time.Time, error) {
+	if duration, err := time.ParseDuration(value); err == nil {
+		return time.Now().Add(-duration), nil
+	}
+
+	t, err := time.Parse(time.RFC3339, value)
+
+	if err != nil {
+		return time.Time{}, errors.New("invalid time " + value + ", use RFC3339 or a duration like 30m")
+	}
+
+	return t, nil
+}
+
+func logFilters(kubernetes *k8s.Kubernetes) ([]interface{}, error) {
+	filters := []interface{}{}
+
+	if len(config.LogMiners) > 0 {
+		filters = append(filters, map[string]interface{}{
+			"terms": map[string]interface{}{"name.keyword": config.LogMiners},
+		})
+	}
+
+	if config.LogLevel != "" {
+		filters = append(filters, map[string]interface{}{
+			"match": map[string]interface{}{"sm.L": config.LogLevel},
+		})
+	}
+
+	if config.LogModule != "" {
+		filters = append(filters, map[string]interface{}{
+			"wildcard": map[string]interface{}{"sm.N": "*" + config.LogModule + "*"},
+		})
+	}
+
+	if config.LogQuery != "" {
+		filters = append(filters, map[string]interface{}{
+			"simple_query_string": map[string]interface{}{"query": config.LogQuery, "default_operator": "and"},
+		})
+	}
+
+	timeRange := map[string]interface{}{}
+
+	if config.LogSince != "" {
+		since, err := parseLogTime(config.LogSince)
+
+		if err != nil {
+			return nil, err
+		}
+
+		timeRange["gte"] = since.UTC().Format(time.RFC3339Nano)
+	}
+
+	if config.LogUntil != "" {
+		until, err := parseLogTime(config.LogUntil)
+
+		if err != nil {
+			return nil, err
+		}
+
+		timeRange["lte"] = until.UTC().Format(time.RFC3339Nano)
+	}
+
+	if len(timeRange) > 0 {
+		filters = append(filters, map[string]interface{}{
+			"range": map[string]interface{}{"@timestamp": timeRange},
+		})
+	}
+
+	if config.FromLayer > 0 || config.ToLayer > 0 {
+		filter, err := layerFilter(kubernetes)
+
+		if err != nil {
+			return nil, err
+		}
+
+		filters = append(filters, filter)
+	}
+
+	return filters, nil
+}
+
+// maxLayerTerms is the default max_terms_count of elasticsearch, i.e., the
+// most layers a terms query of sm.layer_id.keyword can match
+const maxLayerTerms = 65536
+
+type fieldCapsResponse struct {
+	Fields map[string]map[string]struct {
+		Indices []string `json:"indices"`
+	} `json:"fields"`
+}
+
+// layerFilter filters by the layer range. sm.layer_id is a number since the
+// index template of the filebeat pipeline, in older indices it's text and
+// the layers are matched as terms of sm.layer_id.keyword instead.
+func layerFilter(kubernetes *k8s.Kubernetes) (interface{}, error) {
+	layerRange := map[string]interface{}{"gte": config.FromLayer}
+
+	if config.ToLayer > 0 {
+		layerRange["lte"] = config.ToLayer
+	}
+
+	numericFilter := map[string]interface{}{
+		"range": map[string]interface{}{"sm.layer_id": layerRange},
+	}
+
+	index, err := logIndex(kubernetes)
+
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := kubernetes.ESRequest(http.MethodGet, "/"+index+"/_field_caps?fields=sm.layer_id", nil)
+
+	if err != nil {
+		return nil, err
+	}
+
+	response := fieldCapsResponse{}
+
+	if err = json.Unmarshal(data, &response); err != nil {
+		return nil, err
+	}
+
+	types := response.Fields["sm.layer_id"]
+	textTypes := map[string]bool{"text": true, "keyword": true}
+	numericIndices := []string{}
+	textIndices := []string{}
+	text := false
+
+	for fieldType, caps := range types {
+		if textTypes[fieldType] {
+			text = true
+			textIndices = append(textIndices, caps.Indices...)
+		} else {
+			numericIndices = append(numericIndices, caps.Indices...)
+		}
+	}
+
+	if !text {
+		return numericFilter, nil
+	}
+
+	if config.ToLayer <= 0 {
+		return nil, errors.New("sm.layer_id is text in some indices of " + index + ", set --to-layer to filter them by layer")
+	}
+
+	if config.ToLayer-config.FromLayer+1 > maxLayerTerms {
+		return nil, fmt.Errorf("sm.layer_id is text in some indices of %s, where at most %d layers can be searched at once", index, maxLayerTerms)
+	}
+
+	layers := []string{}
+
+	for layer := config.FromLayer; layer <= config.ToLayer; layer++ {
+		layers = append(layers, strconv.Itoa(layer))
+	}
+
+	textFilter := map[string]interface{}{
+		"terms": map[string]interface{}{"sm.layer_id.keyword": layers},
+	}
+
+	// the indices are only listed if the field has more than one type
+	if len(types) == 1 {
+		return textFilter, nil
+	}
+
+	return map[string]interface{}{
+		"bool": map[string]interface{}{
+			"minimum_should_match": 1,
+			"should": []interface{}{
+				logQuery([]interface{}{map[string]interface{}{"terms": map[string]interface{}{"_index": numericIndices}}, numericFilter}),
+				logQuery([]interface{}{map[string]interface{}{"terms": map[string]interface{}{"_index": textIndices}}, textFilter}),
+			},
+		},
+	}, nil
+}
+
+func logQuery(filters []interface{}) map[string]interface{} {
+	return map[string]interface{}{
+		"bool": map[string]interface{}{"filter": filters},
+	}
+}
+
+// formatLog prints the well known fields of go-spacemesh logs first
+func formatLog(source map[string]interface{}) string {
+	sm, _ := source["sm"].(map[string]interface{})
+	line := fmt.Sprintf("%v %v %v %v %v", source["@timestamp"], source["name"], sm["L"], sm["N"], sm["M"])
+
+	keys := []string{}
+
+	for key := range sm {
+		if key != "L" && key != "N" && key != "M" {
+			keys = append(keys, key)
+		}
+	}
+
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		line += fmt.Sprintf(" %s=%v", key, sm[key])
+	}
+
+	return line
+}
+
+// logIndex prefixes the sm-* and ws-* index patterns in a shared elasticsearch
+func logIndex(kubernetes *k8s.Kubernetes) (string, error) {
+	es, err := kubernetes.GetESConnection()
+
+	if err != nil {
+		return "", err
+	}
+
+	if strings.HasPrefix(config.LogIndex, "sm-") || strings.HasPrefix(config.LogIndex, "ws-") {
+		return es.Index(config.LogIndex), nil
+	}
+
+	return config.LogIndex, nil
+}
+
+func searchLogs(kubernetes *k8s.Kubernetes, body map[string]interface{}) ([]logHit, error) {
+	index, err := logIndex(kubernetes)
+
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := kubernetes.ESRequest(http.MethodPost, "/"+index+"/_search", body)
+
+	if err != nil {
+		return nil, err
+	}
+
+	response := logSearchResponse{}
+
+	if err = json.Unmarshal(data, &response); err != nil {
+		return nil, err
+	}
+
+	return response.Hits.Hits, nil
+}
+
+// followLogs polls for logs newer than the last printed log
+func followLogs(kubernetes *k8s.Kubernetes, filters []interface{}, last string, seen map[string]bool) error {
+	for range time.Tick(2 * time.Second) {
+		query := filters
+
+		if last != "" {
+			query = append(append([]interface{}{}, filters...), map[string]interface{}{
+				"range": map[string]interface{}{"@timestamp": map[string]interface{}{"gte": last}},
+			})
+		}
+
+		hits, err := searchLogs(kubernetes, map[string]interface{}{
+			"size":  1000,
+			"sort":  []interface{}{map[string]interface{}{"@timestamp": "asc"}},
+			"query": logQuery(query),
+		})
+
+		if err != nil {
+			return err
+		}
+
+		for _, hit := range hits {
+			if seen[hit.ID] {
+				continue
+			}
+
+			timestamp, _ := hit.Source["@timestamp"].(string)
+
+			// logs with the same timestamp as the last log are fetched again
+			if timestamp != last {
+				seen = map[string]bool{}
+				last = timestamp
+			}
+
+			seen[hit.ID] = true
+			fmt.Println(formatLog(hit.Source))
+		}
+	}
+
+	return nil
+}
+
+// createLogExport creates the LogExport file, gzip compressed if it ends with .gz
+func createLogExport() (io.Writer, func(), error) {
+	file, err := os.Create(config.LogExport)
+
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if !strings.HasSuffix(config.LogExport, ".gz") {
+		return file, func() { file.Close() }, nil
+	}
+
+	gzipWriter := gzip.NewWriter(file)
+
+	return gzipWriter, func() {
+		gzipWriter.Close()
+		file.Close()
+	}, nil
+}
+
+func writeLog(writer io.Writer, source map[string]interface{}) error {
+	line, err := json.Marshal(source)
+
+	if err != nil {
+		return err
+	}
+
+	_, err = writer.Write(append(line, '\n'))
+
+	return err
+}
+
+// exportLogs writes all matching logs as NDJSON, gzip compressed if the file ends with .gz
+func exportLogs(kubernetes *k8s.Kubernetes, filters []interface{}) error {
+	writer, closeExport, err := createLogExport()
+
+	if err != nil {
+		return err
+	}
+
+	defer closeExport()
+
+	index, err := logIndex(kubernetes)
+
+	if err != nil {
+		return err
+	}
+
+	data, err := kubernetes.ESRequest(http.MethodPost, "/"+index+"/_search?scroll=1m", map[string]interface{}{
+		"size":  5000,
+		"sort":  []interface{}{map[string]interface{}{"@timestamp": "asc"}},
+		"query": logQuery(filters),
+	})
+
+	if err != nil {
+		return err
+	}
+
+	exported := 0
+
+	for {
+		response := logSearchResponse{}
+
+		if err = json.Unmarshal(data, &response); err != nil {
+			return err
+		}
+
+		if len(response.Hits.Hits) == 0 {
+			kubernetes.ESRequest(http.MethodDelete, "/_search/scroll", map[string]interface{}{"scroll_id": response.ScrollID})
+			break
+		}
+
+		for _, hit := range response.Hits.Hits {
+			if err = writeLog(writer, hit.Source); err != nil {
+				return err
+			}
+		}
+
+		exported += len(response.Hits.Hits)
+		fmt.Println("exported " + strconv.Itoa(exported) + " logs")
+
+		data, err = kubernetes.ESRequest(http.MethodPost, "/_search/scroll", map[string]interface{}{
+			"scroll":    "1m",
+			"scroll_id": response.ScrollID,
+		})
+
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Logs prints the latest LogLimit logs matching the filters in chronological
+// order and optionally follows new logs, or exports all matching logs.
+func Logs() error {
+	k8sRestConfig, k8sClient, err := gcp.GetKubernetesClient(config.NetworkName)
+
+	if err != nil {
+		return err
+	}
+
+	kubernetes := k8s.Kubernetes{Client: k8sClient, RestConfig: k8sRestConfig}
+
+	logBackend, err := kubernetes.GetLogBackend()
+
+	if err != nil {
+		return err
+	}
+
+	switch logBackend {
+	case "loki":
+		return lokiLogs(&kubernetes)
+	case "none":
+		return errors.New("logs of " + config.NetworkName + " are not stored, it uses log backend none")
+	}
+
+	filters, err := logFilters(&kubernetes)
+
+	if err != nil {
+		return err
+	}
+
+	if config.LogExport != "" {
+		return exportLogs(&kubernetes, filters)
+	}
+
+	hits, err := searchLogs(&kubernetes, map[string]interface{}{
+		"size":  config.LogLimit,
+		"sort":  []interface{}{map[string]interface{}{"@timestamp": "desc"}},
+		"query": logQuery(filters),
+	})
+
+	if err != nil {
+		return err
+	}
+
+	last := ""
+	seen := map[string]bool{}
+
+	for i := len(hits) - 1; i >= 0; i-- {
+		fmt.Println(formatLog(hits[i].Source))
+
+		timestamp, _ := hits[i].Source["@timestamp"].(string)
+
+		if timestamp != last {
+			seen = map[string]bool{}
+			last = timestamp
+		}
+
+		seen[hits[i].ID] = true
+	}
+
+	if config.LogFollow {
+		return followLogs(&kubernetes, filters, last, seen)
+	}
+
+	return nil
+}
@@ -0,0 +1,116 @@
+package network
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestParseLogTime(t *testing.T) {
+	tests := []struct {
+		value    string
+		expected time.Time
+		ago      time.Duration
+		valid    bool
+	}{
+		{value: "2021-05-01T00:00:00Z", expected: time.Date(2021, 5, 1, 0, 0, 0, 0, time.UTC), valid: true},
+		{value: "2021-05-01T02:00:00+02:00", expected: time.Date(2021, 5, 1, 0, 0, 0, 0, time.UTC), valid: true},
+		{value: "30m", ago: 30 * time.Minute, valid: true},
+		{value: "1h30m", ago: 90 * time.Minute, valid: true},
+		{value: "2021-05-01", valid: false},
+		{value: "yesterday", valid: false},
+		{value: "", valid: false},
+	}
+
+	for _, test := range tests {
+		before := time.Now()
+		parsed, err := parseLogTime(test.value)
+		after := time.Now()
+
+		if !test.valid {
+			if err == nil {
+				t.Errorf("%q: parsed %v", test.value, parsed)
+			}
+
+			continue
+		}
+
+		if err != nil {
+			t.Errorf("%q: %v", test.value, err)
+			continue
+		}
+
+		if test.ago > 0 {
+			if parsed.Before(before.Add(-test.ago)) || parsed.After(after.Add(-test.ago)) {
+				t.Errorf("%q: parsed %v, expected %v before now", test.value, parsed, test.ago)
+			}
+
+			continue
+		}
+
+		if !parsed.Equal(test.expected) {
+			t.Errorf("%q: parsed %v, expected %v", test.value, parsed, test.expected)
+		}
+	}
+}
+
+func TestLogFilters(t *testing.T) {
+	saved := *config
+	defer func() { *config = saved }()
+
+	config.LogMiners = []string{"miner-1", "poet-1"}
+	config.LogLevel = "error"
+	config.LogModule = "hare"
+	config.LogQuery = "block not found"
+	config.LogSince = "2021-05-01T00:00:00Z"
+	config.LogUntil = "2021-05-02T00:00:00+02:00"
+	config.FromLayer = 0
+	config.ToLayer = 0
+
+	filters, err := logFilters(nil)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []interface{}{
+		map[string]interface{}{"terms": map[string]interface{}{"name.keyword": []string{"miner-1", "poet-1"}}},
+		map[string]interface{}{"match": map[string]interface{}{"sm.L": "error"}},
+		map[string]interface{}{"wildcard": map[string]interface{}{"sm.N": "*hare*"}},
+		map[string]interface{}{"simple_query_string": map[string]interface{}{"query": "block not found", "default_operator": "and"}},
+		map[string]interface{}{"range": map[string]interface{}{"@timestamp": map[string]interface{}{
+			"gte": "2021-05-01T00:00:00Z",
+			"lte": "2021-05-01T22:00:00Z",
+		}}},
+	}
+
+	if !reflect.DeepEqual(filters, expected) {
+		t.Errorf("filters are %v, expected %v", filters, expected)
+	}
+
+	config.LogSince = "last week"
+
+	if _, err = logFilters(nil); err == nil {
+		t.Error("invalid --log-since was accepted")
+	}
+}
+
+func TestFormatLog(t *testing.T) {
+	source := map[string]interface{}{
+		"@timestamp": "2021-05-01T00:00:00Z",
+		"name":       "miner-1",
+		"sm": map[string]interface{}{
+			"L":        "INFO",
+			"N":        "hare",
+			"M":        "consensus reached",
+			"layer_id": 12,
+			"duration": 1.5,
+		},
+	}
+
+	expected := "2021-05-01T00:00:00Z miner-1 INFO hare consensus reached duration=1.5 layer_id=12"
+
+	if line := formatLog(source); line != expected {
+		t.Errorf("formatLog() = %q, expected %q", line, expected)
+	}
+}
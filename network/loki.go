@@ -0,0 +1,273 @@
+package network
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	k8s "github.com/spacemeshos/go-spacecraft/k8s"
+)
+
+// the default maximum number of entries loki returns for a query
+const lokiPageSize = 5000
+
+type lokiEntry struct {
+	Timestamp int64
+	Line      string
+	Source    map[string]interface{}
+}
+
+// lokiSelector builds a LogQL query with the same filters as the elasticsearch
+// query. The free text query is matched literally.
+func lokiSelector() string {
+	// the same containers as filebeat collects
+	selector := `{namespace="default", container=~"miner|poet"`
+
+	if strings.HasPrefix(config.LogIndex, "ws") {
+		selector = `{namespace="ws", container=~".*node.*"`
+	}
+
+	if len(config.LogMiners) > 0 {
+		names := []string{}
+
+		for _, name := range config.LogMiners {
+			names = append(names, regexp.QuoteMeta(name))
+		}
+
+		selector += `, name=~"` + strings.Join(names, "|") + `"`
+	}
+
+	selector += "}"
+
+	if config.LogQuery != "" {
+		selector += " |= " + strconv.Quote(config.LogQuery)
+	}
+
+	labelFilters := []string{}
+
+	if config.LogLevel != "" {
+		labelFilters = append(labelFilters, `L=~"(?i)`+regexp.QuoteMeta(config.LogLevel)+`"`)
+	}
+
+	if config.LogModule != "" {
+		labelFilters = append(labelFilters, `N=~".*`+regexp.QuoteMeta(config.LogModule)+`.*"`)
+	}
+
+	if config.FromLayer > 0 {
+		labelFilters = append(labelFilters, "layer_id >= "+strconv.Itoa(config.FromLayer))
+	}
+
+	if config.ToLayer > 0 {
+		labelFilters = append(labelFilters, "layer_id <= "+strconv.Itoa(config.ToLayer))
+	}
+
+	if len(labelFilters) > 0 {
+		selector += " | json | " + strings.Join(labelFilters, " | ")
+	}
+
+	return selector
+}
+
+// lokiTimeRange defaults to the logs retention period
+func lokiTimeRange() (time.Time, time.Time, error) {
+	end := time.Now()
+	logsExpiry, _ := strconv.Atoi(config.LogsExpiry)
+	start := end.Add(-time.Duration(logsExpiry) * 24 * time.Hour)
+
+	if config.LogSince != "" {
+		since, err := parseLogTime(config.LogSince)
+
+		if err != nil {
+			return start, end, err
+		}
+
+		start = since
+	}
+
+	if config.LogUntil != "" {
+		until, err := parseLogTime(config.LogUntil)
+
+		if err != nil {
+			return start, end, err
+		}
+
+		end = until
+	}
+
+	return start, end, nil
+}
+
+// queryLoki returns the matching entries of all streams sorted by time
+func queryLoki(kubernetes *k8s.Kubernetes, query string, start int64, end int64, limit int, direction string) ([]lokiEntry, error) {
+	data, err := kubernetes.LokiRequest("/loki/api/v1/query_range", url.Values{
+		"query":     {query},
+		"start":     {strconv.FormatInt(start, 10)},
+		"end":       {strconv.FormatInt(end, 10)},
+		"limit":     {strconv.Itoa(limit)},
+		"direction": {direction},
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	response := struct {
+		Data struct {
+			Result []struct {
+				Stream map[string]string `json:"stream"`
+				Values [][]string        `json:"values"`
+			} `json:"result"`
+		} `json:"data"`
+	}{}
+
+	if err = json.Unmarshal(data, &response); err != nil {
+		return nil, err
+	}
+
+	entries := []lokiEntry{}
+
+	for _, stream := range response.Data.Result {
+		for _, value := range stream.Values {
+			timestamp, err := strconv.ParseInt(value[0], 10, 64)
+
+			if err != nil {
+				return nil, err
+			}
+
+			sm := map[string]interface{}{}
+
+			if err := json.Unmarshal([]byte(value[1]), &sm); err != nil {
+				sm = map[string]interface{}{"message": value[1]}
+			}
+
+			delete(sm, "T")
+
+			entries = append(entries, lokiEntry{
+				Timestamp: timestamp,
+				Line:      value[1],
+				Source: map[string]interface{}{
+					"@timestamp": time.Unix(0, timestamp).UTC().Format(time.RFC3339Nano),
+					"name":       stream.Stream["name"],
+					"sm":         sm,
+				},
+			})
+		}
+	}
+
+	sort.SliceStable(entries, func(i, j int) bool {
+		return entries[i].Timestamp < entries[j].Timestamp
+	})
+
+	return entries, nil
+}
+
+// lokiCursor pages forward through the entries. Entries with the same
+// timestamp as the last seen entry are fetched again and skipped.
+type lokiCursor struct {
+	last int64
+	seen map[string]bool
+}
+
+func (cursor *lokiCursor) next(entries []lokiEntry) []lokiEntry {
+	unseen := []lokiEntry{}
+
+	for _, entry := range entries {
+		key := fmt.Sprintf("%v %s", entry.Source["name"], entry.Line)
+
+		if entry.Timestamp == cursor.last && cursor.seen[key] {
+			continue
+		}
+
+		if entry.Timestamp != cursor.last {
+			cursor.last = entry.Timestamp
+			cursor.seen = map[string]bool{}
+		}
+
+		cursor.seen[key] = true
+		unseen = append(unseen, entry)
+	}
+
+	return unseen
+}
+
+func lokiLogs(kubernetes *k8s.Kubernetes) error {
+	query := lokiSelector()
+	start, end, err := lokiTimeRange()
+
+	if err != nil {
+		return err
+	}
+
+	if config.LogExport != "" {
+		writer, closeExport, err := createLogExport()
+
+		if err != nil {
+			return err
+		}
+
+		defer closeExport()
+
+		cursor := lokiCursor{last: start.UnixNano(), seen: map[string]bool{}}
+		exported := 0
+
+		for {
+			entries, err := queryLoki(kubernetes, query, cursor.last, end.UnixNano(), lokiPageSize, "forward")
+
+			if err != nil {
+				return err
+			}
+
+			entries = cursor.next(entries)
+
+			if len(entries) == 0 {
+				break
+			}
+
+			for _, entry := range entries {
+				if err = writeLog(writer, entry.Source); err != nil {
+					return err
+				}
+			}
+
+			exported += len(entries)
+			fmt.Println("exported " + strconv.Itoa(exported) + " logs")
+		}
+
+		return nil
+	}
+
+	entries, err := queryLoki(kubernetes, query, start.UnixNano(), end.UnixNano(), config.LogLimit, "backward")
+
+	if err != nil {
+		return err
+	}
+
+	cursor := lokiCursor{last: end.UnixNano(), seen: map[string]bool{}}
+
+	for _, entry := range cursor.next(entries) {
+		fmt.Println(formatLog(entry.Source))
+	}
+
+	if !config.LogFollow {
+		return nil
+	}
+
+	for range time.Tick(2 * time.Second) {
+		entries, err := queryLoki(kubernetes, query, cursor.last, time.Now().UnixNano(), lokiPageSize, "forward")
+
+		if err != nil {
+			return err
+		}
+
+		for _, entry := range cursor.next(entries) {
+			fmt.Println(formatLog(entry.Source))
+		}
+	}
+
+	return nil
+}
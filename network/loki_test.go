@@ -0,0 +1,108 @@
+package network
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestLokiSelector(t *testing.T) {
+	saved := *config
+	defer func() { *config = saved }()
+
+	tests := []struct {
+		name      string
+		index     string
+		miners    []string
+		query     string
+		level     string
+		module    string
+		fromLayer int
+		toLayer   int
+		expected  string
+	}{
+		{
+			name:     "miner and poet logs",
+			index:    "sm-*",
+			expected: `{namespace="default", container=~"miner|poet"}`,
+		},
+		{
+			name:     "web services logs",
+			index:    "ws-*",
+			expected: `{namespace="ws", container=~".*node.*"}`,
+		},
+		{
+			name:     "miners are quoted",
+			index:    "sm-*",
+			miners:   []string{"miner-1", "poet.1"},
+			expected: `{namespace="default", container=~"miner|poet", name=~"miner-1|poet\.1"}`,
+		},
+		{
+			name:     "query is matched literally",
+			index:    "sm-*",
+			query:    `block "1" not found`,
+			expected: `{namespace="default", container=~"miner|poet"} |= "block \"1\" not found"`,
+		},
+		{
+			name:      "json label filters",
+			index:     "sm-*",
+			level:     "error",
+			module:    "hare",
+			fromLayer: 10,
+			toLayer:   20,
+			expected:  `{namespace="default", container=~"miner|poet"} | json | L=~"(?i)error" | N=~".*hare.*" | layer_id >= 10 | layer_id <= 20`,
+		},
+		{
+			name:      "open layer range",
+			index:     "sm-*",
+			fromLayer: 10,
+			expected:  `{namespace="default", container=~"miner|poet"} | json | layer_id >= 10`,
+		},
+	}
+
+	for _, test := range tests {
+		config.LogIndex = test.index
+		config.LogMiners = test.miners
+		config.LogQuery = test.query
+		config.LogLevel = test.level
+		config.LogModule = test.module
+		config.FromLayer = test.fromLayer
+		config.ToLayer = test.toLayer
+
+		if selector := lokiSelector(); selector != test.expected {
+			t.Errorf("%s: lokiSelector() = %s, expected %s", test.name, selector, test.expected)
+		}
+	}
+}
+
+func TestLokiCursor(t *testing.T) {
+	entry := func(timestamp int64, name string, line string) lokiEntry {
+		return lokiEntry{Timestamp: timestamp, Line: line, Source: map[string]interface{}{"name": name}}
+	}
+
+	cursor := lokiCursor{}
+
+	pages := []struct {
+		entries  []lokiEntry
+		expected []lokiEntry
+	}{
+		{
+			entries:  []lokiEntry{entry(1, "miner-1", "a"), entry(2, "miner-1", "b"), entry(2, "miner-2", "b")},
+			expected: []lokiEntry{entry(1, "miner-1", "a"), entry(2, "miner-1", "b"), entry(2, "miner-2", "b")},
+		},
+		{
+			// the entries of the last timestamp are fetched again
+			entries:  []lokiEntry{entry(2, "miner-1", "b"), entry(2, "miner-2", "b"), entry(2, "miner-3", "b"), entry(3, "miner-1", "c")},
+			expected: []lokiEntry{entry(2, "miner-3", "b"), entry(3, "miner-1", "c")},
+		},
+		{
+			entries:  []lokiEntry{entry(3, "miner-1", "c")},
+			expected: []lokiEntry{},
+		},
+	}
+
+	for i, page := range pages {
+		if unseen := cursor.next(page.entries); !reflect.DeepEqual(unseen, page.expected) {
+			t.Errorf("page %d: next() = %v, expected %v", i+1, unseen, page.expected)
+		}
+	}
+}
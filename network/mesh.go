@@ -0,0 +1,194 @@
+package network
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	pb "github.com/spacemeshos/api/release/go/spacemesh/v1"
+	k8s "github.com/spacemeshos/go-spacecraft/k8s"
+	"google.golang.org/grpc"
+)
+
+// getMinerHosts returns the GRPC host of every managed miner by miner name
+// and the miner names sorted by miner number
+func getMinerHosts(kubernetes *k8s.Kubernetes) (map[string]string, []string, error) {
+	miners, err := kubernetes.GetMiners()
+
+	if err != nil {
+		return nil, nil, err
+	}
+
+	ip, err := kubernetes.GetExternalIP()
+
+	if err != nil {
+		return nil, nil, err
+	}
+
+	hosts := map[string]string{}
+
+	for _, miner := range miners {
+		port, err := kubernetes.GetExternalPort(miner, "grpcport")
+		if err != nil {
+			return nil, nil, err
+		}
+
+		hosts[miner] = ip + ":" + port
+	}
+
+	sort.Slice(miners, func(i, j int) bool {
+		return minerIndex(miners[i]) < minerIndex(miners[j])
+	})
+
+	return hosts, miners, nil
+}
+
+// healthyMinerHost returns the GRPC host of the first ready miner that answers
+func healthyMinerHost(kubernetes *k8s.Kubernetes) (string, error) {
+	hosts, miners, err := getMinerHosts(kubernetes)
+
+	if err != nil {
+		return "", err
+	}
+
+	minersInfo, err := kubernetes.GetMinersInfo()
+
+	if err != nil {
+		return "", err
+	}
+
+	ready := map[string]bool{}
+
+	for _, miner := range minersInfo {
+		ready[miner.Name] = miner.Ready
+	}
+
+	for _, miner := range miners {
+		if !ready[miner] {
+			continue
+		}
+
+		if _, err := currentLayer(hosts[miner]); err == nil {
+			fmt.Println("connecting to " + miner + " at " + hosts[miner])
+			return hosts[miner], nil
+		}
+	}
+
+	return "", errors.New("no healthy miner found")
+}
+
+func dialMiner(host string) (*grpc.ClientConn, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	return grpc.DialContext(ctx, host, grpc.WithInsecure(), grpc.WithBlock())
+}
+
+func currentLayer(host string) (uint32, error) {
+	conn, err := dialMiner(host)
+
+	if err != nil {
+		return 0, err
+	}
+
+	defer conn.Close()
+
+	r, err := pb.NewMeshServiceClient(conn).CurrentLayer(context.Background(), &pb.CurrentLayerRequest{})
+
+	if err != nil {
+		return 0, err
+	}
+
+	return r.Layernum.Number, nil
+}
+
+func currentEpoch(host string) (uint64, error) {
+	conn, err := dialMiner(host)
+
+	if err != nil {
+		return 0, err
+	}
+
+	defer conn.Close()
+
+	r, err := pb.NewMeshServiceClient(conn).CurrentEpoch(context.Background(), &pb.CurrentEpochRequest{})
+
+	if err != nil {
+		return 0, err
+	}
+
+	return r.Epochnum.Value, nil
+}
+
+func queryLayers(host string, start uint32, end uint32) ([]*pb.Layer, error) {
+	conn, err := dialMiner(host)
+
+	if err != nil {
+		return nil, err
+	}
+
+	defer conn.Close()
+
+	r, err := pb.NewMeshServiceClient(conn).LayersQuery(context.Background(), &pb.LayersQueryRequest{
+		StartLayer: &pb.LayerNumber{Number: start},
+		EndLayer:   &pb.LayerNumber{Number: end},
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	return r.Layer, nil
+}
+
+// waitFor polls the miners until check returns true for one of them
+func waitFor(kubernetes *k8s.Kubernetes, description string, check func(host string) (bool, error)) error {
+	for range time.Tick(10 * time.Second) {
+		hosts, names, err := getMinerHosts(kubernetes)
+
+		if err != nil {
+			return err
+		}
+
+		if len(names) == 0 {
+			return errors.New("no miners found")
+		}
+
+		for _, name := range names {
+			done, err := check(hosts[name])
+
+			if err != nil {
+				fmt.Println(name + ": " + err.Error())
+				continue
+			}
+
+			if done {
+				return nil
+			}
+
+			break
+		}
+
+		fmt.Println("waiting for " + description)
+	}
+
+	return nil
+}
+
+func waitForLayer(kubernetes *k8s.Kubernetes, layer uint32) error {
+	return waitFor(kubernetes, fmt.Sprintf("layer %d", layer), func(host string) (bool, error) {
+		current, err := currentLayer(host)
+
+		return current >= layer, err
+	})
+}
+
+func waitForEpoch(kubernetes *k8s.Kubernetes, epoch uint64) error {
+	return waitFor(kubernetes, fmt.Sprintf("epoch %d", epoch), func(host string) (bool, error) {
+		current, err := currentEpoch(host)
+
+		return current >= epoch, err
+	})
+}
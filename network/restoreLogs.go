@@ -0,0 +1,44 @@
+package network
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+
+	"github.com/spacemeshos/go-spacecraft/gcp"
+	"github.com/spacemeshos/go-spacecraft/k8s"
+)
+
+func RestoreLogs() error {
+	if config.ArchivedNetwork == "" {
+		return errors.New("archived network name is required")
+	}
+
+	k8sRestConfig, k8sClient, err := gcp.GetKubernetesClient(config.NetworkName)
+
+	if err != nil {
+		return err
+	}
+
+	kubernetes := k8s.Kubernetes{Client: k8sClient, RestConfig: k8sRestConfig}
+
+	logBackend, err := kubernetes.GetLogBackend()
+
+	if err != nil {
+		return err
+	}
+
+	if logBackend != "elk" {
+		return errors.New("logs can only be restored into elasticsearch, " + config.NetworkName + " uses log backend " + logBackend)
+	}
+
+	count, err := kubernetes.RestoreLogs(config.ArchivedNetwork)
+
+	if err != nil {
+		return err
+	}
+
+	fmt.Println("restored " + strconv.Itoa(count) + " indices, search them with the index pattern " + config.ArchivedNetwork + "-sm-*")
+
+	return nil
+}
@@ -2,24 +2,234 @@ package network
 
 import (
 	"context"
+	"encoding/csv"
 	"encoding/hex"
+	"encoding/json"
 	"errors"
 	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
 	"strconv"
+	"strings"
 
+	gabs "github.com/Jeffail/gabs/v2"
 	pb "github.com/spacemeshos/api/release/go/spacemesh/v1"
+	cfg "github.com/spacemeshos/go-spacecraft/config"
 	"github.com/spacemeshos/go-spacecraft/gcp"
 	k8s "github.com/spacemeshos/go-spacecraft/k8s"
 	"github.com/spacemeshos/go-spacecraft/log"
-	"google.golang.org/grpc"
-	"google.golang.org/protobuf/types/known/emptypb"
 )
 
-func Rewards() error {
-	if config.Host == "" {
-		return errors.New("You need to specify the host")
+// miners earning less than this share of their expected rewards are flagged
+const lowRewardsRatio = 0.5
+
+type minerRewards struct {
+	Miner         string  `json:"miner"`
+	Cohort        string  `json:"cohort"`
+	Address       string  `json:"address"`
+	Balance       uint64  `json:"balance"`
+	Rewards       uint64  `json:"rewards"`
+	LayerRewards  uint64  `json:"layerRewards"`
+	Fees          uint64  `json:"fees"`
+	RewardLayers  int     `json:"rewardLayers"`
+	FirstLayer    uint32  `json:"firstLayer"`
+	LastLayer     uint32  `json:"lastLayer"`
+	PostSize      uint64  `json:"postSize"`
+	ExpectedShare float64 `json:"expectedShare"`
+	ActualShare   float64 `json:"actualShare"`
+	Ratio         float64 `json:"ratio"`
+	Flag          string  `json:"flag,omitempty"`
+}
+
+type rewardsReport struct {
+	FromLayer uint32         `json:"fromLayer"`
+	ToLayer   uint32         `json:"toLayer"`
+	Total     uint64         `json:"total"`
+	Miners    []minerRewards `json:"miners"`
+}
+
+func (r *rewardsReport) inRange(layer uint32) bool {
+	return layer >= r.FromLayer && (r.ToLayer == 0 || layer <= r.ToLayer)
+}
+
+// accountData pages through the GlobalStateService data of an account
+func accountData(client pb.GlobalStateServiceClient, address []byte, flags pb.AccountDataFlag) ([]*pb.AccountData, error) {
+	data := []*pb.AccountData{}
+
+	for {
+		r, err := client.AccountDataQuery(context.Background(), &pb.AccountDataQueryRequest{
+			Filter: &pb.AccountDataFilter{
+				AccountId:        &pb.AccountId{Address: address},
+				AccountDataFlags: uint32(flags),
+			},
+			MaxResults: 1000,
+			Offset:     uint32(len(data)),
+		})
+
+		if err != nil {
+			return nil, err
+		}
+
+		data = append(data, r.AccountItem...)
+
+		if len(r.AccountItem) == 0 || uint32(len(data)) >= r.TotalResults {
+			return data, nil
+		}
+	}
+}
+
+func queryMinerRewards(client pb.GlobalStateServiceClient, report *rewardsReport, rewards *minerRewards) error {
+	address, err := hex.DecodeString(rewards.Address)
+
+	if err != nil {
+		return err
+	}
+
+	data, err := accountData(client, address, pb.AccountDataFlag_ACCOUNT_DATA_FLAG_ACCOUNT|pb.AccountDataFlag_ACCOUNT_DATA_FLAG_REWARD)
+
+	if err != nil {
+		return err
+	}
+
+	for _, item := range data {
+		if account := item.GetAccountWrapper(); account != nil {
+			rewards.Balance = account.StateCurrent.Balance.Value
+		}
+
+		reward := item.GetReward()
+
+		if reward == nil || !report.inRange(reward.Layer.Number) {
+			continue
+		}
+
+		rewards.Rewards += reward.Total.Value
+		rewards.LayerRewards += reward.LayerReward.Value
+		rewards.Fees += reward.Total.Value - reward.LayerReward.Value
+		rewards.RewardLayers++
+
+		if rewards.FirstLayer == 0 || reward.Layer.Number < rewards.FirstLayer {
+			rewards.FirstLayer = reward.Layer.Number
+		}
+
+		if reward.Layer.Number > rewards.LastLayer {
+			rewards.LastLayer = reward.Layer.Number
+		}
+	}
+
+	return nil
+}
+
+// postSizeFlag matches the flags of a miner group which set the PoST size
+var postSizeFlag = regexp.MustCompile(`--(?:smeshing-opts-numunits|post-space)[= ](\d+)`)
+
+// postSize returns the PoST size of a miner, i.e., the number of space units
+// or the PoST space of older go-spacemesh versions. The flags of the command
+// of the miner override its go-spacemesh config.
+func postSize(command string, configJSON string) uint64 {
+	if match := postSizeFlag.FindStringSubmatch(command); match != nil {
+		size, _ := strconv.ParseUint(match[1], 10, 64)
+
+		return size
+	}
+
+	minerConfig, err := gabs.ParseJSON([]byte(configJSON))
+
+	if err != nil {
+		return 0
+	}
+
+	for _, path := range []string{"smeshing.smeshing-opts.smeshing-opts-numunits", "post.post-space"} {
+		if size, ok := minerConfig.Path(path).Data().(float64); ok && size > 0 {
+			return uint64(size)
+		}
+	}
+
+	return 0
+}
+
+// setExpectedShares sets the share of the rewards every miner is expected to
+// earn, which is proportional to its PoST size. If the size of any miner is
+// unknown every miner is expected to earn an equal share. It returns whether
+// the shares are weighted by the PoST sizes.
+func setExpectedShares(miners []minerRewards) bool {
+	total := uint64(0)
+
+	for _, m := range miners {
+		if m.PostSize == 0 {
+			for i := range miners {
+				miners[i].ExpectedShare = 1 / float64(len(miners))
+			}
+
+			return false
+		}
+
+		total += m.PostSize
+	}
+
+	for i := range miners {
+		miners[i].ExpectedShare = float64(miners[i].PostSize) / float64(total)
+	}
+
+	return true
+}
+
+func writeRewardsReport(report *rewardsReport, path string) error {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".json":
+		data, err := json.MarshalIndent(report, "", "  ")
+
+		if err != nil {
+			return err
+		}
+
+		return ioutil.WriteFile(path, data, 0644)
+	case ".csv":
+		file, err := os.Create(path)
+
+		if err != nil {
+			return err
+		}
+
+		defer file.Close()
+
+		writer := csv.NewWriter(file)
+		writer.Write([]string{"miner", "cohort", "address", "balance", "rewards", "layer_rewards", "fees", "reward_layers", "first_layer", "last_layer", "post_size", "expected_share", "actual_share", "ratio", "flag"})
+
+		for _, m := range report.Miners {
+			writer.Write([]string{
+				m.Miner,
+				m.Cohort,
+				m.Address,
+				strconv.FormatUint(m.Balance, 10),
+				strconv.FormatUint(m.Rewards, 10),
+				strconv.FormatUint(m.LayerRewards, 10),
+				strconv.FormatUint(m.Fees, 10),
+				strconv.Itoa(m.RewardLayers),
+				strconv.FormatUint(uint64(m.FirstLayer), 10),
+				strconv.FormatUint(uint64(m.LastLayer), 10),
+				strconv.FormatUint(m.PostSize, 10),
+				strconv.FormatFloat(m.ExpectedShare, 'f', 4, 64),
+				strconv.FormatFloat(m.ActualShare, 'f', 4, 64),
+				strconv.FormatFloat(m.Ratio, 'f', 2, 64),
+				m.Flag,
+			})
+		}
+
+		writer.Flush()
+
+		return writer.Error()
 	}
 
+	return errors.New("unsupported rewards file format, use .csv or .json")
+}
+
+// Rewards reports the rewards of every managed miner between FromLayer and
+// ToLayer and compares them with the share each miner is expected to earn,
+// i.e., the share of its PoST size in the PoST size of all managed miners.
+func Rewards() error {
 	k8sRestConfig, k8sClient, err := gcp.GetKubernetesClient(config.NetworkName)
 
 	if err != nil {
@@ -28,41 +238,125 @@ func Rewards() error {
 
 	kubernetes := k8s.Kubernetes{Client: k8sClient, RestConfig: k8sRestConfig}
 
+	host := config.Host
+
+	if host == "" {
+		host, err = healthyMinerHost(&kubernetes)
+
+		if err != nil {
+			return err
+		}
+	}
+
 	managedAddresses, err := kubernetes.MinerAccounts()
 
 	if err != nil {
 		return err
 	}
 
-	conn, err := grpc.Dial(config.Host, grpc.WithInsecure())
-	defer conn.Close()
+	miners, err := kubernetes.GetMinersInfo()
 
 	if err != nil {
 		return err
 	}
 
-	c := pb.NewDebugServiceClient(conn)
-	r, err := c.Accounts(context.Background(), &emptypb.Empty{})
+	minerConfigs, err := kubernetes.GetMinerConfigs()
 
 	if err != nil {
 		return err
 	}
 
-	for _, account := range r.AccountWrapper {
-		log.Info.Println("Account: " + hex.EncodeToString(account.AccountId.Address))
-		fmt.Println("Balance: " + strconv.FormatUint(account.StateCurrent.Balance.Value, 10))
-		_, exists := find(managedAddresses, hex.EncodeToString(account.AccountId.Address))
-		fmt.Println("Managed Miner Account: ", strconv.FormatBool(exists)+"\n")
+	cohorts := map[string]string{}
+	commands := map[string]string{}
+
+	for _, miner := range miners {
+		cohorts[miner.Name] = miner.Cohort
+		commands[miner.Name] = miner.Command
 	}
 
-	return nil
-}
+	conn, err := dialMiner(host)
 
-func find(slice []string, val string) (int, bool) {
-	for i, item := range slice {
-		if item == val {
-			return i, true
+	if err != nil {
+		return err
+	}
+
+	defer conn.Close()
+
+	client := pb.NewGlobalStateServiceClient(conn)
+	report := &rewardsReport{FromLayer: uint32(config.FromLayer), ToLayer: uint32(config.ToLayer)}
+
+	for address, miner := range managedAddresses {
+		cohort, ok := cohorts[miner]
+
+		if !ok {
+			cohort = cfg.HonestCohort
+		}
+
+		rewards := minerRewards{Miner: miner, Cohort: cohort, Address: address}
+		rewards.PostSize = postSize(commands[miner], minerConfigs[miner])
+
+		if err = queryMinerRewards(client, report, &rewards); err != nil {
+			return fmt.Errorf("%s: %w", miner, err)
+		}
+
+		report.Total += rewards.Rewards
+		report.Miners = append(report.Miners, rewards)
+	}
+
+	sort.Slice(report.Miners, func(i, j int) bool {
+		return minerIndex(report.Miners[i].Miner) < minerIndex(report.Miners[j].Miner)
+	})
+
+	if !setExpectedShares(report.Miners) {
+		fmt.Println("the PoST size of some miners is unknown, every miner is expected to earn an equal share")
+	}
+
+	cohortMiners := map[string]int{}
+	cohortRewards := map[string]uint64{}
+	cohortBalances := map[string]uint64{}
+
+	for i := range report.Miners {
+		m := &report.Miners[i]
+
+		if report.Total > 0 {
+			m.ActualShare = float64(m.Rewards) / float64(report.Total)
+			m.Ratio = m.ActualShare / m.ExpectedShare
 		}
+
+		if m.Rewards == 0 {
+			m.Flag = "no rewards"
+		} else if m.Ratio < lowRewardsRatio {
+			m.Flag = "low rewards"
+		}
+
+		fmt.Printf("%s cohort=%s address=%s balance=%d rewards=%d layers=%d post=%d expected=%.2f%% actual=%.2f%% ratio=%.2f\n",
+			m.Miner, m.Cohort, m.Address, m.Balance, m.Rewards, m.RewardLayers, m.PostSize, m.ExpectedShare*100, m.ActualShare*100, m.Ratio)
+
+		if m.Flag != "" {
+			log.Error.Println(m.Miner + ": " + m.Flag)
+		}
+
+		cohortMiners[m.Cohort]++
+		cohortRewards[m.Cohort] += m.Rewards
+		cohortBalances[m.Cohort] += m.Balance
 	}
-	return -1, false
+
+	fmt.Println()
+
+	for _, cohort := range []string{cfg.HonestCohort, cfg.AdversarialCohort} {
+		log.Info.Println(strings.Title(cohort) + " Miners:")
+		fmt.Println("Accounts: " + strconv.Itoa(cohortMiners[cohort]))
+		fmt.Println("Total Rewards: " + strconv.FormatUint(cohortRewards[cohort], 10))
+		fmt.Println("Total Balance: " + strconv.FormatUint(cohortBalances[cohort], 10) + "\n")
+	}
+
+	if config.RewardsFile != "" {
+		if err = writeRewardsReport(report, config.RewardsFile); err != nil {
+			return err
+		}
+
+		fmt.Println("rewards report written to " + config.RewardsFile)
+	}
+
+	return nil
 }
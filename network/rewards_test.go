@@ -0,0 +1,55 @@
+package network
+
+import (
+	"testing"
+)
+
+func TestPostSize(t *testing.T) {
+	numUnits := `{"smeshing": {"smeshing-opts": {"smeshing-opts-numunits": 2}}}`
+	postSpace := `{"post": {"post-space": 4294967296}}`
+
+	tests := []struct {
+		name       string
+		command    string
+		configJSON string
+		expected   uint64
+	}{
+		{"space units", "/bin/go-spacemesh --smeshing-start=true", numUnits, 2},
+		{"post space of older versions", "/bin/go-spacemesh", postSpace, 4294967296},
+		{"group flag overrides the config", "/bin/go-spacemesh --smeshing-opts-numunits=8 ; sleep 100000000", numUnits, 8},
+		{"unknown size", "/bin/go-spacemesh", `{"p2p": {}}`, 0},
+		{"invalid config", "/bin/go-spacemesh", "", 0},
+	}
+
+	for _, test := range tests {
+		if size := postSize(test.command, test.configJSON); size != test.expected {
+			t.Errorf("%s: postSize() = %d, expected %d", test.name, size, test.expected)
+		}
+	}
+}
+
+func TestSetExpectedShares(t *testing.T) {
+	miners := []minerRewards{{Miner: "miner-1", PostSize: 2}, {Miner: "miner-2", PostSize: 2}, {Miner: "miner-3", PostSize: 4}}
+
+	if !setExpectedShares(miners) {
+		t.Error("shares aren't weighted by the PoST sizes")
+	}
+
+	for i, expected := range []float64{0.25, 0.25, 0.5} {
+		if miners[i].ExpectedShare != expected {
+			t.Errorf("%s: expected share is %f, expected %f", miners[i].Miner, miners[i].ExpectedShare, expected)
+		}
+	}
+
+	miners = []minerRewards{{Miner: "miner-1", PostSize: 2}, {Miner: "miner-2"}, {Miner: "miner-3", PostSize: 4}, {Miner: "miner-4", PostSize: 4}}
+
+	if setExpectedShares(miners) {
+		t.Error("shares are weighted although a PoST size is unknown")
+	}
+
+	for _, m := range miners {
+		if m.ExpectedShare != 0.25 {
+			t.Errorf("%s: expected share is %f, expected an equal share", m.Miner, m.ExpectedShare)
+		}
+	}
+}
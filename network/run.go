@@ -0,0 +1,325 @@
+package network
+
+import (
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/spacemeshos/go-spacecraft/gcp"
+	k8s "github.com/spacemeshos/go-spacecraft/k8s"
+	"github.com/spacemeshos/go-spacecraft/log"
+	"sigs.k8s.io/yaml"
+)
+
+type scenarioAssertion struct {
+	LayerHashConsistent *int `json:"layerHashConsistent,omitempty"`
+	MinersReady         bool `json:"minersReady,omitempty"`
+}
+
+// scenarioStep is a single action of a scenario. Only one action should be set per step.
+type scenarioStep struct {
+	Name        string                `json:"name,omitempty"`
+	Create      bool                  `json:"create,omitempty"`
+	WaitLayer   *int                  `json:"waitLayer,omitempty"`
+	WaitEpoch   *int                  `json:"waitEpoch,omitempty"`
+	Sleep       string                `json:"sleep,omitempty"`
+	AddMiners   int                   `json:"addMiners,omitempty"`
+	DeleteMiner string                `json:"deleteMiner,omitempty"`
+	Upgrade     string                `json:"upgrade,omitempty"`
+	Chaos       []k8s.ChaosExperiment `json:"chaos,omitempty"`
+	Assert      *scenarioAssertion    `json:"assert,omitempty"`
+}
+
+type scenario struct {
+	Steps []scenarioStep `json:"steps"`
+}
+
+type stepResult struct {
+	Name     string
+	Passed   bool
+	Skipped  bool
+	Err      error
+	Duration time.Duration
+}
+
+func readScenario(path string) (*scenario, error) {
+	buf, err := ioutil.ReadFile(path)
+
+	if err != nil {
+		return nil, err
+	}
+
+	s := &scenario{}
+
+	if err = yaml.Unmarshal(buf, s); err != nil {
+		return nil, err
+	}
+
+	if len(s.Steps) == 0 {
+		return nil, errors.New("scenario has no steps")
+	}
+
+	return s, nil
+}
+
+func (step scenarioStep) description() string {
+	if step.Name != "" {
+		return step.Name
+	}
+
+	switch {
+	case step.Create:
+		return "create network"
+	case step.WaitLayer != nil:
+		return "wait for layer " + strconv.Itoa(*step.WaitLayer)
+	case step.WaitEpoch != nil:
+		return "wait for epoch " + strconv.Itoa(*step.WaitEpoch)
+	case step.Sleep != "":
+		return "sleep " + step.Sleep
+	case step.AddMiners > 0:
+		return "add " + strconv.Itoa(step.AddMiners) + " miners"
+	case step.DeleteMiner != "":
+		return "delete miner-" + step.DeleteMiner
+	case step.Upgrade != "":
+		return "upgrade to " + step.Upgrade
+	case len(step.Chaos) > 0:
+		return "create " + strconv.Itoa(len(step.Chaos)) + " chaos experiments"
+	case step.Assert != nil:
+		return "assert"
+	}
+
+	return "unknown step"
+}
+
+func getKubernetes() (*k8s.Kubernetes, error) {
+	k8sRestConfig, k8sClient, err := gcp.GetKubernetesClient(config.NetworkName)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &k8s.Kubernetes{Client: k8sClient, RestConfig: k8sRestConfig}, nil
+}
+
+// assertLayerHashConsistent checks that all miners report the same hash for the layer
+func assertLayerHashConsistent(kubernetes *k8s.Kubernetes, layer uint32) error {
+	hosts, miners, err := getMinerHosts(kubernetes)
+
+	if err != nil {
+		return err
+	}
+
+	minersByHash := map[string][]string{}
+
+	for _, miner := range miners {
+		layers, err := queryLayers(hosts[miner], layer, layer)
+
+		if err != nil {
+			return fmt.Errorf("%s: %w", miner, err)
+		}
+
+		if len(layers) == 0 {
+			return fmt.Errorf("%s: layer %d not found", miner, layer)
+		}
+
+		hash := hex.EncodeToString(layers[0].Hash)
+		minersByHash[hash] = append(minersByHash[hash], miner)
+	}
+
+	if len(minersByHash) > 1 {
+		hashes := []string{}
+
+		for hash, hashMiners := range minersByHash {
+			hashes = append(hashes, hash+": "+strings.Join(hashMiners, ","))
+		}
+
+		sort.Strings(hashes)
+
+		return fmt.Errorf("miners disagree on the hash of layer %d (%s)", layer, strings.Join(hashes, "; "))
+	}
+
+	return nil
+}
+
+func assertMinersReady(kubernetes *k8s.Kubernetes) error {
+	miners, err := kubernetes.GetMinersInfo()
+
+	if err != nil {
+		return err
+	}
+
+	notReady := []string{}
+
+	for _, miner := range miners {
+		if !miner.Ready {
+			notReady = append(notReady, miner.Name)
+		}
+	}
+
+	if len(notReady) > 0 {
+		sort.Strings(notReady)
+		return errors.New("miners not ready: " + strings.Join(notReady, ","))
+	}
+
+	return nil
+}
+
+func runAssertion(assertion *scenarioAssertion) error {
+	kubernetes, err := getKubernetes()
+
+	if err != nil {
+		return err
+	}
+
+	if assertion.MinersReady {
+		if err = assertMinersReady(kubernetes); err != nil {
+			return err
+		}
+	}
+
+	if assertion.LayerHashConsistent != nil {
+		if err = assertLayerHashConsistent(kubernetes, uint32(*assertion.LayerHashConsistent)); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func runStep(step scenarioStep) error {
+	switch {
+	case step.Create:
+		return Create()
+	case step.WaitLayer != nil:
+		kubernetes, err := getKubernetes()
+
+		if err != nil {
+			return err
+		}
+
+		return waitForLayer(kubernetes, uint32(*step.WaitLayer))
+	case step.WaitEpoch != nil:
+		kubernetes, err := getKubernetes()
+
+		if err != nil {
+			return err
+		}
+
+		return waitForEpoch(kubernetes, uint64(*step.WaitEpoch))
+	case step.Sleep != "":
+		duration, err := time.ParseDuration(step.Sleep)
+
+		if err != nil {
+			return err
+		}
+
+		time.Sleep(duration)
+		return nil
+	case step.AddMiners > 0:
+		config.MinerNumber = ""
+
+		for i := 0; i < step.AddMiners; i++ {
+			if err := AddMiner(); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	case step.DeleteMiner != "":
+		config.MinerNumber = strings.TrimPrefix(step.DeleteMiner, "miner-")
+		defer func() { config.MinerNumber = "" }()
+
+		return DeleteMiner()
+	case step.Upgrade != "":
+		config.GoSmImage = step.Upgrade
+
+		return Upgrade()
+	case len(step.Chaos) > 0:
+		kubernetes, err := getKubernetes()
+
+		if err != nil {
+			return err
+		}
+
+		return createChaosExperiments(kubernetes, step.Chaos)
+	case step.Assert != nil:
+		return runAssertion(step.Assert)
+	}
+
+	return errors.New("step has no action")
+}
+
+// Run executes the steps of the scenario file in order. A failed assertion
+// is reported but doesn't stop the scenario, any other failure skips the
+// remaining steps.
+func Run() error {
+	if config.ScenarioFile == "" {
+		return errors.New("please provide the scenario file")
+	}
+
+	s, err := readScenario(config.ScenarioFile)
+
+	if err != nil {
+		return err
+	}
+
+	results := []stepResult{}
+	aborted := false
+
+	for i, step := range s.Steps {
+		result := stepResult{Name: step.description()}
+
+		if aborted {
+			result.Skipped = true
+			results = append(results, result)
+			continue
+		}
+
+		log.Info.Println("step " + strconv.Itoa(i+1) + "/" + strconv.Itoa(len(s.Steps)) + ": " + result.Name)
+
+		start := time.Now()
+		result.Err = runStep(step)
+		result.Duration = time.Since(start).Round(time.Second)
+		result.Passed = result.Err == nil
+
+		if result.Err != nil {
+			log.Error.Println(result.Err)
+
+			if step.Assert == nil {
+				aborted = true
+			}
+		}
+
+		results = append(results, result)
+	}
+
+	fmt.Println()
+	log.Info.Println("Scenario report:")
+
+	failed := 0
+
+	for i, result := range results {
+		line := strconv.Itoa(i+1) + ". " + result.Name
+
+		switch {
+		case result.Skipped:
+			fmt.Println(line + ": SKIPPED")
+		case result.Passed:
+			log.Success.Println(line + ": PASS (" + result.Duration.String() + ")")
+		default:
+			failed++
+			log.Error.Println(line + ": FAIL (" + result.Duration.String() + "): " + result.Err.Error())
+		}
+	}
+
+	if failed > 0 || aborted {
+		return errors.New("scenario failed: " + strconv.Itoa(failed) + " of " + strconv.Itoa(len(results)) + " steps failed")
+	}
+
+	return nil
+}
@@ -0,0 +1,63 @@
+package network
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	cfg "github.com/spacemeshos/go-spacecraft/config"
+	"github.com/spacemeshos/go-spacecraft/gcp"
+	k8s "github.com/spacemeshos/go-spacecraft/k8s"
+	"github.com/spacemeshos/go-spacecraft/log"
+)
+
+func Status() error {
+	k8sRestConfig, k8sClient, err := gcp.GetKubernetesClient(config.NetworkName)
+
+	if err != nil {
+		return err
+	}
+
+	kubernetes := k8s.Kubernetes{Client: k8sClient, RestConfig: k8sRestConfig}
+
+	miners, err := kubernetes.GetMinersInfo()
+
+	if err != nil {
+		return err
+	}
+
+	sort.Slice(miners, func(i, j int) bool {
+		return minerIndex(miners[i].Name) < minerIndex(miners[j].Name)
+	})
+
+	cohortMiners := map[string]int{}
+	cohortReady := map[string]int{}
+
+	log.Info.Println("Miners:")
+
+	for _, miner := range miners {
+		fmt.Printf("%s group=%s cohort=%s ready=%t image=%s\n", miner.Name, miner.Group, miner.Cohort, miner.Ready, miner.Image)
+
+		cohortMiners[miner.Cohort]++
+
+		if miner.Ready {
+			cohortReady[miner.Cohort]++
+		}
+	}
+
+	fmt.Println()
+
+	for _, cohort := range []string{cfg.HonestCohort, cfg.AdversarialCohort} {
+		log.Info.Println(strings.Title(cohort) + " Miners:")
+		fmt.Println("Ready: " + strconv.Itoa(cohortReady[cohort]) + "/" + strconv.Itoa(cohortMiners[cohort]))
+	}
+
+	return nil
+}
+
+func minerIndex(name string) int {
+	index, _ := strconv.Atoi(strings.TrimPrefix(name, "miner-"))
+
+	return index
+}
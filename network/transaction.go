@@ -0,0 +1,53 @@
+package network
+
+import (
+	"crypto/ed25519"
+	"crypto/sha512"
+	"encoding/binary"
+
+	"filippo.io/edwards25519"
+)
+
+const addressLength = 20
+
+// addressOf returns the spacemesh address of a public key, i.e., its last 20 bytes
+func addressOf(publicKey ed25519.PublicKey) []byte {
+	return publicKey[len(publicKey)-addressLength:]
+}
+
+// sign2 signs the message the way go-spacemesh does. It is ed25519 except that
+// the public key is not part of the challenge hash, which lets nodes extract the
+// public key of the sender from the signature.
+func sign2(privateKey ed25519.PrivateKey, message []byte) []byte {
+	digest := sha512.Sum512(privateKey.Seed())
+	secret, _ := edwards25519.NewScalar().SetBytesWithClamping(digest[:32])
+
+	h := sha512.New()
+	h.Write(digest[32:])
+	h.Write(message)
+	nonce, _ := edwards25519.NewScalar().SetUniformBytes(h.Sum(nil))
+
+	encodedR := new(edwards25519.Point).ScalarBaseMult(nonce).Bytes()
+
+	h.Reset()
+	h.Write(encodedR)
+	h.Write(message)
+	challenge, _ := edwards25519.NewScalar().SetUniformBytes(h.Sum(nil))
+
+	s := edwards25519.NewScalar().MultiplyAdd(challenge, secret, nonce)
+
+	return append(encodedR, s.Bytes()...)
+}
+
+// signedTransfer returns the XDR encoding of a signed simple coin transfer
+// which is accepted by the SubmitTransaction RPC
+func signedTransfer(privateKey ed25519.PrivateKey, nonce uint64, recipient []byte, gasLimit uint64, fee uint64, amount uint64) []byte {
+	inner := make([]byte, 8+addressLength+8+8+8)
+	binary.BigEndian.PutUint64(inner[0:], nonce)
+	copy(inner[8:], recipient)
+	binary.BigEndian.PutUint64(inner[8+addressLength:], gasLimit)
+	binary.BigEndian.PutUint64(inner[16+addressLength:], fee)
+	binary.BigEndian.PutUint64(inner[24+addressLength:], amount)
+
+	return append(inner, sign2(privateKey, inner)...)
+}
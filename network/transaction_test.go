@@ -0,0 +1,151 @@
+package network
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"crypto/sha512"
+	"encoding/binary"
+	"testing"
+
+	"filippo.io/edwards25519"
+)
+
+// verify2 checks a signature of sign2, i.e., [S]B = R + [k]A where the
+// challenge k is the hash of R and the message only
+func verify2(publicKey ed25519.PublicKey, message []byte, signature []byte) bool {
+	if len(signature) != ed25519.SignatureSize {
+		return false
+	}
+
+	A, err := new(edwards25519.Point).SetBytes(publicKey)
+
+	if err != nil {
+		return false
+	}
+
+	R, err := new(edwards25519.Point).SetBytes(signature[:32])
+
+	if err != nil {
+		return false
+	}
+
+	S, err := edwards25519.NewScalar().SetCanonicalBytes(signature[32:])
+
+	if err != nil {
+		return false
+	}
+
+	h := sha512.New()
+	h.Write(signature[:32])
+	h.Write(message)
+	k, _ := edwards25519.NewScalar().SetUniformBytes(h.Sum(nil))
+
+	left := new(edwards25519.Point).ScalarBaseMult(S)
+	right := new(edwards25519.Point).Add(R, new(edwards25519.Point).ScalarMult(k, A))
+
+	return left.Equal(right) == 1
+}
+
+func testKey(seed byte) ed25519.PrivateKey {
+	return ed25519.NewKeyFromSeed(bytes.Repeat([]byte{seed}, ed25519.SeedSize))
+}
+
+func TestSign2(t *testing.T) {
+	tests := []struct {
+		name    string
+		key     ed25519.PrivateKey
+		message []byte
+	}{
+		{"empty message", testKey(1), []byte{}},
+		{"short message", testKey(2), []byte("spacemesh")},
+		{"transfer sized message", testKey(3), bytes.Repeat([]byte{0xab}, 8+addressLength+8+8+8)},
+	}
+
+	for _, test := range tests {
+		publicKey := test.key.Public().(ed25519.PublicKey)
+		signature := sign2(test.key, test.message)
+
+		if !verify2(publicKey, test.message, signature) {
+			t.Errorf("%s: signature doesn't verify", test.name)
+		}
+
+		if !bytes.Equal(signature, sign2(test.key, test.message)) {
+			t.Errorf("%s: signature isn't deterministic", test.name)
+		}
+
+		// the public key isn't part of the challenge, so it's no ed25519 signature
+		if ed25519.Verify(publicKey, test.message, signature) {
+			t.Errorf("%s: signature is a standard ed25519 signature", test.name)
+		}
+
+		if verify2(testKey(9).Public().(ed25519.PublicKey), test.message, signature) {
+			t.Errorf("%s: signature verifies with another public key", test.name)
+		}
+
+		if verify2(publicKey, append(test.message, 0), signature) {
+			t.Errorf("%s: signature verifies for another message", test.name)
+		}
+	}
+}
+
+func TestSignedTransfer(t *testing.T) {
+	key := testKey(1)
+	recipient := addressOf(testKey(2).Public().(ed25519.PublicKey))
+
+	tests := []struct {
+		nonce    uint64
+		gasLimit uint64
+		fee      uint64
+		amount   uint64
+	}{
+		{0, 100, 1, 10},
+		{7, 1, 0, 0},
+		{1<<64 - 1, 1<<64 - 1, 1<<64 - 1, 1<<64 - 1},
+	}
+
+	for _, test := range tests {
+		transfer := signedTransfer(key, test.nonce, recipient, test.gasLimit, test.fee, test.amount)
+
+		innerLength := 8 + addressLength + 8 + 8 + 8
+
+		if len(transfer) != innerLength+ed25519.SignatureSize {
+			t.Fatalf("transfer has %d bytes, expected %d", len(transfer), innerLength+ed25519.SignatureSize)
+		}
+
+		inner := transfer[:innerLength]
+
+		fields := []struct {
+			name     string
+			value    uint64
+			expected uint64
+		}{
+			{"nonce", binary.BigEndian.Uint64(inner[0:]), test.nonce},
+			{"gas limit", binary.BigEndian.Uint64(inner[8+addressLength:]), test.gasLimit},
+			{"fee", binary.BigEndian.Uint64(inner[16+addressLength:]), test.fee},
+			{"amount", binary.BigEndian.Uint64(inner[24+addressLength:]), test.amount},
+		}
+
+		for _, field := range fields {
+			if field.value != field.expected {
+				t.Errorf("%s is %d, expected %d", field.name, field.value, field.expected)
+			}
+		}
+
+		if !bytes.Equal(inner[8:8+addressLength], recipient) {
+			t.Errorf("recipient is %x, expected %x", inner[8:8+addressLength], recipient)
+		}
+
+		if !verify2(key.Public().(ed25519.PublicKey), inner, transfer[innerLength:]) {
+			t.Error("signature of the transfer doesn't verify")
+		}
+	}
+}
+
+func TestAddressOf(t *testing.T) {
+	publicKey := testKey(1).Public().(ed25519.PublicKey)
+	address := addressOf(publicKey)
+
+	if len(address) != addressLength || !bytes.Equal(address, publicKey[ed25519.PublicKeySize-addressLength:]) {
+		t.Errorf("address %x isn't the last %d bytes of %x", address, addressLength, publicKey)
+	}
+}
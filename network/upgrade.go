@@ -1,6 +1,7 @@
 package network
 
 import (
+	"fmt"
 	"time"
 
 	"github.com/spacemeshos/go-spacecraft/gcp"
@@ -22,10 +23,56 @@ func Upgrade() error {
 		return err
 	}
 
+	minerGroups, err := kubernetes.GetMinerGroups()
+
+	if err != nil {
+		return err
+	}
+
 	for _, miner := range miners {
-		kubernetes.UpdateImageOfMiners(miner)
+		if config.MinerGroup != "" && minerGroups[miner] != config.MinerGroup {
+			continue
+		}
+
+		image := config.GoSmImage
+
+		if group, ok := config.GetMinerGroup(minerGroups[miner]); ok {
+			image = group.Image
+		}
+
+		// stop the rolling upgrade at the first miner that doesn't come up
+		if err = kubernetes.UpdateImageOfMiners(miner, image); err != nil {
+			return err
+		}
+
 		time.Sleep(time.Duration(config.RestartWaitTime) * time.Minute)
 	}
 
+	// the versions of a group, e.g., the adversarial miners or a canary,
+	// aren't the versions of the network
+	if config.MinerGroup != "" {
+		return nil
+	}
+
+	return updateNodeVersionInDiscovery()
+}
+
+// updateNodeVersionInDiscovery updates the node versions of the upgraded
+// network if it's in the discovery service
+func updateNodeVersionInDiscovery() error {
+	networks, err := k8s.ListDiscovery()
+
+	if err != nil {
+		return err
+	}
+
+	for _, network := range networks {
+		if network.NetName == config.NetworkName {
+			fmt.Println("updating node version in discovery service")
+
+			return k8s.UpdateNodeVersionInDiscovery(config.GoSmImage)
+		}
+	}
+
 	return nil
 }
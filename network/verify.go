@@ -0,0 +1,230 @@
+package network
+
+import (
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+
+	pb "github.com/spacemeshos/api/release/go/spacemesh/v1"
+	"github.com/spacemeshos/go-spacecraft/gcp"
+	k8s "github.com/spacemeshos/go-spacecraft/k8s"
+	"github.com/spacemeshos/go-spacecraft/log"
+)
+
+// layerView is what a miner reports about a layer
+type layerView struct {
+	Hash      string
+	Blocks    string
+	StateRoot string
+}
+
+func viewOf(layer *pb.Layer) layerView {
+	blocks := []string{}
+
+	for _, block := range layer.Blocks {
+		blocks = append(blocks, hex.EncodeToString(block.Id))
+	}
+
+	sort.Strings(blocks)
+
+	return layerView{
+		Hash:      hex.EncodeToString(layer.Hash),
+		Blocks:    strings.Join(blocks, ","),
+		StateRoot: hex.EncodeToString(layer.RootStateHash),
+	}
+}
+
+// differences lists the parts of the views which don't match
+func (v layerView) differences(other layerView) []string {
+	diff := []string{}
+
+	if v.Hash != other.Hash {
+		diff = append(diff, "layer hash")
+	}
+
+	if v.Blocks != other.Blocks {
+		diff = append(diff, "blocks")
+	}
+
+	if v.StateRoot != other.StateRoot {
+		diff = append(diff, "state root")
+	}
+
+	return diff
+}
+
+// majorityView returns the view reported by most miners. Ties are broken in
+// favour of the view of the miner with the lowest number.
+func majorityView(views map[string]layerView, miners []string) (layerView, bool) {
+	counts := map[layerView]int{}
+
+	for _, miner := range miners {
+		if view, ok := views[miner]; ok {
+			counts[view]++
+		}
+	}
+
+	best := layerView{}
+	found := false
+
+	for _, miner := range miners {
+		if view, ok := views[miner]; ok && (!found || counts[view] > counts[best]) {
+			best = view
+			found = true
+		}
+	}
+
+	return best, found
+}
+
+func Verify() error {
+	k8sRestConfig, k8sClient, err := gcp.GetKubernetesClient(config.NetworkName)
+
+	if err != nil {
+		return err
+	}
+
+	kubernetes := k8s.Kubernetes{Client: k8sClient, RestConfig: k8sRestConfig}
+
+	hosts, miners, err := getMinerHosts(&kubernetes)
+
+	if err != nil {
+		return err
+	}
+
+	if len(miners) == 0 {
+		return errors.New("no miners found")
+	}
+
+	unreachable := map[string]error{}
+	reachable := []string{}
+	notProgressing := []string{}
+	toLayer := uint32(config.ToLayer)
+
+	if config.ToLayer == 0 {
+		toLayer = math.MaxUint32
+	}
+
+	for _, miner := range miners {
+		current, err := currentLayer(hosts[miner])
+
+		if err != nil {
+			unreachable[miner] = err
+			continue
+		}
+
+		// a miner at layer 0 hasn't finished any layer that could be compared
+		if current == 0 {
+			notProgressing = append(notProgressing, miner)
+			continue
+		}
+
+		reachable = append(reachable, miner)
+
+		// by default only compare layers which all miners have finished
+		if config.ToLayer == 0 && current <= toLayer {
+			toLayer = current - 1
+		}
+	}
+
+	for _, miner := range notProgressing {
+		log.Error.Println(miner + " is still at layer 0")
+	}
+
+	if len(reachable) == 0 {
+		if len(notProgressing) > 0 {
+			return errors.New("no miners progressed past layer 0")
+		}
+
+		return errors.New("no miners reachable")
+	}
+
+	fromLayer := uint32(config.FromLayer)
+
+	if toLayer < fromLayer || toLayer == math.MaxUint32 {
+		return fmt.Errorf("invalid layer range %d-%d", fromLayer, toLayer)
+	}
+
+	fmt.Printf("comparing layers %d-%d of %d miners\n", fromLayer, toLayer, len(reachable))
+
+	// views by layer and miner
+	views := map[uint32]map[string]layerView{}
+
+	for _, miner := range reachable {
+		layers, err := queryLayers(hosts[miner], fromLayer, toLayer)
+
+		if err != nil {
+			unreachable[miner] = err
+			continue
+		}
+
+		for _, layer := range layers {
+			if views[layer.Number.Number] == nil {
+				views[layer.Number.Number] = map[string]layerView{}
+			}
+
+			views[layer.Number.Number][miner] = viewOf(layer)
+		}
+	}
+
+	diverged := map[string]string{}
+
+	for layer := fromLayer; layer <= toLayer; layer++ {
+		reference, ok := majorityView(views[layer], reachable)
+
+		if !ok {
+			continue
+		}
+
+		for _, miner := range reachable {
+			if _, ok := diverged[miner]; ok {
+				continue
+			}
+
+			if _, ok := unreachable[miner]; ok {
+				continue
+			}
+
+			view, ok := views[layer][miner]
+
+			if !ok {
+				diverged[miner] = "layer " + strconv.Itoa(int(layer)) + " missing"
+				continue
+			}
+
+			if diff := view.differences(reference); len(diff) > 0 {
+				diverged[miner] = "layer " + strconv.Itoa(int(layer)) + " (" + strings.Join(diff, ", ") + ")"
+			}
+		}
+	}
+
+	for _, miner := range miners {
+		if err, ok := unreachable[miner]; ok {
+			log.Error.Println(miner + " unreachable: " + err.Error())
+		}
+	}
+
+	for _, miner := range miners {
+		if layer, ok := diverged[miner]; ok {
+			log.Error.Println(miner + " diverged at " + layer)
+		}
+	}
+
+	if len(diverged) > 0 {
+		return errors.New(strconv.Itoa(len(diverged)) + " of " + strconv.Itoa(len(miners)) + " miners diverged")
+	}
+
+	if len(unreachable) > 0 {
+		return errors.New(strconv.Itoa(len(unreachable)) + " of " + strconv.Itoa(len(miners)) + " miners unreachable")
+	}
+
+	if len(notProgressing) > 0 {
+		return errors.New(strconv.Itoa(len(notProgressing)) + " of " + strconv.Itoa(len(miners)) + " miners are still at layer 0")
+	}
+
+	return nil
+}
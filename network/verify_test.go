@@ -0,0 +1,83 @@
+package network
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestMajorityView(t *testing.T) {
+	a := layerView{Hash: "aa", Blocks: "01,02", StateRoot: "ff"}
+	b := layerView{Hash: "bb", Blocks: "01", StateRoot: "ff"}
+	c := layerView{Hash: "cc", Blocks: "", StateRoot: "ee"}
+
+	tests := []struct {
+		name     string
+		views    map[string]layerView
+		miners   []string
+		expected layerView
+		found    bool
+	}{
+		{
+			name:   "no views",
+			views:  map[string]layerView{},
+			miners: []string{"miner-1", "miner-2"},
+		},
+		{
+			name:     "all agree",
+			views:    map[string]layerView{"miner-1": a, "miner-2": a, "miner-3": a},
+			miners:   []string{"miner-1", "miner-2", "miner-3"},
+			expected: a, found: true,
+		},
+		{
+			name:     "majority differs from the first miner",
+			views:    map[string]layerView{"miner-1": b, "miner-2": a, "miner-3": a},
+			miners:   []string{"miner-1", "miner-2", "miner-3"},
+			expected: a, found: true,
+		},
+		{
+			name:     "tie goes to the lowest miner",
+			views:    map[string]layerView{"miner-1": b, "miner-2": a, "miner-3": a, "miner-4": b},
+			miners:   []string{"miner-1", "miner-2", "miner-3", "miner-4"},
+			expected: b, found: true,
+		},
+		{
+			name:     "plurality of three views",
+			views:    map[string]layerView{"miner-1": c, "miner-2": a, "miner-3": b, "miner-4": a},
+			miners:   []string{"miner-1", "miner-2", "miner-3", "miner-4"},
+			expected: a, found: true,
+		},
+		{
+			name:     "miners without a view are skipped",
+			views:    map[string]layerView{"miner-2": c},
+			miners:   []string{"miner-1", "miner-2", "miner-3"},
+			expected: c, found: true,
+		},
+	}
+
+	for _, test := range tests {
+		view, found := majorityView(test.views, test.miners)
+
+		if found != test.found || !reflect.DeepEqual(view, test.expected) {
+			t.Errorf("%s: majorityView() = %v, %v, expected %v, %v", test.name, view, found, test.expected, test.found)
+		}
+	}
+}
+
+func TestLayerViewDifferences(t *testing.T) {
+	view := layerView{Hash: "aa", Blocks: "01,02", StateRoot: "ff"}
+
+	tests := []struct {
+		other    layerView
+		expected []string
+	}{
+		{view, []string{}},
+		{layerView{Hash: "bb", Blocks: "01,02", StateRoot: "ff"}, []string{"layer hash"}},
+		{layerView{Hash: "aa", Blocks: "01", StateRoot: "ee"}, []string{"blocks", "state root"}},
+	}
+
+	for _, test := range tests {
+		if diff := view.differences(test.other); !reflect.DeepEqual(diff, test.expected) {
+			t.Errorf("differences(%v) = %v, expected %v", test.other, diff, test.expected)
+		}
+	}
+}
@@ -0,0 +1,20 @@
+package network
+
+import (
+	"github.com/spacemeshos/go-spacecraft/gcp"
+	k8s "github.com/spacemeshos/go-spacecraft/k8s"
+)
+
+// UpdateWatch updates the targets of spacemesh-watch, and its image and
+// flags if updateImage and updateFlags are set
+func UpdateWatch(updateImage bool, updateFlags bool) error {
+	k8sRestConfig, k8sClient, err := gcp.GetKubernetesClient(config.NetworkName)
+
+	if err != nil {
+		return err
+	}
+
+	kubernetes := k8s.Kubernetes{Client: k8sClient, RestConfig: k8sRestConfig}
+
+	return kubernetes.UpdateSpacemeshWatch(updateImage, updateFlags)
+}
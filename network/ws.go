@@ -1,8 +1,12 @@
 package network
 
 import (
+	"fmt"
+	"time"
+
 	"github.com/spacemeshos/go-spacecraft/gcp"
 	k8s "github.com/spacemeshos/go-spacecraft/k8s"
+	"github.com/spacemeshos/go-spacecraft/log"
 )
 
 func DeployWS() error {
@@ -14,20 +18,52 @@ func DeployWS() error {
 
 	kubernetes := k8s.Kubernetes{Client: k8sClient, RestConfig: k8sRestConfig}
 
-	if err = kubernetes.DeployFilebeatForWS(); err != nil {
+	if err = kubernetes.LoadDNSSettings(); err != nil {
 		return err
 	}
 
-	err = kubernetes.DeployWS()
+	// networks created before the DNS settings were saved use the options
+	if err = kubernetes.SaveDNSSettings(); err != nil {
+		return err
+	}
+
+	logBackend, err := kubernetes.GetLogBackend()
 
 	if err != nil {
 		return err
 	}
 
-	if err = kubernetes.SetupLogDeletionPolicyForWS(); err != nil {
+	if k8s.ManagedTLS() {
+		if err = kubernetes.DeployCertManager(); err != nil {
+			return err
+		}
+	}
+
+	// promtail collects the logs of all namespaces
+	if logBackend == "elk" {
+		if err = kubernetes.DeployFilebeatForWS(); err != nil {
+			return err
+		}
+	}
+
+	err = kubernetes.DeployWS()
+
+	if err != nil {
 		return err
 	}
 
+	if logBackend == "elk" {
+		if err = kubernetes.SetupLogDeletionPolicyForWS(); err != nil {
+			return err
+		}
+	}
+
+	if config.DeployFaucet {
+		if err = kubernetes.DeployFaucet(); err != nil {
+			return err
+		}
+	}
+
 	err = kubernetes.AddToDiscovery()
 
 	if err != nil {
@@ -36,3 +72,105 @@ func DeployWS() error {
 
 	return nil
 }
+
+// UpgradeWS upgrades the web services charts and updates the explorer and
+// dash versions of the network in the discovery service
+func UpgradeWS() error {
+	k8sRestConfig, k8sClient, err := gcp.GetKubernetesClient(config.NetworkName)
+
+	if err != nil {
+		return err
+	}
+
+	kubernetes := k8s.Kubernetes{Client: k8sClient, RestConfig: k8sRestConfig}
+
+	if err = kubernetes.LoadDNSSettings(); err != nil {
+		return err
+	}
+
+	err = kubernetes.UpgradeWS()
+
+	if err != nil {
+		return err
+	}
+
+	// the other fields of the network may have been changed by hand
+	return k8s.UpdateNetworkInDiscovery(config.NetworkName, func(network *k8s.Network) {
+		network.ExplorerVersion = config.ExplorerVersion
+		network.DashVersion = config.DashboardVersion
+	})
+}
+
+func StatusWS() error {
+	k8sRestConfig, k8sClient, err := gcp.GetKubernetesClient(config.NetworkName)
+
+	if err != nil {
+		return err
+	}
+
+	kubernetes := k8s.Kubernetes{Client: k8sClient, RestConfig: k8sRestConfig}
+
+	status, err := kubernetes.GetWSStatus()
+
+	if err != nil {
+		return err
+	}
+
+	if len(status.Releases) == 0 && len(status.Pods) == 0 {
+		log.Error.Println("Web services are not deployed")
+		return nil
+	}
+
+	log.Info.Println("Releases:")
+
+	for _, release := range status.Releases {
+		fmt.Printf("%s chart=%s app=%s revision=%d status=%s updated=%s\n", release.Name, release.Chart, release.AppVersion, release.Revision, release.Status, release.Updated.UTC().Format(time.RFC3339))
+	}
+
+	fmt.Println()
+	log.Info.Println("Pods:")
+
+	for _, pod := range status.Pods {
+		fmt.Printf("%s ready=%t phase=%s restarts=%d\n", pod.Name, pod.Ready, pod.Phase, pod.Restarts)
+	}
+
+	fmt.Println()
+
+	if status.IngressIP == "" {
+		log.Error.Println("Ingress IP: pending")
+	} else {
+		log.Info.Println("Ingress IP: " + status.IngressIP)
+	}
+
+	return nil
+}
+
+// DeleteWS deletes the web services, their DNS records and the network from
+// the discovery service, the miners keep running
+func DeleteWS() error {
+	k8sRestConfig, k8sClient, err := gcp.GetKubernetesClient(config.NetworkName)
+
+	if err != nil {
+		return err
+	}
+
+	kubernetes := k8s.Kubernetes{Client: k8sClient, RestConfig: k8sRestConfig}
+
+	if err = kubernetes.LoadDNSSettings(); err != nil {
+		return err
+	}
+
+	err = kubernetes.RemoveFromDiscovery()
+
+	if err != nil {
+		return err
+	}
+
+	err = kubernetes.DeleteWS()
+
+	if err != nil {
+		return err
+	}
+
+	return kubernetes.DeleteDNSRecords("ws", "faucet")
+}
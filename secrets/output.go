@@ -0,0 +1,76 @@
+package secrets
+
+import (
+	"bufio"
+	"io"
+	"os"
+)
+
+// RedactOutput replaces os.Stdout and os.Stderr with pipes which are copied
+// to the original files with the secrets redacted, so that everything
+// printed with fmt, by cobra or by a subprocess is redacted as well. The
+// returned function restores os.Stdout and os.Stderr after the output
+// written so far is copied.
+func RedactOutput() (func(), error) {
+	restoreStdout, err := redactFile(&os.Stdout)
+
+	if err != nil {
+		return nil, err
+	}
+
+	restoreStderr, err := redactFile(&os.Stderr)
+
+	if err != nil {
+		restoreStdout()
+		return nil, err
+	}
+
+	return func() {
+		restoreStderr()
+		restoreStdout()
+	}, nil
+}
+
+func redactFile(file **os.File) (func(), error) {
+	original := *file
+	r, w, err := os.Pipe()
+
+	if err != nil {
+		return nil, err
+	}
+
+	done := make(chan struct{})
+
+	go func() {
+		defer close(done)
+		defer r.Close()
+
+		copyLines(Writer(original), r)
+	}()
+
+	*file = w
+
+	return func() {
+		*file = original
+		w.Close()
+		<-done
+	}, nil
+}
+
+// copyLines copies whole lines, so that a secret is never split across two
+// writes and missed by the redaction
+func copyLines(w io.Writer, r io.Reader) {
+	reader := bufio.NewReader(r)
+
+	for {
+		line, err := reader.ReadString('\n')
+
+		if line != "" {
+			io.WriteString(w, line)
+		}
+
+		if err != nil {
+			return
+		}
+	}
+}
@@ -0,0 +1,163 @@
+package secrets
+
+import (
+	"errors"
+	"io"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"strings"
+	"sync"
+)
+
+// minRedactLength avoids redacting short values which appear in unrelated
+// output, e.g., a password "a"
+const minRedactLength = 4
+
+const redacted = "[REDACTED]"
+
+var (
+	mu     sync.RWMutex
+	values = map[string]bool{}
+)
+
+// Resolve returns the value of a secret reference and registers it for
+// redaction. References are:
+//
+//	env:NAME             environment variable NAME
+//	file:PATH            content of the file at PATH
+//	sops:PATH#KEY        KEY (e.g., slack.token) of the SOPS file at PATH
+//	vault:PATH#FIELD     FIELD of the Vault secret at PATH, e.g., secret/data/spacecraft
+//
+// Any other value is returned as it is.
+func Resolve(ref string) (string, error) {
+	value, err := resolve(ref)
+
+	if err != nil {
+		return "", err
+	}
+
+	Add(value)
+
+	return value, nil
+}
+
+func resolve(ref string) (string, error) {
+	scheme := strings.SplitN(ref, ":", 2)
+
+	if len(scheme) != 2 {
+		return ref, nil
+	}
+
+	switch scheme[0] {
+	case "env":
+		value, ok := os.LookupEnv(scheme[1])
+
+		if !ok {
+			return "", errors.New("secret environment variable " + scheme[1] + " is not set")
+		}
+
+		return value, nil
+	case "file":
+		data, err := ioutil.ReadFile(scheme[1])
+
+		if err != nil {
+			return "", err
+		}
+
+		return strings.TrimRight(string(data), "\r\n"), nil
+	case "sops":
+		path, key := splitKey(scheme[1])
+
+		return sopsDecrypt(path, key)
+	case "vault":
+		path, field := splitKey(scheme[1])
+
+		if field == "" {
+			return "", errors.New("vault secret reference " + ref + " has no #field")
+		}
+
+		return vaultRead(path, field)
+	}
+
+	return ref, nil
+}
+
+func splitKey(ref string) (string, string) {
+	parts := strings.SplitN(ref, "#", 2)
+
+	if len(parts) == 1 {
+		return parts[0], ""
+	}
+
+	return parts[0], parts[1]
+}
+
+// sopsDecrypt decrypts the file with the sops CLI, which reads the age or
+// cloud KMS keys from its usual environment, e.g., SOPS_AGE_KEY_FILE
+func sopsDecrypt(path string, key string) (string, error) {
+	args := []string{"--decrypt"}
+
+	if key != "" {
+		extract := ""
+
+		for _, part := range strings.Split(key, ".") {
+			extract += `["` + part + `"]`
+		}
+
+		args = append(args, "--extract", extract)
+	}
+
+	cmd := exec.Command("sops", append(args, path)...)
+	cmd.Stderr = os.Stderr
+
+	out, err := cmd.Output()
+
+	if err != nil {
+		return "", errors.New("cannot decrypt " + path + " with sops: " + err.Error())
+	}
+
+	return strings.TrimRight(string(out), "\r\n"), nil
+}
+
+// Add registers a value to be redacted
+func Add(value string) {
+	if len(value) < minRedactLength {
+		return
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+
+	values[value] = true
+}
+
+// Redact replaces the registered secrets in s
+func Redact(s string) string {
+	mu.RLock()
+	defer mu.RUnlock()
+
+	for value := range values {
+		s = strings.ReplaceAll(s, value, redacted)
+	}
+
+	return s
+}
+
+type redactWriter struct {
+	w io.Writer
+}
+
+func (r redactWriter) Write(p []byte) (int, error) {
+	if _, err := io.WriteString(r.w, Redact(string(p))); err != nil {
+		return 0, err
+	}
+
+	return len(p), nil
+}
+
+// Writer returns a writer which redacts the registered secrets before
+// writing to w
+func Writer(w io.Writer) io.Writer {
+	return redactWriter{w: w}
+}
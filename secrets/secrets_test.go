@@ -0,0 +1,141 @@
+package secrets
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func resetValues() {
+	mu.Lock()
+	defer mu.Unlock()
+
+	values = map[string]bool{}
+}
+
+func TestResolve(t *testing.T) {
+	os.Setenv("SPACECRAFT_TEST_SECRET", "from-env")
+	defer os.Unsetenv("SPACECRAFT_TEST_SECRET")
+
+	file := filepath.Join(t.TempDir(), "secret")
+
+	if err := ioutil.WriteFile(file, []byte("from-file\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		ref      string
+		expected string
+		valid    bool
+	}{
+		{ref: "plain-value", expected: "plain-value", valid: true},
+		{ref: "", expected: "", valid: true},
+		{ref: "https://hooks.slack.com/services/x", expected: "https://hooks.slack.com/services/x", valid: true},
+		{ref: "env:SPACECRAFT_TEST_SECRET", expected: "from-env", valid: true},
+		{ref: "env:SPACECRAFT_TEST_MISSING"},
+		{ref: "file:" + file, expected: "from-file", valid: true},
+		{ref: "file:" + file + ".missing"},
+		{ref: "vault:secret/data/spacecraft"},
+	}
+
+	for _, test := range tests {
+		value, err := resolve(test.ref)
+
+		if !test.valid {
+			if err == nil {
+				t.Errorf("%q: resolved to %q", test.ref, value)
+			}
+
+			continue
+		}
+
+		if err != nil {
+			t.Errorf("%q: %v", test.ref, err)
+			continue
+		}
+
+		if value != test.expected {
+			t.Errorf("%q: resolved to %q, expected %q", test.ref, value, test.expected)
+		}
+	}
+}
+
+func TestRedact(t *testing.T) {
+	defer resetValues()
+
+	Add("s3cr3t-token")
+	Add("hunter2")
+	Add("abc")
+
+	tests := []struct {
+		s        string
+		expected string
+	}{
+		{"no secrets", "no secrets"},
+		{"token=s3cr3t-token", "token=[REDACTED]"},
+		{"hunter2 and s3cr3t-token and hunter2", "[REDACTED] and [REDACTED] and [REDACTED]"},
+		{"short values like abc aren't redacted", "short values like abc aren't redacted"},
+	}
+
+	for _, test := range tests {
+		if redactedString := Redact(test.s); redactedString != test.expected {
+			t.Errorf("Redact(%q) = %q, expected %q", test.s, redactedString, test.expected)
+		}
+	}
+
+	var buf bytes.Buffer
+
+	if n, err := Writer(&buf).Write([]byte("password hunter2\n")); err != nil || n != 17 {
+		t.Errorf("Write() = %d, %v, expected 17, nil", n, err)
+	}
+
+	if buf.String() != "password [REDACTED]\n" {
+		t.Errorf("writer wrote %q", buf.String())
+	}
+}
+
+func TestRedactOutput(t *testing.T) {
+	defer resetValues()
+
+	Add("s3cr3t-token")
+
+	file, err := ioutil.TempFile(t.TempDir(), "stdout")
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	defer file.Close()
+
+	stdout := os.Stdout
+	os.Stdout = file
+	defer func() { os.Stdout = stdout }()
+
+	restore, err := RedactOutput()
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	fmt.Println("token=s3cr3t-token")
+	fmt.Print("no newline s3cr3t-token")
+
+	restore()
+
+	if os.Stdout != file {
+		t.Error("os.Stdout wasn't restored")
+	}
+
+	data, err := ioutil.ReadFile(file.Name())
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if expected := "token=[REDACTED]\nno newline [REDACTED]"; string(data) != expected {
+		t.Errorf("output is %q, expected %q", data, expected)
+	}
+}
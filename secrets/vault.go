@@ -0,0 +1,71 @@
+package secrets
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+)
+
+// vaultRead reads a field of a secret from the Vault HTTP API using
+// VAULT_ADDR and VAULT_TOKEN. Both KV version 1 and 2 secrets are supported.
+func vaultRead(path string, field string) (string, error) {
+	addr := os.Getenv("VAULT_ADDR")
+
+	if addr == "" {
+		return "", errors.New("VAULT_ADDR is not set")
+	}
+
+	req, err := http.NewRequest("GET", strings.TrimSuffix(addr, "/")+"/v1/"+strings.TrimPrefix(path, "/"), nil)
+
+	if err != nil {
+		return "", err
+	}
+
+	req.Header.Set("X-Vault-Token", os.Getenv("VAULT_TOKEN"))
+
+	if namespace := os.Getenv("VAULT_NAMESPACE"); namespace != "" {
+		req.Header.Set("X-Vault-Namespace", namespace)
+	}
+
+	resp, err := http.DefaultClient.Do(req)
+
+	if err != nil {
+		return "", err
+	}
+
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("cannot read vault secret %s: %s", path, resp.Status)
+	}
+
+	var secret struct {
+		Data map[string]interface{} `json:"data"`
+	}
+
+	if err = json.NewDecoder(resp.Body).Decode(&secret); err != nil {
+		return "", err
+	}
+
+	data := secret.Data
+
+	// KV version 2 nests the fields in data.data
+	if nested, ok := data["data"].(map[string]interface{}); ok {
+		data = nested
+	}
+
+	value, ok := data[field]
+
+	if !ok {
+		return "", errors.New("vault secret " + path + " has no field " + field)
+	}
+
+	if str, ok := value.(string); ok {
+		return str, nil
+	}
+
+	return fmt.Sprintf("%v", value), nil
+}